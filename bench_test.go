@@ -0,0 +1,155 @@
+package sfv_test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+)
+
+func BenchmarkParse(b *testing.B) {
+	inputs := []struct {
+		name  string
+		input []byte
+	}{
+		{"priority", []byte(`u=1, i`)},
+		{"cache-status", []byte(`ExampleCache; hit; ttl=376, OtherCache; fwd=uri-miss; stored`)},
+		{"signature-input", []byte(`sig1=("@method" "@authority" "@path" "content-digest" "content-length" "content-type");created=1618884473;keyid="test-key-rsa-pss"`)},
+		{"byte-sequence", []byte(`sig1=:P0wLUszWQjoi54udOtydf9IWTfNhy+r53jGFj9XZuP4uKwxyJo1RSHi+oEF1FuX6O29d+lbxwwBao1BAgadijW+7O/PyezlTnqAOVPWx9GlyntiCiHzC87qmSQjvu1CFyFuWSjdGa3qLYYlNm7pVaJFalQiKWnUaqfT4LyttaXyoyZW84jS8gyarxAiWI97mPXU+OVM64+HVBHmnEsS+lTeIsEQo36T3NFf2CujWARPQg53r58RmpZ+J9eKR2CD6IJQvacn5A4Ix5BUAVGqlyp8JYm+S/CWJi31PNUjRRCusCVRj05NrxABNFv3r5S9IXf2fYJK+eyW4AiGVMvMcOg==:`)},
+	}
+
+	for _, input := range inputs {
+		b.Run(input.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				if _, err := sfv.Parse(input.input); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParseItem(b *testing.B) {
+	inputs := []struct {
+		name  string
+		input []byte
+	}{
+		{"token", []byte(`gzip`)},
+		{"integer", []byte(`3600`)},
+		{"string", []byte(`"https://example.com/"`)},
+		{"boolean", []byte(`?1`)},
+		{"with-parameters", []byte(`2; foourl="https://foo.example.com/"`)},
+	}
+
+	for _, input := range inputs {
+		b.Run(input.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				if _, err := sfv.ParseItem(input.input); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParseString(b *testing.B) {
+	const input = `ExampleCache; hit; ttl=376, OtherCache; fwd=uri-miss; stored`
+
+	b.Run("ParseString", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := sfv.ParseString(input); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Parse", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := sfv.Parse([]byte(input)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkValidate(b *testing.B) {
+	input := []byte(`sig1=("@method" "@authority" "@path" "content-digest" "content-length" "content-type");created=1618884473;keyid="test-key-rsa-pss"`)
+
+	b.Run("Validate", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if err := sfv.Validate(input, sfv.DictionaryField); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("ParseDictionary", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := sfv.ParseDictionary(input); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	dict, err := sfv.ParseDictionary([]byte(`sig1=("@method" "@authority" "@path" "content-digest" "content-length" "content-type");created=1618884473;keyid="test-key-rsa-pss"`))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("Marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := sfv.Marshal(dict); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("MarshalAppend", func(b *testing.B) {
+		b.ReportAllocs()
+		buf := make([]byte, 0, 256)
+		for range b.N {
+			var err error
+			if buf, err = sfv.MarshalAppend(buf[:0], dict); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkEncoder(b *testing.B) {
+	dict := sfv.NewDictionary()
+	for i := range 50 {
+		item := sfv.Token("value")
+		if err := item.Parameter("n", i); err != nil {
+			b.Fatal(err)
+		}
+		if err := dict.Set(fmt.Sprintf("key%d", i), item); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.Run("Marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := sfv.Marshal(dict); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("Encode", func(b *testing.B) {
+		b.ReportAllocs()
+		enc := sfv.NewEncoder(io.Discard)
+		for range b.N {
+			if err := enc.Encode(dict); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
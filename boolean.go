@@ -1,6 +1,10 @@
 package sfv
 
-import "github.com/lestrrat-go/blackmagic"
+import (
+	"strconv"
+
+	"github.com/lestrrat-go/blackmagic"
+)
 
 // BooleanItem represents a boolean value,
 // with optional parameters.
@@ -59,6 +63,21 @@ func False() BooleanBareItem {
 	return BooleanBareItem(false)
 }
 
+// TrueItem returns a new BooleanItem representing true, without
+// parameters. It is a shorthand for Boolean(true), and is useful to
+// create Dictionary members that are serialized as a bare key, as in
+//
+//	dict.Set("must-revalidate", sfv.TrueItem())
+func TrueItem() *BooleanItem {
+	return True().toItem()
+}
+
+// FalseItem returns a new BooleanItem representing false, without
+// parameters. It is a shorthand for Boolean(false).
+func FalseItem() *BooleanItem {
+	return False().toItem()
+}
+
 // ToItem converts the BooleanBareItem to a full Item.
 func (b BooleanBareItem) ToItem() Item {
 	return b.toItem()
@@ -77,20 +96,39 @@ var trueBareItemBytes = []byte("?1")
 var falseBareItemBytes = []byte("?0")
 
 func (b BooleanBareItem) MarshalSFV() ([]byte, error) {
+	return b.AppendSFV(nil)
+}
+
+// AppendSFV appends the serialization of the BooleanBareItem to dst.
+func (b BooleanBareItem) AppendSFV(dst []byte) ([]byte, error) {
 	if bool(b) {
-		return trueBareItemBytes, nil
+		return append(dst, trueBareItemBytes...), nil
 	}
-	return falseBareItemBytes, nil
+	return append(dst, falseBareItemBytes...), nil
 }
 
 // Type returns the type of the BooleanBareItem, useful when
 // you have a list of BareItems and need to know the type
 // of each item.
-func (b BooleanBareItem) Type() int {
+func (b BooleanBareItem) Type() Type {
 	return BooleanType
 }
 
+// GoString returns Go-like notation for the BooleanBareItem, which is
+// used by the %#v verb.
+func (b BooleanBareItem) GoString() string {
+	return "sfv.BareBoolean(" + strconv.FormatBool(bool(b)) + ")"
+}
+
 // GetValue retrieves the bool value from the BooleanBareItem.
 func (b BooleanBareItem) GetValue(dst any) error {
 	return blackmagic.AssignIfCompatible(dst, bool(b))
 }
+
+// AsBool returns the value of the BooleanBareItem. The second return
+// value is always true.
+func (b BooleanBareItem) AsBool() (bool, bool) { return bool(b), true }
+
+func (b BooleanBareItem) AsString() (string, bool) { return "", false }
+func (b BooleanBareItem) AsInt64() (int64, bool)   { return 0, false }
+func (b BooleanBareItem) AsBytes() ([]byte, bool)  { return nil, false }
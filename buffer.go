@@ -0,0 +1,33 @@
+package sfv
+
+import "sync"
+
+// maxPooledBufferSize is the largest serialization buffer that is
+// returned to the pool. Larger buffers are dropped so that a single huge
+// value does not pin memory forever.
+const maxPooledBufferSize = 64 * 1024
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 0, 256)
+		return &buf
+	},
+}
+
+// getBuffer returns an empty buffer to serialize into. The buffer must be
+// returned with releaseBuffer once its contents are no longer needed.
+func getBuffer() *[]byte {
+	return bufferPool.Get().(*[]byte) //nolint:forcetypeassert
+}
+
+// releaseBuffer returns buf to the pool. buf must hold the slice that was
+// last written to, so that any growth is retained for the next use. The
+// serialization functions return a nil slice on failure, in which case
+// the buffer is simply dropped.
+func releaseBuffer(buf *[]byte) {
+	if *buf == nil || cap(*buf) > maxPooledBufferSize {
+		return
+	}
+	*buf = (*buf)[:0]
+	bufferPool.Put(buf)
+}
@@ -0,0 +1,256 @@
+package sfv
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// DictionaryBuilder builds a Dictionary declaratively, as in
+//
+//	dict, err := sfv.NewDictionaryBuilder().
+//		Set("a", 1).
+//		SetWithParams("b", "x", map[string]any{"q": 0.5}).
+//		Build()
+//
+// Values are converted the same way as by Marshal, so Go values such as
+// ints, strings, and slices can be used in addition to Items, BareItems,
+// and Inner Lists. Errors are deferred: the first error stops the
+// builder, and is returned by Build.
+type DictionaryBuilder struct {
+	dict *Dictionary
+	err  error
+}
+
+// NewDictionaryBuilder creates a new DictionaryBuilder for an empty
+// Dictionary.
+func NewDictionaryBuilder() *DictionaryBuilder {
+	return &DictionaryBuilder{dict: NewDictionary()}
+}
+
+// Set adds or replaces the member called key.
+func (b *DictionaryBuilder) Set(key string, value any) *DictionaryBuilder {
+	return b.SetWithParams(key, value, nil)
+}
+
+// SetWithParams adds or replaces the member called key, with the given
+// parameters. See Dictionary.SetWithParams for details.
+func (b *DictionaryBuilder) SetWithParams(key string, value any, params map[string]any) *DictionaryBuilder {
+	if b.err != nil {
+		return b
+	}
+	b.err = b.dict.SetWithParams(key, value, params)
+	return b
+}
+
+// Build returns the Dictionary, or the first error encountered while
+// building it.
+func (b *DictionaryBuilder) Build() (*Dictionary, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.dict, nil
+}
+
+// ListBuilder builds a List declaratively, as in
+//
+//	list, err := sfv.NewListBuilder().
+//		Add("a").
+//		AddWithParams(1, map[string]any{"q": 0.5}).
+//		AddInnerList(func(b *sfv.InnerListBuilder) {
+//			b.Add("x").Add("y").Param("p", true)
+//		}).
+//		Build()
+//
+// Values are converted in the same way as by DictionaryBuilder, and errors
+// are deferred until Build.
+type ListBuilder struct {
+	list *List
+	err  error
+}
+
+// NewListBuilder creates a new ListBuilder for an empty List.
+func NewListBuilder() *ListBuilder {
+	return &ListBuilder{list: &List{}}
+}
+
+// Add adds value to the end of the list.
+func (b *ListBuilder) Add(value any) *ListBuilder {
+	return b.AddWithParams(value, nil)
+}
+
+// AddWithParams adds value to the end of the list, with the given
+// parameters. See Dictionary.SetWithParams for how they are converted.
+func (b *ListBuilder) AddWithParams(value any, params map[string]any) *ListBuilder {
+	if b.err != nil {
+		return b
+	}
+	member, err := memberWithParameters(value, params)
+	if err != nil {
+		b.err = fmt.Errorf("invalid list member %d: %w", b.list.Len(), err)
+		return b
+	}
+	b.list.values = append(b.list.values, member)
+	return b
+}
+
+// AddInnerList adds an Inner List to the end of the list, built by fn.
+// Errors encountered by the InnerListBuilder passed to fn are reported
+// by Build.
+func (b *ListBuilder) AddInnerList(fn func(*InnerListBuilder)) *ListBuilder {
+	if b.err != nil {
+		return b
+	}
+	ib := NewInnerListBuilder()
+	fn(ib)
+	il, err := ib.Build()
+	if err != nil {
+		b.err = fmt.Errorf("invalid list member %d: %w", b.list.Len(), err)
+		return b
+	}
+	b.list.values = append(b.list.values, il)
+	return b
+}
+
+// Build returns the List, or the first error encountered while building
+// it.
+func (b *ListBuilder) Build() (*List, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.list, nil
+}
+
+// InnerListBuilder builds an InnerList declaratively. It is usually
+// obtained through ListBuilder.AddInnerList, but can be used on its own.
+type InnerListBuilder struct {
+	list *InnerList
+	err  error
+}
+
+// NewInnerListBuilder creates a new InnerListBuilder for an empty
+// InnerList.
+func NewInnerListBuilder() *InnerListBuilder {
+	return &InnerListBuilder{list: NewInnerList()}
+}
+
+// Add adds value to the end of the inner list.
+func (b *InnerListBuilder) Add(value any) *InnerListBuilder {
+	return b.AddWithParams(value, nil)
+}
+
+// AddWithParams adds value to the end of the inner list, with the given
+// parameters. value must convert to an Item, as Inner Lists cannot be
+// nested.
+func (b *InnerListBuilder) AddWithParams(value any, params map[string]any) *InnerListBuilder {
+	if b.err != nil {
+		return b
+	}
+	member, err := memberWithParameters(value, params)
+	if err != nil {
+		b.err = fmt.Errorf("invalid inner list item %d: %w", b.list.Len(), err)
+		return b
+	}
+	item, ok := member.(Item)
+	if !ok {
+		b.err = fmt.Errorf("invalid inner list item %d: inner lists cannot be nested", b.list.Len())
+		return b
+	}
+	b.list.values = append(b.list.values, item)
+	return b
+}
+
+// Param sets a parameter of the inner list itself.
+func (b *InnerListBuilder) Param(key string, value any) *InnerListBuilder {
+	if b.err != nil {
+		return b
+	}
+	if !isValidKey(key) {
+		b.err = fmt.Errorf("invalid parameter key %q", key)
+		return b
+	}
+	v, err := bareItemFromValue(value)
+	if err != nil {
+		b.err = fmt.Errorf("invalid value for parameter %q: %w", key, err)
+		return b
+	}
+	b.err = b.list.params.Set(key, v)
+	return b
+}
+
+// Build returns the InnerList, or the first error encountered while
+// building it.
+func (b *InnerListBuilder) Build() (*InnerList, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.list, nil
+}
+
+// memberFrom converts a Go value to an Item or an *InnerList, using the
+// same conversions as Marshal
+func memberFrom(value any) (any, error) {
+	if value == nil {
+		return nil, fmt.Errorf("value cannot be nil")
+	}
+	v, err := defaultEncodeConfig.valueToSFV(value)
+	if err != nil {
+		return nil, err
+	}
+	return toMember(v)
+}
+
+// memberWithParameters converts a Go value to an Item or an *InnerList,
+// and adds params to it. The value passed in is not modified.
+func memberWithParameters(value any, params map[string]any) (any, error) {
+	member, err := memberFrom(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(params) == 0 {
+		return member, nil
+	}
+
+	var merged *Parameters
+	switch member := member.(type) {
+	case Item:
+		merged = member.Parameters().Clone()
+	case *InnerList:
+		merged = member.params.Clone()
+	}
+	if merged == nil {
+		merged = NewParameters()
+	}
+	for _, key := range slices.Sorted(maps.Keys(params)) {
+		if !isValidKey(key) {
+			return nil, fmt.Errorf("invalid parameter key %q", key)
+		}
+		v, err := bareItemFromValue(params[key])
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for parameter %q: %w", key, err)
+		}
+		_ = merged.Set(key, v)
+	}
+
+	switch member := member.(type) {
+	case Item:
+		return member.With(merged), nil
+	case *InnerList:
+		return &InnerList{values: member.values, params: merged}, nil
+	default:
+		return member, nil
+	}
+}
+
+// bareItemFromValue converts a Go value to a BareItem, using the same
+// conversions as Marshal
+func bareItemFromValue(value any) (BareItem, error) {
+	if value == nil {
+		return nil, fmt.Errorf("value cannot be nil")
+	}
+	v, err := defaultEncodeConfig.valueToSFV(value)
+	if err != nil {
+		return nil, err
+	}
+	return toBareItem(v)
+}
@@ -0,0 +1,98 @@
+package sfv_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDictionaryBuilder(t *testing.T) {
+	dict, err := sfv.NewDictionaryBuilder().
+		Set("a", 1).
+		SetWithParams("b", "x", map[string]any{"q": 0.5, "final": true}).
+		Set("c", []string{"y", "z"}).
+		SetWithParams("d", sfv.Token("tok").With(mustParameters(t, "p", sfv.BareInteger(1))), map[string]any{"n": -2}).
+		Set("a", false).
+		Build()
+	require.NoError(t, err)
+
+	marshaled, err := dict.MarshalSFV()
+	require.NoError(t, err)
+	require.Equal(t, `a=?0, b="x";final;q=0.5, c=("y" "z"), d=tok;p=1;n=-2`, string(marshaled))
+
+	t.Run("errors", func(t *testing.T) {
+		testcases := []struct {
+			name    string
+			builder *sfv.DictionaryBuilder
+		}{
+			{"invalid key", sfv.NewDictionaryBuilder().Set("Bad", 1)},
+			{"invalid parameter key", sfv.NewDictionaryBuilder().SetWithParams("a", 1, map[string]any{"Bad": 1})},
+			{"unsupported value", sfv.NewDictionaryBuilder().Set("a", make(chan int))},
+			{"nil value", sfv.NewDictionaryBuilder().Set("a", nil)},
+			{"parameter with parameters", sfv.NewDictionaryBuilder().SetWithParams("a", 1, map[string]any{"p": sfv.Integer(1).With(mustParameters(t, "x", sfv.True()))})},
+			{"deferred", sfv.NewDictionaryBuilder().Set("Bad", 1).Set("good", 2)},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.name, func(t *testing.T) {
+				dict, err := tc.builder.Build()
+				require.Error(t, err)
+				require.Nil(t, dict)
+			})
+		}
+	})
+}
+
+func mustParameters(t *testing.T, key string, value sfv.BareItem) *sfv.Parameters {
+	t.Helper()
+	params := sfv.NewParameters()
+	require.NoError(t, params.Set(key, value))
+	return params
+}
+
+func TestListBuilder(t *testing.T) {
+	list, err := sfv.NewListBuilder().
+		Add("a").
+		AddWithParams(sfv.Token("gzip"), map[string]any{"q": 0.5}).
+		AddInnerList(func(b *sfv.InnerListBuilder) {
+			b.Add("x").AddWithParams(1, map[string]any{"n": true}).Param("p", sfv.BareToken("tok"))
+		}).
+		AddInnerList(func(*sfv.InnerListBuilder) {}).
+		Add([]int{1, 2}).
+		Build()
+	require.NoError(t, err)
+
+	marshaled, err := list.MarshalSFV()
+	require.NoError(t, err)
+	require.Equal(t, `"a", gzip;q=0.5, ("x" 1;n);p=tok, (), (1 2)`, string(marshaled))
+
+	il, err := sfv.NewInnerListBuilder().Add(sfv.Integer(1)).Param("a", 1).Build()
+	require.NoError(t, err)
+	marshaled, err = il.MarshalSFV()
+	require.NoError(t, err)
+	require.Equal(t, `(1);a=1`, string(marshaled))
+
+	t.Run("errors", func(t *testing.T) {
+		testcases := []struct {
+			name    string
+			builder *sfv.ListBuilder
+		}{
+			{"unsupported value", sfv.NewListBuilder().Add(make(chan int))},
+			{"invalid parameter", sfv.NewListBuilder().AddWithParams(1, map[string]any{"Bad": 1})},
+			{"nested inner list", sfv.NewListBuilder().AddInnerList(func(b *sfv.InnerListBuilder) {
+				b.Add([]string{"x"})
+			})},
+			{"invalid inner list parameter", sfv.NewListBuilder().AddInnerList(func(b *sfv.InnerListBuilder) {
+				b.Param("p", []int{1})
+			})},
+			{"deferred", sfv.NewListBuilder().Add(nil).Add("ok")},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.name, func(t *testing.T) {
+				list, err := tc.builder.Build()
+				require.Error(t, err)
+				require.Nil(t, list)
+			})
+		}
+	})
+}
@@ -1,8 +1,11 @@
 package sfv
 
 import (
-	"bytes"
 	"encoding/base64"
+	"strconv"
+	"sync"
+
+	"github.com/lestrrat-go/blackmagic"
 )
 
 // ByteSequenceItem represents a base64-encoded byte sequence value,
@@ -17,8 +20,29 @@ var _ Item = (*ByteSequenceItem)(nil)
 // Bare items cannot have parameters. Some constructs
 // may require a bare item instead of a full byte sequence item
 // (e.g. dictionary values).
+//
+// Byte sequences produced by the parser are decoded lazily: the
+// base64 payload is only decoded the first time the value is requested.
 type ByteSequenceBareItem struct {
 	uvalue[[]byte]
+
+	lazy *lazyByteSequence
+}
+
+// lazyByteSequence holds the validated base64 payload of a parsed
+// byte sequence until it is decoded.
+type lazyByteSequence struct {
+	once    sync.Once
+	encoded []byte
+	decoded []byte
+}
+
+func (l *lazyByteSequence) value() []byte {
+	l.once.Do(func() {
+		l.decoded = decodeBase64(l.encoded)
+		l.encoded = nil
+	})
+	return l.decoded
 }
 
 var _ BareItem = (*ByteSequenceBareItem)(nil)
@@ -52,6 +76,36 @@ func BareByteSequence(b []byte) *ByteSequenceBareItem {
 	return &v
 }
 
+// SetValue sets the byte slice held by the ByteSequenceBareItem.
+func (b *ByteSequenceBareItem) SetValue(value []byte) error {
+	b.value = value
+	b.lazy = nil
+	return nil
+}
+
+// Value returns the decoded byte slice held by the ByteSequenceBareItem.
+func (b *ByteSequenceBareItem) Value() []byte {
+	return b.bytes()
+}
+
+// GetValue assigns the decoded byte slice to dst.
+func (b ByteSequenceBareItem) GetValue(dst any) error {
+	return blackmagic.AssignIfCompatible(dst, b.bytes())
+}
+
+// AsBytes returns the decoded byte slice held by the ByteSequenceBareItem.
+// The second return value is always true.
+func (b ByteSequenceBareItem) AsBytes() ([]byte, bool) {
+	return b.bytes(), true
+}
+
+func (b ByteSequenceBareItem) bytes() []byte {
+	if b.lazy != nil {
+		return b.lazy.value()
+	}
+	return b.value
+}
+
 // ToItem converts the ByteSequenceBareItem to a full Item.
 func (b *ByteSequenceBareItem) ToItem() Item {
 	return b.toItem()
@@ -59,16 +113,26 @@ func (b *ByteSequenceBareItem) ToItem() Item {
 
 // MarshalSFV implements the Marshaler interface for ByteSequenceBareItem.
 func (b ByteSequenceBareItem) MarshalSFV() ([]byte, error) {
-	var buf bytes.Buffer
-	buf.WriteByte(':')
-	buf.WriteString(base64.StdEncoding.EncodeToString(b.value))
-	buf.WriteByte(':')
-	return buf.Bytes(), nil
+	return b.AppendSFV(nil)
+}
+
+// AppendSFV appends the serialization of the ByteSequenceBareItem to dst.
+func (b ByteSequenceBareItem) AppendSFV(dst []byte) ([]byte, error) {
+	dst = append(dst, ':')
+	dst = base64.StdEncoding.AppendEncode(dst, b.bytes())
+	dst = append(dst, ':')
+	return dst, nil
 }
 
 // Type returns the type of the ByteSequenceBareItem, useful when
 // you have a list of BareItems and need to know the type
 // of each item.
-func (b ByteSequenceBareItem) Type() int {
+func (b ByteSequenceBareItem) Type() Type {
 	return ByteSequenceType
 }
+
+// GoString returns Go-like notation for the ByteSequenceBareItem, which
+// is used by the %#v verb.
+func (b ByteSequenceBareItem) GoString() string {
+	return "sfv.BareByteSequence([]byte(" + strconv.Quote(string(b.bytes())) + "))"
+}
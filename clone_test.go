@@ -0,0 +1,76 @@
+package sfv_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	t.Run("Item", func(t *testing.T) {
+		item, err := sfv.ParseItemString(`:AQID:;a=1`)
+		require.NoError(t, err)
+
+		c := item.Clone()
+		require.True(t, sfv.Equal(item, c))
+
+		var b []byte
+		require.NoError(t, c.GetValue(&b))
+		b[0] = 0xff
+		require.NoError(t, c.Parameters().Set("a", sfv.BareInteger(2)))
+
+		marshaled, err := item.MarshalSFV()
+		require.NoError(t, err)
+		require.Equal(t, `:AQID:;a=1`, string(marshaled), "the original should not be modified")
+	})
+	t.Run("List", func(t *testing.T) {
+		v, err := sfv.ParseString(`a;x, (b c);y=1`)
+		require.NoError(t, err)
+		list := v.(*sfv.List)
+
+		c := list.Clone()
+		require.True(t, sfv.Equal(list, c))
+
+		member, _ := c.Get(1)
+		il := member.(*sfv.InnerList)
+		require.NoError(t, il.Add(sfv.Token("d")))
+		require.NoError(t, il.Parameters().Set("y", sfv.BareInteger(2)))
+		require.NoError(t, c.Add(sfv.Token("e")))
+
+		marshaled, err := list.MarshalSFV()
+		require.NoError(t, err)
+		require.Equal(t, `a;x, (b c);y=1`, string(marshaled), "the original should not be modified")
+	})
+	t.Run("Dictionary", func(t *testing.T) {
+		dict, err := sfv.ParseDictionaryString(`a=1;p, b, c=(x)`, sfv.WithRawText(true))
+		require.NoError(t, err)
+
+		c := dict.Clone()
+		require.True(t, sfv.Equal(dict, c))
+		require.Equal(t, dict.RawMember("a"), c.RawMember("a"))
+
+		require.NoError(t, c.Set("a", sfv.Integer(2)))
+		require.NoError(t, c.Set("d", sfv.Integer(3)))
+
+		marshaled, err := dict.MarshalSFV()
+		require.NoError(t, err)
+		require.Equal(t, `a=1;p, b, c=(x)`, string(marshaled), "the original should not be modified")
+		require.Equal(t, []string{"a", "b", "c"}, dict.Keys())
+	})
+	t.Run("Parameters", func(t *testing.T) {
+		params := sfv.NewParameters()
+		require.NoError(t, params.Set("a", sfv.BareString("x")))
+
+		c := params.Clone()
+		require.NoError(t, c.Set("b", sfv.True()))
+		require.Equal(t, []string{"a"}, params.Keys())
+		require.Equal(t, []string{"a", "b"}, c.Keys())
+	})
+	t.Run("nil", func(t *testing.T) {
+		require.Nil(t, (*sfv.List)(nil).Clone())
+		require.Nil(t, (*sfv.Dictionary)(nil).Clone())
+		require.Nil(t, (*sfv.InnerList)(nil).Clone())
+		require.Nil(t, (*sfv.Parameters)(nil).Clone())
+	})
+}
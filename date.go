@@ -1,8 +1,9 @@
 package sfv
 
 import (
-	"bytes"
+	"fmt"
 	"strconv"
+	"time"
 )
 
 // DateItem represents a Unix timestamp date value,
@@ -52,6 +53,16 @@ func BareDate(timestamp int64) *DateBareItem {
 	return &v
 }
 
+// DateStrict creates a new DateBareItem with the given Unix timestamp,
+// like BareDate, but returns an error if the timestamp is outside of the
+// range allowed by RFC 9651, which is the same as for Integers.
+func DateStrict(timestamp int64) (*DateBareItem, error) {
+	if timestamp > maxSFVInteger || timestamp < -maxSFVInteger {
+		return nil, fmt.Errorf("sfv: date %d out of range (max %d decimal digits)", timestamp, maxIntegerDigits)
+	}
+	return BareDate(timestamp), nil
+}
+
 // ToItem converts the DateBareItem to a full Item.
 func (d *DateBareItem) ToItem() Item {
 	return d.toItem()
@@ -59,15 +70,35 @@ func (d *DateBareItem) ToItem() Item {
 
 // MarshalSFV implements the Marshaler interface for DateBareItem.
 func (d DateBareItem) MarshalSFV() ([]byte, error) {
-	var buf bytes.Buffer
-	buf.WriteByte('@')
-	buf.WriteString(strconv.FormatInt(d.value, 10))
-	return buf.Bytes(), nil
+	return d.AppendSFV(nil)
+}
+
+// AppendSFV appends the serialization of the DateBareItem to dst.
+func (d DateBareItem) AppendSFV(dst []byte) ([]byte, error) {
+	dst = append(dst, '@')
+	return strconv.AppendInt(dst, d.value, 10), nil
+}
+
+// GetValue assigns the timestamp of the DateBareItem to dst. If dst is a
+// *time.Time, the timestamp is converted to a time.Time in UTC. Otherwise
+// dst may point to any integer type, as for IntegerBareItem.
+func (d DateBareItem) GetValue(dst any) error {
+	if t, ok := dst.(*time.Time); ok {
+		*t = time.Unix(d.value, 0).UTC()
+		return nil
+	}
+	return assignInteger(dst, d.value)
 }
 
 // Type returns the type of the DateBareItem, useful when
 // you have a list of BareItems and need to know the type
 // of each item.
-func (d DateBareItem) Type() int {
+func (d DateBareItem) Type() Type {
 	return DateType
 }
+
+// GoString returns Go-like notation for the DateBareItem, which is used
+// by the %#v verb.
+func (d DateBareItem) GoString() string {
+	return "sfv.BareDate(" + strconv.FormatInt(d.value, 10) + ")"
+}
@@ -0,0 +1,88 @@
+package sfv
+
+import (
+	"errors"
+	"strings"
+)
+
+// diagnosticContext is the number of bytes of input shown on each side of
+// the offending byte by FormatParseError
+const diagnosticContext = 32
+
+// FormatParseError renders err, as returned by one of the parsing
+// functions for input, as a caret-annotated excerpt of the input in the
+// style of compiler diagnostics:
+//
+//	sfv: parse error at offset 8: ...
+//	  a=1, b=?x
+//	          ^
+//
+// Long inputs are trimmed to the bytes around the offending one, and
+// bytes that are not printable ASCII characters are shown as '.' so that
+// the caret stays aligned. When err combines several errors, as with
+// WithErrorAggregation, each of them is rendered in turn. If err does not
+// carry a location, only its message is returned.
+//
+// When WithObsFold is used, the offsets refer to the unfolded input, so
+// the excerpt is only accurate if input contains no line breaks.
+func FormatParseError(input []byte, err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var perrs []*ParseError
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			var perr *ParseError
+			if errors.As(e, &perr) {
+				perrs = append(perrs, perr)
+			}
+		}
+	} else {
+		var perr *ParseError
+		if errors.As(err, &perr) {
+			perrs = append(perrs, perr)
+		}
+	}
+	if len(perrs) == 0 {
+		return err.Error()
+	}
+
+	var sb strings.Builder
+	for i, perr := range perrs {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(perr.Error())
+		sb.WriteByte('\n')
+		writeExcerpt(&sb, input, perr.Offset)
+	}
+	return sb.String()
+}
+
+// writeExcerpt writes the part of input around offset, followed by a line
+// with a caret below the byte at offset
+func writeExcerpt(sb *strings.Builder, input []byte, offset int) {
+	offset = max(0, min(offset, len(input)))
+	start := max(0, offset-diagnosticContext)
+	end := min(len(input), offset+diagnosticContext)
+
+	sb.WriteString("  ")
+	caret := 2 + offset - start
+	if start > 0 {
+		sb.WriteString("...")
+		caret += 3
+	}
+	for _, c := range input[start:end] {
+		if c < 0x20 || c > 0x7e {
+			c = '.'
+		}
+		sb.WriteByte(c)
+	}
+	if end < len(input) {
+		sb.WriteString("...")
+	}
+	sb.WriteByte('\n')
+	sb.WriteString(strings.Repeat(" ", caret))
+	sb.WriteString("^\n")
+}
@@ -0,0 +1,60 @@
+package sfv_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatParseError(t *testing.T) {
+	t.Run("single error", func(t *testing.T) {
+		input := `a=1, b=?x`
+		_, err := sfv.ParseDictionaryString(input)
+		require.Error(t, err)
+
+		var perr *sfv.ParseError
+		require.ErrorAs(t, err, &perr)
+		require.Equal(t, 8, perr.Offset, "offset should point at the offending byte")
+
+		require.Equal(t, err.Error()+"\n"+
+			"  a=1, b=?x\n"+
+			"          ^\n", sfv.FormatParseError([]byte(input), err))
+	})
+	t.Run("long input", func(t *testing.T) {
+		input := strings.Repeat("a, ", 20) + "?x, " + strings.Repeat("b, ", 20) + "c"
+		_, err := sfv.ParseString(input)
+		require.Error(t, err)
+
+		lines := strings.Split(sfv.FormatParseError([]byte(input), err), "\n")
+		require.Len(t, lines, 4)
+		require.True(t, strings.HasPrefix(lines[1], "  ..."), lines[1])
+		require.True(t, strings.HasSuffix(lines[1], "..."), lines[1])
+		caret := strings.Index(lines[2], "^")
+		require.Equal(t, "x", lines[1][caret:caret+1])
+	})
+	t.Run("control characters", func(t *testing.T) {
+		input := "a\tb" // a tab may precede the comma, but not another item
+		_, err := sfv.ParseString(input)
+		require.Error(t, err)
+		require.Equal(t, err.Error()+"\n"+
+			"  a.b\n"+
+			"    ^\n", sfv.FormatParseError([]byte(input), err))
+	})
+	t.Run("aggregated errors", func(t *testing.T) {
+		input := `a, ?9, b, ?7`
+		_, err := sfv.ParseString(input, sfv.WithErrorAggregation(true))
+		require.Error(t, err)
+
+		out := sfv.FormatParseError([]byte(input), err)
+		require.Equal(t, 2, strings.Count(out, "^"), out)
+		require.Contains(t, out, "\n      ^\n")
+		require.Contains(t, out, "\n             ^\n")
+	})
+	t.Run("no location", func(t *testing.T) {
+		require.Equal(t, "boom", sfv.FormatParseError(nil, errors.New("boom")))
+		require.Empty(t, sfv.FormatParseError(nil, nil))
+	})
+}
@@ -1,8 +1,13 @@
 package sfv
 
 import (
-	"bytes"
 	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/lestrrat-go/blackmagic"
 )
@@ -11,8 +16,11 @@ import (
 // Values can be Items, BareItems, or InnerLists. Dictionary maintains insertion
 // order and serializes as semicolon-separated key=value pairs according to RFC 9651.
 type Dictionary struct {
-	keys   []string
-	values map[string]any
+	members OrderedMap[string, any]
+	raw     []byte
+	raws    map[string][]byte
+	spans   map[string]Span
+	frozen  bool
 }
 
 // NewDictionary creates a new empty Dictionary. A Dictionary represents
@@ -20,8 +28,7 @@ type Dictionary struct {
 // to values (Items, BareItems, or InnerLists).
 func NewDictionary() *Dictionary {
 	return &Dictionary{
-		keys:   make([]string, 0),
-		values: make(map[string]any),
+		members: *NewOrderedMap[string, any](),
 	}
 }
 
@@ -29,6 +36,9 @@ func NewDictionary() *Dictionary {
 // The value must be an Item, BareItem, or *InnerList.
 // Returns an error if the value type is not supported.
 func (d *Dictionary) Set(key string, value any) error {
+	if d.frozen {
+		return ErrFrozen
+	}
 	switch value.(type) {
 	case Item, BareItem, *InnerList:
 		// ok. no op
@@ -36,17 +46,181 @@ func (d *Dictionary) Set(key string, value any) error {
 		return fmt.Errorf("value must be of type Item, BareItem, or *InnerList, got %T", value)
 	}
 
-	if _, exists := d.values[key]; !exists {
-		d.keys = append(d.keys, key)
+	d.members.Set(key, value)
+	// the recorded input text no longer describes the new value
+	d.raw = nil
+	delete(d.raws, key)
+	delete(d.spans, key)
+	return nil
+}
+
+// SetWithParams adds or updates the member called key, with the given
+// parameters, as in "c;foo=bar". Unlike Set, value can be any Go value
+// that Marshal can convert to an Item or an Inner List, such as a string
+// or an int, and so can the values of params. Since Go maps are not
+// ordered, the parameters are added in lexicographic order of their keys,
+// after any parameters value already has. value itself is not modified.
+// It is an error if key or any of the parameter keys is not a valid key.
+func (d *Dictionary) SetWithParams(key string, value any, params map[string]any) error {
+	if d.frozen {
+		return ErrFrozen
+	}
+	if !isValidKey(key) {
+		return fmt.Errorf("invalid dictionary key %q", key)
+	}
+	member, err := memberWithParameters(value, params)
+	if err != nil {
+		return fmt.Errorf("failed to set dictionary member %q: %w", key, err)
+	}
+	return d.Set(key, member)
+}
+
+// Pair is a key and a value, as passed to Dictionary.SetPairs.
+type Pair struct {
+	Key   string
+	Value any
+}
+
+// SetAll adds or updates a member for each entry of members. The values
+// are converted as by SetWithParams. Since Go maps are not ordered, new
+// members are added in lexicographic order of their keys; use SetPairs to
+// control the order. Either all members are set, or, if any key or value
+// is invalid, none is and an error is returned.
+func (d *Dictionary) SetAll(members map[string]any) error {
+	pairs := make([]Pair, 0, len(members))
+	for _, key := range slices.Sorted(maps.Keys(members)) {
+		pairs = append(pairs, Pair{Key: key, Value: members[key]})
+	}
+	return d.SetPairs(pairs...)
+}
+
+// SetPairs is the same as SetAll, but adds new members in the order of
+// pairs. When the same key appears more than once, the last value wins,
+// but the member keeps the position of the first one.
+func (d *Dictionary) SetPairs(pairs ...Pair) error {
+	if d.frozen {
+		return ErrFrozen
+	}
+	converted := make([]any, len(pairs))
+	for i, pair := range pairs {
+		if !isValidKey(pair.Key) {
+			return fmt.Errorf("invalid dictionary key %q", pair.Key)
+		}
+		member, err := memberFrom(pair.Value)
+		if err != nil {
+			return fmt.Errorf("failed to set dictionary member %q: %w", pair.Key, err)
+		}
+		converted[i] = member
+	}
+	for i, pair := range pairs {
+		_ = d.Set(pair.Key, converted[i])
+	}
+	return nil
+}
+
+// Merge adds the members of other to d, in order, as if the two
+// dictionaries had been sent as separate lines of the same field. As
+// specified by RFC 9651 Section 4.2.2, members of other whose key is
+// already present in d replace the existing member, but keep its position.
+// Text recorded by WithRawText is carried over, while spans recorded by
+// WithSpans are not, as they refer to a different input.
+//
+// The members are not copied, so d and other share them after the merge.
+// Use Clone first if that is not desired. Merge returns ErrFrozen if d is
+// frozen.
+func (d *Dictionary) Merge(other *Dictionary) error {
+	if d.frozen {
+		return ErrFrozen
+	}
+	for key, value := range other.All() {
+		_ = d.Set(key, value)
+		if raw := other.RawMember(key); raw != nil {
+			d.setRawMember(key, raw)
+		}
 	}
-	d.values[key] = value
 	return nil
 }
 
+func (d *Dictionary) setRawMember(key string, raw []byte) {
+	if d.raws == nil {
+		d.raws = make(map[string][]byte)
+	}
+	d.raws[key] = raw
+}
+
+func (d *Dictionary) setMemberSpan(key string, span Span) {
+	if d.spans == nil {
+		d.spans = make(map[string]Span)
+	}
+	d.spans[key] = span
+}
+
+// MemberSpan returns the location in the input of the member associated
+// with the given key, including the key itself and any parameters. The
+// second return value is false unless the dictionary was parsed with the
+// WithSpans option enabled, or if the member has been replaced since.
+func (d *Dictionary) MemberSpan(key string) (Span, bool) {
+	if d == nil {
+		return Span{}, false
+	}
+	span, ok := d.spans[key]
+	return span, ok
+}
+
+// RawMember returns the exact input text of the member associated with
+// the given key, including the key itself and any parameters, as in
+// `a=1;x`. It returns nil unless the dictionary was parsed with the
+// WithRawText option enabled, or if the member has been replaced
+// since. The returned slice must not be modified.
+func (d *Dictionary) RawMember(key string) []byte {
+	if d == nil {
+		return nil
+	}
+	return d.raws[key]
+}
+
+// Raw returns the exact input text the whole dictionary was parsed from,
+// without leading and trailing whitespace. It returns nil unless the
+// dictionary was parsed with the WithRawText option enabled, or if a
+// member has been set since. Members modified in place are not detected.
+// The returned slice must not be modified.
+func (d *Dictionary) Raw() []byte {
+	if d == nil {
+		return nil
+	}
+	return d.raw
+}
+
+// Has reports whether the dictionary has a member with the given key.
+func (d *Dictionary) Has(key string) bool {
+	return d != nil && d.members.Has(key)
+}
+
+// Get returns the member with the given key, which is an Item, a
+// BareItem, or an *InnerList, as it was passed to Set. Members parsed from
+// a bare key without parameters, as in "a, b", are BareItems holding
+// true. The second return value is false if there is no such member.
+func (d *Dictionary) Get(key string) (any, bool) {
+	if d == nil {
+		return nil, false
+	}
+	return d.members.Get(key)
+}
+
+// At returns the key and the member at position i, in the order the
+// members were added. The third return value is false if i is out of
+// range.
+func (d *Dictionary) At(i int) (string, any, bool) {
+	if d == nil {
+		return "", nil, false
+	}
+	return d.members.At(i)
+}
+
 // GetValue retrieves the value associated with the given key and assigns
 // it to dst. Returns an error if the key is not found or if assignment fails.
 func (d *Dictionary) GetValue(key string, dst any) error {
-	value, exists := d.values[key]
+	value, exists := d.Get(key)
 	if !exists {
 		return fmt.Errorf("key %q not found in dictionary", key)
 	}
@@ -55,92 +229,360 @@ func (d *Dictionary) GetValue(key string, dst any) error {
 
 // MarshalSFV implements the Marshaler interface for Dictionary
 func (d *Dictionary) MarshalSFV() ([]byte, error) {
-	if d == nil || len(d.keys) == 0 {
+	if d == nil || d.members.Len() == 0 {
 		return []byte{}, nil
 	}
+	return d.appendSFV(nil, &defaultEncodeConfig)
+}
 
-	var buf bytes.Buffer
-	first := true
+// AppendSFV appends the serialization of the dictionary to dst.
+func (d *Dictionary) AppendSFV(dst []byte) ([]byte, error) {
+	return d.appendSFV(dst, &defaultEncodeConfig)
+}
 
-	for _, key := range d.keys {
-		var value any
-		if err := d.GetValue(key, &value); err != nil {
+// MarshalText implements encoding.TextMarshaler, and returns the same
+// serialization as MarshalSFV.
+func (d *Dictionary) MarshalText() ([]byte, error) {
+	return d.MarshalSFV()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It parses text as a
+// Dictionary, and replaces the contents of d with it.
+func (d *Dictionary) UnmarshalText(text []byte) error {
+	if d.frozen {
+		return ErrFrozen
+	}
+	parsed, err := ParseDictionary(text)
+	if err != nil {
+		return err
+	}
+	*d = *parsed
+	return nil
+}
+
+// WriteSFV writes the serialization of the dictionary to w, and returns
+// the number of bytes written.
+func (d *Dictionary) WriteSFV(w io.Writer) (int, error) {
+	return writeSFV(w, d)
+}
+
+func (d *Dictionary) appendSFV(dst []byte, cfg *encodeConfig) ([]byte, error) {
+	if d == nil {
+		return dst, nil
+	}
+
+	first := true
+	for _, key := range d.sortedKeys(cfg) {
+		value, ok := d.members.Get(key)
+		if !ok {
 			continue
 		}
 
 		// Add separator between dictionary entries
 		if !first {
-			buf.WriteString(", ")
+			dst = append(dst, ',', ' ')
 		}
 		first = false
 
-		// Write the key
-		buf.WriteString(key)
-
-		// Check if this is a Boolean true value (bare key)
-		isBareKey := false
-		switch v := value.(type) {
-		case Item:
-			if v.Type() == BooleanType {
-				var b bool
-				if err := v.GetValue(&b); err == nil && b {
-					isBareKey = true
-				}
-			}
-		case BareItem:
-			if v.Type() == BooleanType {
-				var b bool
-				if err := v.GetValue(&b); err == nil && b {
-					isBareKey = true
-				}
-			}
+		var err error
+		dst, err = appendDictionaryMember(dst, key, value, cfg)
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		// For bare keys (Boolean true), we still need to marshal to get parameters
-		if isBareKey {
-			// For Boolean true, don't include the =?1 part, just parameters
-			if item, ok := value.(Item); ok && item.Parameters() != nil && item.Parameters().Len() > 0 {
-				paramBytes, err := item.Parameters().MarshalSFV()
-				if err != nil {
-					return nil, fmt.Errorf("error marshaling parameters for dictionary key %q: %w", key, err)
-				}
-				buf.Write(paramBytes)
-			}
-			// BareItems don't have parameters, so no need to handle that case
-		} else {
-			// Regular values - include equals and full marshaling
-			buf.WriteByte('=')
-			var valueBytes []byte
-			var err error
+	return dst, nil
+}
 
-			switch v := value.(type) {
-			case Item:
-				valueBytes, err = v.MarshalSFV()
-			case BareItem:
-				// Convert BareItem to Item for marshaling
-				item := v.ToItem()
-				valueBytes, err = item.MarshalSFV()
-			case *InnerList:
-				valueBytes, err = v.MarshalSFV()
-			default:
-				return nil, fmt.Errorf("unsupported dictionary value type: %T", v)
+// sortedKeys returns the keys in the order they are serialized
+func (d *Dictionary) sortedKeys(cfg *encodeConfig) []string {
+	if cfg.sortDictionaryKeys {
+		return slices.Sorted(slices.Values(d.members.keys))
+	}
+	return d.members.keys
+}
+
+// appendDictionaryMember appends the serialization of a single member,
+// including its key, to dst
+func appendDictionaryMember(dst []byte, key string, value any, cfg *encodeConfig) ([]byte, error) {
+	// Write the key
+	dst = append(dst, key...)
+
+	// Check if this is a Boolean true value (bare key)
+	isBareKey := false
+	switch v := value.(type) {
+	case Item:
+		if v.IsBoolean() {
+			var b bool
+			if err := v.GetValue(&b); err == nil && b {
+				isBareKey = true
+			}
+		}
+	case BareItem:
+		if v.IsBoolean() {
+			var b bool
+			if err := v.GetValue(&b); err == nil && b {
+				isBareKey = true
 			}
+		}
+	}
 
+	// For bare keys (Boolean true), we still need to marshal to get parameters
+	if isBareKey {
+		// For Boolean true, don't include the =?1 part, just parameters
+		if item, ok := value.(Item); ok && item.Parameters() != nil && item.Parameters().Len() > 0 {
+			var err error
+			dst, err = item.Parameters().appendSFV(dst, cfg)
 			if err != nil {
-				return nil, fmt.Errorf("error marshaling dictionary value for key %q: %w", key, err)
+				return nil, fmt.Errorf("error marshaling parameters for dictionary key %q: %w", key, err)
 			}
+		}
+		// BareItems don't have parameters, so no need to handle that case
+		return dst, nil
+	}
+
+	// Regular values - include equals and full marshaling
+	dst = append(dst, '=')
+	var err error
+
+	switch v := value.(type) {
+	case Item:
+		dst, err = appendValue(dst, v, cfg)
+	case BareItem:
+		// Convert BareItem to Item for marshaling
+		dst, err = appendValue(dst, v.ToItem(), cfg)
+	case *InnerList:
+		dst, err = v.appendSFV(dst, cfg)
+	default:
+		return nil, fmt.Errorf("unsupported dictionary value type: %T", v)
+	}
 
-			buf.Write(valueBytes)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling dictionary value for key %q: %w", key, err)
+	}
+	return dst, nil
+}
+
+// GoString returns Go-like notation for the dictionary, such as
+// &sfv.Dictionary{"a": sfv.Integer(1)}, which is used by the %#v verb.
+// The members are listed in order. It is meant to make test failures
+// readable, and is not guaranteed to be valid Go code.
+func (d *Dictionary) GoString() string {
+	if d == nil {
+		return "(*sfv.Dictionary)(nil)"
+	}
+	var sb strings.Builder
+	sb.WriteString("&sfv.Dictionary{")
+	for i, key := range d.members.keys {
+		if i > 0 {
+			sb.WriteString(", ")
 		}
+		sb.WriteString(strconv.Quote(key))
+		sb.WriteString(": ")
+		fmt.Fprintf(&sb, "%#v", d.members.values[key])
 	}
+	sb.WriteByte('}')
+	return sb.String()
+}
 
-	return buf.Bytes(), nil
+// Clone returns a deep copy of the dictionary, including its members and
+// their parameters. Modifying the copy does not affect the original, and
+// vice versa. Cloning nil returns nil.
+func (d *Dictionary) Clone() *Dictionary {
+	if d == nil {
+		return nil
+	}
+	c := &Dictionary{
+		members: *NewOrderedMap[string, any](),
+		raw:     d.raw,
+		raws:    maps.Clone(d.raws),
+		spans:   maps.Clone(d.spans),
+	}
+	for key, value := range d.members.All() {
+		c.members.Set(key, cloneMember(value))
+	}
+	return c
 }
 
-// Keys returns the ordered list of keys in the dictionary
+// Freeze makes the dictionary and all of its members read-only. See
+// List.Freeze for details.
+func (d *Dictionary) Freeze() {
+	if d == nil {
+		return
+	}
+	d.frozen = true
+	for member := range d.members.Values() {
+		freezeMember(member)
+	}
+}
+
+// Frozen reports whether Freeze was called on the dictionary.
+func (d *Dictionary) Frozen() bool {
+	return d != nil && d.frozen
+}
+
+// WithMember returns a copy of the dictionary, with the member called key
+// added or replaced by value, which must be of the same types as for Set.
+// The dictionary itself is not modified. The other members are shared
+// between the two dictionaries rather than copied, so they must not be
+// modified in place either; use their own With methods to derive new
+// values from them instead.
+func (d *Dictionary) WithMember(key string, value any) (*Dictionary, error) {
+	c := NewDictionary()
+	if d != nil {
+		c.members.Merge(&d.members)
+		c.raws = maps.Clone(d.raws)
+		c.spans = maps.Clone(d.spans)
+	}
+	if err := c.Set(key, value); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Keys returns a copy of the keys in the dictionary, in order. The
+// returned slice is safe to modify without affecting the dictionary.
 func (d *Dictionary) Keys() []string {
 	if d == nil {
 		return nil
 	}
-	return d.keys
+	return d.members.Keys()
+}
+
+// All returns an iterator over the keys and members of the dictionary,
+// in order, as in
+//
+//	for key, member := range dict.All() {
+//		...
+//	}
+//
+// The members are the same values as returned by Get. The dictionary
+// must not be modified during the iteration.
+func (d *Dictionary) All() iter.Seq2[string, any] {
+	if d == nil {
+		return func(func(string, any) bool) {}
+	}
+	return d.members.All()
+}
+
+// Values returns an iterator over the members of the dictionary, in
+// order. Keys already returns a slice, which can be ranged over in the
+// same way. The dictionary must not be modified during the iteration.
+func (d *Dictionary) Values() iter.Seq[any] {
+	if d == nil {
+		return func(func(any) bool) {}
+	}
+	return d.members.Values()
+}
+
+// GetItem returns the member with the given key as an Item. Members that
+// were set as a BareItem are converted using ToItem. It is an error if
+// there is no such member, or if it is an Inner List.
+func (d *Dictionary) GetItem(key string) (Item, error) {
+	v, ok := d.Get(key)
+	if !ok {
+		return nil, fmt.Errorf("key %q not found in dictionary", key)
+	}
+	switch v := v.(type) {
+	case Item:
+		return v, nil
+	case BareItem:
+		return v.ToItem(), nil
+	default:
+		return nil, fmt.Errorf("member %q is not an item, got %T", key, v)
+	}
+}
+
+// GetInnerList returns the member with the given key, which must be an
+// Inner List.
+func (d *Dictionary) GetInnerList(key string) (*InnerList, error) {
+	v, ok := d.Get(key)
+	if !ok {
+		return nil, fmt.Errorf("key %q not found in dictionary", key)
+	}
+	il, ok := v.(*InnerList)
+	if !ok {
+		return nil, fmt.Errorf("member %q is not an inner list, got %T", key, v)
+	}
+	return il, nil
+}
+
+// GetString returns the value of the member with the given key, which
+// must be a String, a Token, or a Display String. Its parameters, if
+// any, are ignored.
+func (d *Dictionary) GetString(key string) (string, error) {
+	return getMemberValue[string](d, key, StringType, TokenType, DisplayStringType)
+}
+
+// GetInt64 returns the value of the member with the given key, which must
+// be an Integer. Its parameters, if any, are ignored.
+func (d *Dictionary) GetInt64(key string) (int64, error) {
+	return getMemberValue[int64](d, key, IntegerType)
+}
+
+// GetFloat64 returns the value of the member with the given key, which
+// must be a Decimal. Its parameters, if any, are ignored.
+func (d *Dictionary) GetFloat64(key string) (float64, error) {
+	return getMemberValue[float64](d, key, DecimalType)
+}
+
+// GetBool returns the value of the member with the given key, which must
+// be a Boolean. Members parsed from a bare key, as in "a, b", are true.
+// Its parameters, if any, are ignored.
+func (d *Dictionary) GetBool(key string) (bool, error) {
+	return getMemberValue[bool](d, key, BooleanType)
+}
+
+// GetBytes returns the value of the member with the given key, which must
+// be a Byte Sequence. Its parameters, if any, are ignored. The returned
+// slice is shared with the member, and must not be modified.
+func (d *Dictionary) GetBytes(key string) ([]byte, error) {
+	return getMemberValue[[]byte](d, key, ByteSequenceType)
+}
+
+// Get returns the member of d with the given key as a T. If the member
+// is of type T, such as *InnerList or Item, it is returned as is.
+// Otherwise it must be an Item whose value can be converted to T, as by
+// ItemValue, so that
+//
+//	maxAge, err := sfv.Get[int](dict, "max-age")
+//
+// fails if the member is missing, is not an Integer, or does not fit in
+// an int.
+func Get[T any](d *Dictionary, key string) (T, error) {
+	var zero T
+	v, ok := d.Get(key)
+	if !ok {
+		return zero, fmt.Errorf("key %q not found in dictionary", key)
+	}
+	if t, ok := v.(T); ok {
+		return t, nil
+	}
+	item, ok := v.(CoreItem)
+	if !ok {
+		return zero, fmt.Errorf("member %q is not an item, got %T", key, v)
+	}
+	t, err := ItemValue[T](item)
+	if err != nil {
+		return zero, fmt.Errorf("failed to get value of member %q: %w", key, err)
+	}
+	return t, nil
+}
+
+// getMemberValue returns the value of the item with the given key, which
+// must be of one of the given types
+func getMemberValue[T any](d *Dictionary, key string, types ...Type) (T, error) {
+	var zero T
+	item, err := d.GetItem(key)
+	if err != nil {
+		return zero, err
+	}
+	if !slices.Contains(types, item.Type()) {
+		return zero, fmt.Errorf("member %q is of type %s, expected %s", key, item.Type(), types[0])
+	}
+	var v T
+	if err := item.GetValue(&v); err != nil {
+		return zero, fmt.Errorf("failed to get value of member %q: %w", key, err)
+	}
+	return v, nil
 }
@@ -0,0 +1,295 @@
+package sfv_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDictionaryGet(t *testing.T) {
+	dict, err := sfv.ParseDictionaryString(`a=1, b=(x y), c, d;p`)
+	require.NoError(t, err)
+
+	require.True(t, dict.Has("a"))
+	require.False(t, dict.Has("z"))
+
+	v, ok := dict.Get("a")
+	require.True(t, ok)
+	require.IsType(t, &sfv.IntegerItem{}, v)
+
+	v, ok = dict.Get("b")
+	require.True(t, ok)
+	il, ok := v.(*sfv.InnerList)
+	require.True(t, ok, "expected *sfv.InnerList, got %T", v)
+	require.Equal(t, 2, il.Len())
+
+	v, ok = dict.Get("c")
+	require.True(t, ok)
+	require.Equal(t, sfv.True(), v, "bare keys are bare booleans")
+
+	v, ok = dict.Get("d")
+	require.True(t, ok)
+	require.Implements(t, (*sfv.Item)(nil), v, "bare keys with parameters are items")
+
+	v, ok = dict.Get("z")
+	require.False(t, ok)
+	require.Nil(t, v)
+
+	var nilDict *sfv.Dictionary
+	require.False(t, nilDict.Has("a"))
+	_, ok = nilDict.Get("a")
+	require.False(t, ok)
+}
+
+func TestDictionaryAt(t *testing.T) {
+	dict, err := sfv.ParseDictionaryString(`b=1, a=(x), c`)
+	require.NoError(t, err)
+
+	expected := []string{"b", "a", "c"}
+	for i, want := range expected {
+		key, value, ok := dict.At(i)
+		require.True(t, ok)
+		require.Equal(t, want, key)
+		member, _ := dict.Get(want)
+		require.Equal(t, member, value)
+	}
+
+	for _, i := range []int{-1, len(expected)} {
+		key, value, ok := dict.At(i)
+		require.False(t, ok, "index %d", i)
+		require.Empty(t, key)
+		require.Nil(t, value)
+	}
+
+	_, _, ok := (*sfv.Dictionary)(nil).At(0)
+	require.False(t, ok)
+}
+
+func TestDictionaryIterators(t *testing.T) {
+	dict, err := sfv.ParseDictionaryString(`b=1, a=(x), c`)
+	require.NoError(t, err)
+
+	var keys []string
+	var members []any
+	for key, member := range dict.All() {
+		keys = append(keys, key)
+		members = append(members, member)
+	}
+	require.Equal(t, dict.Keys(), keys)
+
+	var values []any
+	for member := range dict.Values() {
+		values = append(values, member)
+	}
+	require.Equal(t, members, values)
+
+	for key := range dict.All() {
+		require.Equal(t, "b", key)
+		break
+	}
+
+	for range (*sfv.Dictionary)(nil).All() {
+		t.Fatal("nil dictionary should have no members")
+	}
+}
+
+func TestDictionaryMerge(t *testing.T) {
+	d1, err := sfv.ParseDictionaryString(`a=1, b=2`, sfv.WithRawText(true))
+	require.NoError(t, err)
+	d2, err := sfv.ParseDictionaryString(`c=3, a=(x);p`, sfv.WithRawText(true))
+	require.NoError(t, err)
+
+	require.NoError(t, d1.Merge(d2))
+	marshaled, err := d1.MarshalSFV()
+	require.NoError(t, err)
+	require.Equal(t, `a=(x);p, b=2, c=3`, string(marshaled), "later members override earlier ones in place")
+	require.Equal(t, `a=(x);p`, string(d1.RawMember("a")))
+	require.Equal(t, `b=2`, string(d1.RawMember("b")))
+
+	// merging is the same as parsing both lines as a single field
+	combined, err := sfv.ParseDictionaryString(`a=1, b=2, c=3, a=(x);p`)
+	require.NoError(t, err)
+	require.True(t, sfv.Equal(combined, d1))
+
+	require.NoError(t, d1.Merge(nil))
+	require.Equal(t, []string{"a", "b", "c"}, d1.Keys())
+
+	var d3 sfv.Dictionary
+	require.NoError(t, d3.Merge(d2))
+	require.True(t, sfv.Equal(d2, &d3))
+}
+
+func TestDictionaryTypedGetters(t *testing.T) {
+	dict, err := sfv.ParseDictionaryString(`s="x", t=tok, n=42;p, f=1.5, b, c=?0, bs=:AQID:, il=(a b)`)
+	require.NoError(t, err)
+
+	s, err := dict.GetString("s")
+	require.NoError(t, err)
+	require.Equal(t, "x", s)
+
+	s, err = dict.GetString("t")
+	require.NoError(t, err)
+	require.Equal(t, "tok", s)
+
+	n, err := dict.GetInt64("n")
+	require.NoError(t, err)
+	require.Equal(t, int64(42), n)
+
+	f, err := dict.GetFloat64("f")
+	require.NoError(t, err)
+	require.Equal(t, 1.5, f)
+
+	b, err := dict.GetBool("b")
+	require.NoError(t, err)
+	require.True(t, b)
+
+	b, err = dict.GetBool("c")
+	require.NoError(t, err)
+	require.False(t, b)
+
+	bs, err := dict.GetBytes("bs")
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, bs)
+
+	il, err := dict.GetInnerList("il")
+	require.NoError(t, err)
+	require.Equal(t, 2, il.Len())
+
+	item, err := dict.GetItem("n")
+	require.NoError(t, err)
+	require.Equal(t, 1, item.Parameters().Len())
+
+	item, err = dict.GetItem("b")
+	require.NoError(t, err)
+	require.Equal(t, sfv.BooleanType, item.Type())
+
+	_, err = dict.GetInt64("s")
+	require.ErrorContains(t, err, `member "s" is of type string, expected integer`)
+	_, err = dict.GetString("missing")
+	require.Error(t, err)
+	_, err = dict.GetItem("il")
+	require.Error(t, err)
+	_, err = dict.GetInnerList("n")
+	require.Error(t, err)
+}
+
+func TestDictionarySetWithParams(t *testing.T) {
+	dict := sfv.NewDictionary()
+	require.NoError(t, dict.SetWithParams("c", sfv.True(), map[string]any{"foo": sfv.BareToken("bar")}))
+	require.NoError(t, dict.SetWithParams("n", 10, map[string]any{"unit": "s", "approx": true}))
+	require.NoError(t, dict.SetWithParams("l", []int{1, 2}, map[string]any{"n": 2}))
+	require.NoError(t, dict.SetWithParams("p", "plain", nil))
+
+	marshaled, err := dict.MarshalSFV()
+	require.NoError(t, err)
+	require.Equal(t, `c;foo=bar, n=10;approx;unit="s", l=(1 2);n=2, p="plain"`, string(marshaled))
+
+	il := sfv.NewInnerList()
+	require.NoError(t, il.Add(sfv.Token("x")))
+	require.NoError(t, dict.SetWithParams("il", il, map[string]any{"k": 1}))
+	require.Zero(t, il.Parameters().Len(), "the value passed in should not be modified")
+
+	require.Error(t, dict.SetWithParams("Bad", 1, nil))
+	require.Error(t, dict.SetWithParams("a", 1, map[string]any{"bad key": 1}))
+	require.Error(t, dict.SetWithParams("a", 1, map[string]any{"k": []int{1}}))
+	require.False(t, dict.Has("a"), "nothing should be set on error")
+}
+
+func TestDictionarySetAll(t *testing.T) {
+	dict := sfv.NewDictionary()
+	require.NoError(t, dict.Set("z", sfv.Integer(0)))
+	require.NoError(t, dict.SetAll(map[string]any{"b": "x", "a": 1, "z": true, "c": []string{"y"}}))
+
+	marshaled, err := dict.MarshalSFV()
+	require.NoError(t, err)
+	require.Equal(t, `z, a=1, b="x", c=("y")`, string(marshaled))
+
+	dict = sfv.NewDictionary()
+	require.NoError(t, dict.SetPairs(
+		sfv.Pair{Key: "u", Value: 1},
+		sfv.Pair{Key: "i", Value: sfv.True()},
+		sfv.Pair{Key: "u", Value: 3},
+	))
+	marshaled, err = dict.MarshalSFV()
+	require.NoError(t, err)
+	require.Equal(t, `u=3, i`, string(marshaled))
+
+	err = dict.SetAll(map[string]any{"a": 1, "b": make(chan int)})
+	require.Error(t, err)
+	err = dict.SetPairs(sfv.Pair{Key: "a", Value: 1}, sfv.Pair{Key: "Bad", Value: 2})
+	require.Error(t, err)
+	require.Equal(t, []string{"u", "i"}, dict.Keys(), "nothing should be set on error")
+}
+
+func TestDictionaryBooleanItems(t *testing.T) {
+	params := sfv.NewParameters()
+	require.NoError(t, params.Set("p", 1))
+
+	dict := sfv.NewDictionary()
+	require.NoError(t, dict.Set("a", sfv.TrueItem()))
+	require.NoError(t, dict.Set("b", sfv.FalseItem()))
+	require.NoError(t, dict.Set("c", sfv.TrueItem().With(params)))
+	require.Equal(t, `a, b=?0, c;p=1`, marshalString(t, dict))
+
+	require.True(t, sfv.Equal(sfv.Boolean(true), sfv.TrueItem()))
+	require.True(t, sfv.Equal(sfv.Boolean(false), sfv.FalseItem()))
+	require.Equal(t, 0, sfv.TrueItem().Parameters().Len())
+}
+
+func TestGenericGet(t *testing.T) {
+	dict, err := sfv.ParseDictionaryString(`max-age=60;p, name=tok, q=0.5, ok, sig=:AQID:, t=@1, l=(a b)`)
+	require.NoError(t, err)
+
+	maxAge, err := sfv.Get[int](dict, "max-age")
+	require.NoError(t, err)
+	require.Equal(t, 60, maxAge)
+
+	maxAge64, err := sfv.Get[int64](dict, "max-age")
+	require.NoError(t, err)
+	require.Equal(t, int64(60), maxAge64)
+
+	name, err := sfv.Get[string](dict, "name")
+	require.NoError(t, err)
+	require.Equal(t, "tok", name)
+
+	q, err := sfv.Get[float64](dict, "q")
+	require.NoError(t, err)
+	require.InDelta(t, 0.5, q, 0)
+
+	ok, err := sfv.Get[bool](dict, "ok")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	sig, err := sfv.Get[[]byte](dict, "sig")
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, sig)
+
+	ts, err := sfv.Get[time.Time](dict, "t")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), ts.Unix())
+
+	il, err := sfv.Get[*sfv.InnerList](dict, "l")
+	require.NoError(t, err)
+	require.Equal(t, 2, il.Len())
+
+	item, err := sfv.Get[sfv.Item](dict, "max-age")
+	require.NoError(t, err)
+	require.Equal(t, 1, item.Parameters().Len())
+
+	_, err = sfv.Get[int](dict, "missing")
+	require.Error(t, err)
+	_, err = sfv.Get[string](dict, "max-age")
+	require.Error(t, err)
+	_, err = sfv.Get[int8](dict, "t")
+	require.NoError(t, err)
+	_, err = sfv.Get[int](dict, "l")
+	require.Error(t, err)
+
+	v, err := sfv.ItemValue[string](sfv.BareToken("x"))
+	require.NoError(t, err)
+	require.Equal(t, "x", v)
+	_, err = sfv.ItemValue[string](nil)
+	require.Error(t, err)
+}
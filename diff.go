@@ -0,0 +1,196 @@
+package sfv
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// DifferenceKind describes how a value differs between the two arguments
+// of Diff.
+type DifferenceKind int
+
+const (
+	// Added means that the value is only present in the second argument
+	Added DifferenceKind = iota + 1
+	// Removed means that the value is only present in the first argument
+	Removed
+	// Changed means that the value is present in both arguments, but is
+	// not the same
+	Changed
+)
+
+func (k DifferenceKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	default:
+		return "DifferenceKind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// Difference is a single difference reported by Diff.
+//
+// Path locates the value that differs: Dictionary keys are written as is,
+// List and Inner List members as an index in brackets, and parameters as
+// their key preceded by a semicolon, so that "sig1[0];x" refers to the
+// parameter x of the first item of the Inner List at key sig1. Path is
+// empty when the values passed to Diff differ as a whole.
+//
+// Old holds the value in the first argument, and New the value in the
+// second one. Old is nil for additions, and New is nil for removals. For
+// Items whose value changed, Old and New are the bare items, as their
+// parameters are compared separately.
+type Difference struct {
+	Kind DifferenceKind
+	Path string
+	Old  any
+	New  any
+}
+
+// String returns a one line description of the difference, such as
+// `changed a;q: 1 -> 2`.
+func (d Difference) String() string {
+	path := d.Path
+	if path == "" {
+		path = "value"
+	}
+	switch d.Kind {
+	case Added:
+		return fmt.Sprintf("added %s: %s", path, formatDiffValue(d.New))
+	case Removed:
+		return fmt.Sprintf("removed %s: %s", path, formatDiffValue(d.Old))
+	default:
+		return fmt.Sprintf("%s %s: %s -> %s", d.Kind, path, formatDiffValue(d.Old), formatDiffValue(d.New))
+	}
+}
+
+// formatDiffValue returns the serialization of v, falling back to its Go
+// representation if it cannot be serialized
+func formatDiffValue(v any) string {
+	if m, ok := v.(Marshaler); ok {
+		if b, err := m.MarshalSFV(); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// Diff compares a and b, and reports the Dictionary members, List and
+// Inner List members, parameters, and items that were added, removed,
+// or changed to turn a into b. Values are compared as by Equal, so Diff
+// returns no differences if and only if Equal(a, b) is true.
+//
+// Members of Lists and Inner Lists are compared by position, so inserting
+// a member reports every following member as changed. When Dictionary
+// members or parameters have the same keys but in a different order, the
+// whole Dictionary or Parameters is reported as changed.
+func Diff(a, b any) []Difference {
+	return diffValues(nil, "", a, b)
+}
+
+func diffValues(diffs []Difference, path string, a, b any) []Difference {
+	if l, ok := a.(List); ok {
+		a = &l
+	}
+	if l, ok := b.(List); ok {
+		b = &l
+	}
+
+	n := len(diffs)
+	switch a := a.(type) {
+	case *Dictionary:
+		// Dictionaries only appear at the top level, so keys are paths
+		if b, ok := b.(*Dictionary); ok && a != nil && b != nil {
+			for key, av := range a.All() {
+				bv, ok := b.Get(key)
+				if !ok {
+					diffs = append(diffs, Difference{Kind: Removed, Path: key, Old: av})
+					continue
+				}
+				diffs = diffValues(diffs, key, av, bv)
+			}
+			for key, bv := range b.All() {
+				if !a.Has(key) {
+					diffs = append(diffs, Difference{Kind: Added, Path: key, New: bv})
+				}
+			}
+		}
+	case *List:
+		if b, ok := b.(*List); ok && a != nil && b != nil {
+			diffs = diffSequences(diffs, path, a.values, b.values)
+		}
+	case *InnerList:
+		if b, ok := b.(*InnerList); ok && a != nil && b != nil {
+			diffs = diffSequences(diffs, path, a.values, b.values)
+			diffs = diffParameters(diffs, path, a.params, b.params)
+		}
+	case CoreItem:
+		if b, ok := b.(CoreItem); ok {
+			if a.Type() != b.Type() || !bareValuesEqual(a, b) {
+				diffs = append(diffs, Difference{Kind: Changed, Path: path, Old: bareOf(a), New: bareOf(b)})
+			}
+			diffs = diffParameters(diffs, path, itemParameters(a), itemParameters(b))
+		}
+	}
+
+	// values of different types, and differences that are not specific
+	// to a member, such as the order of keys, are reported as a whole
+	if len(diffs) == n && !Equal(a, b) {
+		diffs = append(diffs, Difference{Kind: Changed, Path: path, Old: a, New: b})
+	}
+	return diffs
+}
+
+func diffSequences[T any](diffs []Difference, path string, a, b []T) []Difference {
+	for i := range max(len(a), len(b)) {
+		ipath := path + "[" + strconv.Itoa(i) + "]"
+		switch {
+		case i >= len(b):
+			diffs = append(diffs, Difference{Kind: Removed, Path: ipath, Old: a[i]})
+		case i >= len(a):
+			diffs = append(diffs, Difference{Kind: Added, Path: ipath, New: b[i]})
+		default:
+			diffs = diffValues(diffs, ipath, a[i], b[i])
+		}
+	}
+	return diffs
+}
+
+func diffParameters(diffs []Difference, path string, a, b *Parameters) []Difference {
+	n := len(diffs)
+	if a.Len() > 0 {
+		for _, key := range a.Keys() {
+			bv, ok := b.Lookup(key)
+			if !ok {
+				diffs = append(diffs, Difference{Kind: Removed, Path: path + ";" + key, Old: a.entries.values[key]})
+				continue
+			}
+			if !itemsEqual(a.entries.values[key], bv) {
+				diffs = append(diffs, Difference{Kind: Changed, Path: path + ";" + key, Old: a.entries.values[key], New: bv})
+			}
+		}
+	}
+	if b.Len() > 0 {
+		for _, key := range b.Keys() {
+			if _, ok := a.Lookup(key); !ok {
+				diffs = append(diffs, Difference{Kind: Added, Path: path + ";" + key, New: b.entries.values[key]})
+			}
+		}
+	}
+	if len(diffs) == n && !parametersEqual(a, b) {
+		diffs = append(diffs, Difference{Kind: Changed, Path: path, Old: a, New: b})
+	}
+	return diffs
+}
+
+// bareOf returns v without its parameters
+func bareOf(v CoreItem) CoreItem {
+	if fi, ok := v.(interface{ bareItem() BareItem }); ok {
+		return fi.bareItem()
+	}
+	return v
+}
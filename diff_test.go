@@ -0,0 +1,55 @@
+package sfv_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	parse := func(s string) any {
+		t.Helper()
+		v, err := sfv.ParseString(s)
+		require.NoError(t, err)
+		return v
+	}
+	diff := func(a, b any) []string {
+		var ret []string
+		for _, d := range sfv.Diff(a, b) {
+			ret = append(ret, d.String())
+		}
+		return ret
+	}
+
+	testcases := []struct {
+		name     string
+		a, b     string
+		expected []string
+	}{
+		{"equal", `a;q=1, (b c)`, `a;q=1,(b c)`, nil},
+		{"list members", `a, b, c`, `a, x`, []string{"changed [1]: b -> x", "removed [2]: c"}},
+		{"added member", `a`, `a, (b)`, []string{"added [1]: (b)"}},
+		{"parameters", `a;q=1;x, b`, `a;q=2;y, b`, []string{"changed [0];q: 1 -> 2", "removed [0];x: ?1", "added [0];y: ?1"}},
+		{"item and parameters", `a;q=1`, `"a";q=1`, []string{`changed [0]: a -> "a"`}},
+		{"inner list", `(a b);p=1`, `(a c d);p=2`, []string{"changed [0][1]: b -> c", "added [0][2]: d", "changed [0];p: 1 -> 2"}},
+		{"item to inner list", `a`, `(a)`, []string{"changed [0]: a -> (a)"}},
+		{"dictionary", `sig1=(a b);created=1, sig2=x, old`, `sig1=(a b);created=2, sig2=y, new=?0`, []string{"changed sig1;created: 1 -> 2", "changed sig2: x -> y", "removed old: ?1", "added new: ?0"}},
+		{"key order", `a=1, b=2`, `b=2, a=1`, []string{"changed value: a=1, b=2 -> b=2, a=1"}},
+		{"parameter order", `a;x;y`, `a;y;x`, []string{"changed [0]: ;x;y -> ;y;x"}},
+		{"list vs dictionary", `a, b`, `a=1`, []string{"changed value: a, b -> a=1"}},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			a, b := parse(tc.a), parse(tc.b)
+			require.Equal(t, tc.expected, diff(a, b))
+			require.Equal(t, len(tc.expected) == 0, sfv.Equal(a, b), "Diff should agree with Equal")
+		})
+	}
+
+	diffs := sfv.Diff(parse(`a;q=1`), parse(`a;q=2`))
+	require.Len(t, diffs, 1)
+	require.Equal(t, sfv.Changed, diffs[0].Kind)
+	require.Equal(t, "[0];q", diffs[0].Path)
+	require.Equal(t, sfv.BareInteger(1), diffs[0].Old)
+}
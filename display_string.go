@@ -1,8 +1,9 @@
 package sfv
 
 import (
-	"bytes"
 	"fmt"
+	"strconv"
+	"unicode/utf8"
 )
 
 // DisplayStringItem represents a percent-encoded display string value,
@@ -52,6 +53,17 @@ func BareDisplayString(s string) *DisplayStringBareItem {
 	return &v
 }
 
+// DisplayStringStrict creates a new DisplayStringBareItem with the given
+// string, like BareDisplayString, but returns an error if s is not valid
+// UTF-8. Such strings are otherwise serialized with each invalid byte
+// replaced by U+FFFD.
+func DisplayStringStrict(s string) (*DisplayStringBareItem, error) {
+	if !utf8.ValidString(s) {
+		return nil, fmt.Errorf("sfv: display string %q is not valid UTF-8", s)
+	}
+	return BareDisplayString(s), nil
+}
+
 // ToItem converts the DisplayStringBareItem to a full Item.
 func (d *DisplayStringBareItem) ToItem() Item {
 	return d.toItem()
@@ -59,29 +71,47 @@ func (d *DisplayStringBareItem) ToItem() Item {
 
 // MarshalSFV implements the Marshaler interface for DisplayStringBareItem.
 func (d DisplayStringBareItem) MarshalSFV() ([]byte, error) {
-	var buf bytes.Buffer
-	buf.WriteByte('%')
-	buf.WriteByte('"')
+	return d.AppendSFV(nil)
+}
+
+// AppendSFV appends the serialization of the DisplayStringBareItem to dst.
+func (d DisplayStringBareItem) AppendSFV(dst []byte) ([]byte, error) {
+	const hexDigits = "0123456789abcdef"
+
+	dst = append(dst, '%', '"')
 	// Percent-encode non-ASCII characters
 	for _, r := range d.value {
 		if r <= 127 && r >= 32 && r != '%' {
 			// ASCII printable characters except %
-			buf.WriteRune(r)
-		} else {
-			// Percent-encode everything else
-			utf8Bytes := []byte(string(r))
-			for _, b := range utf8Bytes {
-				buf.WriteString(fmt.Sprintf("%%%.2x", b))
-			}
+			dst = append(dst, byte(r))
+			continue
+		}
+
+		// Percent-encode everything else
+		var buf [utf8.UTFMax]byte
+		for _, b := range utf8.AppendRune(buf[:0], r) {
+			dst = append(dst, '%', hexDigits[b>>4], hexDigits[b&0x0f])
 		}
 	}
-	buf.WriteByte('"')
-	return buf.Bytes(), nil
+	dst = append(dst, '"')
+	return dst, nil
 }
 
 // Type returns the type of the DisplayStringBareItem, useful when
 // you have a list of BareItems and need to know the type
 // of each item.
-func (d DisplayStringBareItem) Type() int {
+func (d DisplayStringBareItem) Type() Type {
 	return DisplayStringType
 }
+
+// AsString returns the decoded string held by the DisplayStringBareItem. The second return
+// value is always true.
+func (d DisplayStringBareItem) AsString() (string, bool) {
+	return d.value, true
+}
+
+// GoString returns Go-like notation for the DisplayStringBareItem, which
+// is used by the %#v verb.
+func (d DisplayStringBareItem) GoString() string {
+	return "sfv.BareDisplayString(" + strconv.Quote(d.value) + ")"
+}
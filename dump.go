@@ -0,0 +1,136 @@
+package sfv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dump returns a human readable, indented representation of v, which
+// shows the type of each value, its parameters, and how Inner Lists are
+// nested. It is meant for inspecting long fields, such as Signature-Input
+// or Proxy-Status, during development. For example, the Dictionary
+// `sig1=("@method" "@path");created=1618884473, sig2=:AQID:` is dumped as
+//
+//	Dictionary (2 members)
+//	  sig1: InnerList (2 items)
+//	    [0] string "@method"
+//	    [1] string "@path"
+//	    ;created integer 1618884473
+//	  sig2: byte sequence :AQID:
+//
+// The output format is not stable, and must not be parsed.
+func Dump(v Value) string {
+	var sb strings.Builder
+	dumpValue(&sb, v, 0)
+	return sb.String()
+}
+
+func dumpValue(sb *strings.Builder, v any, depth int) {
+	switch v := v.(type) {
+	case nil:
+		sb.WriteString("<nil>\n")
+	case *List:
+		if v == nil {
+			sb.WriteString("<nil List>\n")
+			return
+		}
+		dumpList(sb, v, depth)
+	case List:
+		dumpList(sb, &v, depth)
+	case *Dictionary:
+		if v == nil {
+			sb.WriteString("<nil Dictionary>\n")
+			return
+		}
+		n := v.members.Len()
+		fmt.Fprintf(sb, "Dictionary (%d %s)\n", n, plural(n, "member", "members"))
+		for key, member := range v.All() {
+			dumpIndent(sb, depth+1)
+			sb.WriteString(key)
+			sb.WriteString(": ")
+			dumpValue(sb, member, depth+1)
+		}
+	case *InnerList:
+		if v == nil {
+			sb.WriteString("<nil InnerList>\n")
+			return
+		}
+		fmt.Fprintf(sb, "InnerList (%d %s)\n", len(v.values), plural(len(v.values), "item", "items"))
+		for i, item := range v.values {
+			dumpIndent(sb, depth+1)
+			fmt.Fprintf(sb, "[%d] ", i)
+			dumpValue(sb, item, depth+1)
+		}
+		dumpParameters(sb, v.params, depth+1)
+	case *Parameters:
+		if v == nil {
+			sb.WriteString("<nil Parameters>\n")
+			return
+		}
+		fmt.Fprintf(sb, "Parameters (%d)\n", v.Len())
+		dumpParameters(sb, v, depth+1)
+	case Item:
+		// the parameters are written separately
+		dumpBareItem(sb, bareOf(v))
+		dumpParameters(sb, v.Parameters(), depth+1)
+	case BareItem:
+		dumpBareItem(sb, v)
+	case Marshaler:
+		b, err := v.MarshalSFV()
+		if err != nil {
+			fmt.Fprintf(sb, "%T <error: %v>\n", v, err)
+			return
+		}
+		fmt.Fprintf(sb, "%T %s\n", v, b)
+	default:
+		fmt.Fprintf(sb, "%T %v\n", v, v)
+	}
+}
+
+func dumpList(sb *strings.Builder, l *List, depth int) {
+	fmt.Fprintf(sb, "List (%d %s)\n", len(l.values), plural(len(l.values), "member", "members"))
+	for i, member := range l.values {
+		dumpIndent(sb, depth+1)
+		fmt.Fprintf(sb, "[%d] ", i)
+		dumpValue(sb, member, depth+1)
+	}
+}
+
+func dumpParameters(sb *strings.Builder, params *Parameters, depth int) {
+	for _, key := range params.Keys() {
+		dumpIndent(sb, depth)
+		sb.WriteByte(';')
+		sb.WriteString(key)
+		sb.WriteByte(' ')
+		dumpBareItem(sb, params.entries.values[key])
+	}
+}
+
+// dumpBareItem writes the type of v followed by its serialization, or by
+// its Go value if it cannot be serialized
+func dumpBareItem(sb *strings.Builder, v CoreItem) {
+	sb.WriteString(v.Type().String())
+	sb.WriteByte(' ')
+	b, err := v.AppendSFV(nil)
+	if err != nil {
+		var value any
+		_ = v.GetValue(&value)
+		fmt.Fprintf(sb, "%v <error: %v>\n", value, err)
+		return
+	}
+	sb.Write(b)
+	sb.WriteByte('\n')
+}
+
+func dumpIndent(sb *strings.Builder, depth int) {
+	for range depth {
+		sb.WriteString("  ")
+	}
+}
+
+func plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return singular
+	}
+	return pluralForm
+}
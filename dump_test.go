@@ -0,0 +1,38 @@
+package sfv_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDump(t *testing.T) {
+	dict, err := sfv.ParseDictionaryString(`sig1=("@method" "@path");created=1618884473, sig2=:AQID:`)
+	require.NoError(t, err)
+	require.Equal(t, `Dictionary (2 members)
+  sig1: InnerList (2 items)
+    [0] string "@method"
+    [1] string "@path"
+    ;created integer 1618884473
+  sig2: byte sequence :AQID:
+`, sfv.Dump(dict))
+
+	list, err := sfv.ParseString(`gzip;q=0.5;final, (a);x, ?1, %"caf%c3%a9", @1`)
+	require.NoError(t, err)
+	require.Equal(t, `List (5 members)
+  [0] token gzip
+    ;q decimal 0.5
+    ;final boolean ?1
+  [1] InnerList (1 item)
+    [0] token a
+    ;x boolean ?1
+  [2] boolean ?1
+  [3] display string %"caf%c3%a9"
+  [4] date @1
+`, sfv.Dump(list.(*sfv.List)))
+
+	require.Equal(t, "integer 42\n", sfv.Dump(sfv.Integer(42)))
+	require.Equal(t, "token foo\n", sfv.Dump(sfv.BareToken("foo")))
+	require.Equal(t, "<nil List>\n", sfv.Dump((*sfv.List)(nil)))
+}
@@ -0,0 +1,137 @@
+package sfv
+
+import (
+	"bytes"
+	"reflect"
+)
+
+// Equal reports whether a and b hold the same structured value. Unlike
+// comparing serializations, it does not depend on formatting options, and
+// unlike reflect.DeepEqual, it does not depend on internal state such as
+// the raw input text or whether a byte sequence has been decoded yet.
+//
+// Items are equal if they are of the same type, hold the same value, and
+// have the same parameters in the same order. A BareItem is equal to an
+// Item without parameters that holds the same value, as the two
+// serialize identically. Inner Lists and Lists are equal if their members
+// are equal and in the same order, and Dictionaries if they have the same
+// keys in the same order, with equal members. Values of other types are
+// compared using their MarshalSFV method if they implement Marshaler, or
+// reflect.DeepEqual otherwise.
+func Equal(a, b any) bool {
+	if l, ok := a.(List); ok {
+		a = &l
+	}
+	if l, ok := b.(List); ok {
+		b = &l
+	}
+
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case CoreItem:
+		b, ok := b.(CoreItem)
+		return ok && itemsEqual(a, b)
+	case *InnerList:
+		b, ok := b.(*InnerList)
+		if !ok || a.Len() != b.Len() || !parametersEqual(a.Parameters(), b.Parameters()) {
+			return false
+		}
+		for i := range a.Len() {
+			if !itemsEqual(a.values[i], b.values[i]) {
+				return false
+			}
+		}
+		return true
+	case *List:
+		b, ok := b.(*List)
+		if !ok || a.Len() != b.Len() {
+			return false
+		}
+		for i := range a.Len() {
+			if !Equal(a.values[i], b.values[i]) {
+				return false
+			}
+		}
+		return true
+	case *Dictionary:
+		b, ok := b.(*Dictionary)
+		if !ok {
+			return false
+		}
+		akeys, bkeys := a.Keys(), b.Keys()
+		if len(akeys) != len(bkeys) {
+			return false
+		}
+		for i, key := range akeys {
+			av, _ := a.Get(key)
+			bv, _ := b.Get(key)
+			if bkeys[i] != key || !Equal(av, bv) {
+				return false
+			}
+		}
+		return true
+	case *Parameters:
+		b, ok := b.(*Parameters)
+		return ok && parametersEqual(a, b)
+	case Marshaler:
+		b, ok := b.(Marshaler)
+		if !ok {
+			return false
+		}
+		ab, aerr := a.MarshalSFV()
+		bb, berr := b.MarshalSFV()
+		return aerr == nil && berr == nil && bytes.Equal(ab, bb)
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+// itemsEqual reports whether two items, either of which may be bare, are
+// equal
+func itemsEqual(a, b CoreItem) bool {
+	if a.Type() != b.Type() || !bareValuesEqual(a, b) {
+		return false
+	}
+	return parametersEqual(itemParameters(a), itemParameters(b))
+}
+
+// bareValuesEqual reports whether two items of the same type hold the
+// same value, ignoring their parameters
+func bareValuesEqual(a, b CoreItem) bool {
+	var av, bv any
+	if a.GetValue(&av) != nil || b.GetValue(&bv) != nil {
+		return false
+	}
+	if ab, ok := av.([]byte); ok {
+		bb, ok := bv.([]byte)
+		return ok && bytes.Equal(ab, bb)
+	}
+	return av == bv
+}
+
+// itemParameters returns the parameters of v, which are nil for bare items
+func itemParameters(v CoreItem) *Parameters {
+	if item, ok := v.(Item); ok {
+		return item.Parameters()
+	}
+	return nil
+}
+
+// parametersEqual reports whether a and b have the same keys in the same
+// order, with equal values. nil and empty parameters are equal.
+func parametersEqual(a, b *Parameters) bool {
+	if a.Len() != b.Len() {
+		return false
+	}
+	if a.Len() == 0 {
+		return true
+	}
+	bkeys := b.Keys()
+	for i, key := range a.Keys() {
+		if bkeys[i] != key || !itemsEqual(a.entries.values[key], b.entries.values[key]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package sfv_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEqual(t *testing.T) {
+	parse := func(s string) any {
+		t.Helper()
+		v, err := sfv.ParseString(s)
+		require.NoError(t, err)
+		return v
+	}
+	item := func(s string) any {
+		t.Helper()
+		v, err := sfv.ParseItemString(s)
+		require.NoError(t, err)
+		return v
+	}
+
+	testcases := []struct {
+		name  string
+		a, b  any
+		equal bool
+	}{
+		{"same list", parse(`a;q=1, ("x" 2);p, :AQID:`), parse(`a;q=1,("x" 2);p,:AQID:`), true},
+		{"different member order", parse(`a, b`), parse(`b, a`), false},
+		{"different parameter order", parse(`a;x;y`), parse(`a;y;x`), false},
+		{"different parameter value", parse(`a;q=1`), parse(`a;q=2`), false},
+		{"missing parameter", parse(`a;q=1`), parse(`a`), false},
+		{"different item type", parse(`"a"`), parse(`a`), false},
+		{"different byte sequences", parse(`:AQID:`), parse(`:AQIE:`), false},
+		{"same dictionary", parse(`a=1, b=(x y);z`), parse(`a=1, b=(x y);z`), true},
+		{"different key order", parse(`a=1, b=2`), parse(`b=2, a=1`), false},
+		{"list vs dictionary", parse(`a, b`), parse(`a=1, b`), false},
+		{"bare vs full item", sfv.BareToken("a"), sfv.Token("a"), true},
+		{"bare vs item with parameters", sfv.BareToken("a"), item(`a;x`), false},
+		{"constructed vs parsed", sfv.Decimal(1.5), item(`1.5`), true},
+		{"nil", nil, nil, true},
+		{"nil vs value", nil, sfv.Integer(1), false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.equal, sfv.Equal(tc.a, tc.b))
+			require.Equal(t, tc.equal, sfv.Equal(tc.b, tc.a), "Equal should be symmetric")
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package sfv
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrEmptyField is returned by the encoding functions when
+// WithEmptyFieldError is enabled and the value to encode is a List or a
+// Dictionary with no members. RFC 9651 specifies that such fields must
+// not be serialized at all, so callers should omit the field instead of
+// sending an empty value.
+var ErrEmptyField = errors.New("sfv: field has no members")
+
+// ErrFrozen is returned by the methods that modify a Dictionary, List,
+// Inner List, Item, or Parameters after it has been frozen with Freeze.
+var ErrFrozen = errors.New("sfv: value is frozen")
+
+// ParseError is returned when parsing fails at a known location in the
+// input. Offset is the byte offset, counted from the start of the input,
+// at which the parser gave up. All the parsing functions report errors
+// as a *ParseError, or, when WithErrorAggregation is used, as several of
+// them combined with errors.Join. Use FormatParseError to show where the
+// error is in the input.
+type ParseError struct {
+	Offset int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("sfv: parse error at offset %d: %v", e.Offset, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,326 @@
+package sfv
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+
+	"github.com/lestrrat-go/sfv/internal/tokens"
+)
+
+// EventKind identifies the type of an Event reported by ParseEvents.
+type EventKind int
+
+const (
+	InvalidEvent EventKind = iota
+	// StartListEvent marks the beginning of a List field
+	StartListEvent
+	// StartDictionaryEvent marks the beginning of a Dictionary field
+	StartDictionaryEvent
+	// StartInnerListEvent marks the beginning of an Inner List
+	StartInnerListEvent
+	// EndEvent marks the end of the most recently started List,
+	// Dictionary, or Inner List
+	EndEvent
+	// KeyEvent reports the key of a Dictionary member. It is followed
+	// by the events describing the member value
+	KeyEvent
+	// BareItemEvent reports the bare item of an Item
+	BareItemEvent
+	// ParamEvent reports a parameter. Parameters are reported right
+	// after the bare item, or the end of the inner list, they belong to
+	ParamEvent
+)
+
+// Event describes a single syntactic element found by ParseEvents.
+type Event struct {
+	Kind EventKind
+	// Key holds the key for KeyEvent and ParamEvent
+	Key string
+	// Value holds the value for BareItemEvent and ParamEvent
+	Value BareItem
+}
+
+// ParseEvents parses data as a structured field of type ft, reporting each
+// syntactic element to fn as an Event, instead of building Items, Lists,
+// and Dictionaries. This is useful for building custom representations,
+// or for validating fields without keeping their contents around.
+//
+// For a List field, the events look like this:
+//
+//	StartList
+//	  BareItem, Param...               (for each Item member)
+//	  StartInnerList                   (for each Inner List member)
+//	    BareItem, Param...             (for each Item in the Inner List)
+//	  End, Param...
+//	End
+//
+// Dictionary fields are the same, except that they start with StartDictionary,
+// and each member is preceded by a Key event. A Dictionary member without a
+// value is reported as a Boolean true BareItem. Item fields are reported as
+// a BareItem followed by its parameters, without Start and End events.
+//
+// Duplicate Dictionary keys and parameter keys are reported each time they
+// appear. Per RFC 9651, the last occurrence is the one that counts.
+//
+// If fn returns ErrStop, parsing stops and ParseEvents returns nil. If fn
+// returns any other error, parsing stops and that error is returned as is.
+func ParseEvents(data []byte, ft FieldType, fn func(Event) error, options ...ParseOption) error {
+	mode, err := ft.parseMode()
+	if err != nil {
+		return err
+	}
+
+	pctx := getParseContext()
+	defer releaseParseContext(pctx)
+
+	pctx.init(data, mode, options)
+	h := &eventHandler{fn: fn}
+	if err := pctx.emit(h); err != nil {
+		if h.err != nil {
+			if errors.Is(h.err, ErrStop) {
+				return nil
+			}
+			return h.err
+		}
+		return pctx.positionalError(err)
+	}
+	return nil
+}
+
+// Validate checks that data is a well-formed structured field of type ft,
+// without building the Items, Lists, and Dictionaries that the other
+// parsing functions return. Use this when the contents of the field are
+// not needed, for example to reject malformed fields early.
+func Validate(data []byte, ft FieldType, options ...ParseOption) error {
+	mode, err := ft.parseMode()
+	if err != nil {
+		return err
+	}
+
+	pctx := getParseContext()
+	defer releaseParseContext(pctx)
+
+	pctx.init(data, mode, options)
+	if err := pctx.emit(&eventHandler{}); err != nil {
+		return pctx.positionalError(err)
+	}
+	return nil
+}
+
+// eventHandler wraps the user-supplied callback, so that errors returned
+// by the callback can be told apart from parse errors. A handler without
+// a callback discards all events
+type eventHandler struct {
+	fn  func(Event) error
+	err error
+}
+
+func (h *eventHandler) handle(ev Event) error {
+	if h.fn == nil {
+		return nil
+	}
+	if err := h.fn(ev); err != nil {
+		h.err = err
+		return err
+	}
+	return nil
+}
+
+// emit is the event-based counterpart of do()
+func (pctx *parseContext) emit(h *eventHandler) error {
+	pctx.stripWhitespace()
+
+	mode := pctx.mode
+	if mode == parseModeDefault {
+		mode = parseModeList
+		if pctx.isDictionary() {
+			mode = parseModeDictionary
+		}
+	}
+
+	switch mode {
+	case parseModeDictionary:
+		if err := pctx.emitDictionary(h); err != nil {
+			return fmt.Errorf("sfv: failed to parse dictionary: %w", err)
+		}
+	case parseModeList:
+		if err := pctx.emitList(h); err != nil {
+			return fmt.Errorf("sfv: failed to parse list: %w", err)
+		}
+	case parseModeItem:
+		if err := pctx.emitItem(h); err != nil {
+			return fmt.Errorf("sfv: failed to parse item: %w", err)
+		}
+	}
+
+	pctx.stripWhitespace()
+	if !pctx.eof() {
+		return fmt.Errorf("sfv: unexpected trailing characters")
+	}
+	return nil
+}
+
+func (pctx *parseContext) emitList(h *eventHandler) error {
+	if err := h.handle(Event{Kind: StartListEvent}); err != nil {
+		return err
+	}
+
+	for !pctx.eof() {
+		if err := pctx.emitMember(h); err != nil {
+			return fmt.Errorf("sfv: parse list: %w", err)
+		}
+		if done, err := pctx.consumeMemberSeparator(); err != nil {
+			return fmt.Errorf("sfv: parse list: %w", err)
+		} else if done {
+			break
+		}
+	}
+
+	return h.handle(Event{Kind: EndEvent})
+}
+
+func (pctx *parseContext) emitDictionary(h *eventHandler) error {
+	if err := h.handle(Event{Kind: StartDictionaryEvent}); err != nil {
+		return err
+	}
+
+	for !pctx.eof() {
+		key, err := pctx.parseKey()
+		if err != nil {
+			return fmt.Errorf("sfv: parse dictionary: %w", err)
+		}
+		if err := h.handle(Event{Kind: KeyEvent, Key: key}); err != nil {
+			return err
+		}
+
+		if !pctx.eof() && pctx.current() == tokens.Equals {
+			pctx.advance() // consume '='
+			if err := pctx.emitMember(h); err != nil {
+				return fmt.Errorf("sfv: parse dictionary value: %w", err)
+			}
+		} else {
+			if err := h.handle(Event{Kind: BareItemEvent, Value: True()}); err != nil {
+				return err
+			}
+			if err := pctx.emitParameters(h); err != nil {
+				return fmt.Errorf("sfv: parse dictionary parameters: %w", err)
+			}
+		}
+
+		if done, err := pctx.consumeMemberSeparator(); err != nil {
+			return fmt.Errorf("sfv: parse dictionary: %w", err)
+		} else if done {
+			break
+		}
+	}
+
+	return h.handle(Event{Kind: EndEvent})
+}
+
+// consumeMemberSeparator consumes the optional whitespace and comma
+// between two members of a List or a Dictionary. done is true when the
+// end of input has been reached instead.
+func (pctx *parseContext) consumeMemberSeparator() (bool, error) {
+	pctx.stripWhitespace()
+	if pctx.eof() {
+		return true, nil
+	}
+
+	if pctx.current() != tokens.Comma {
+		return false, fmt.Errorf("expected comma, got '%c'", pctx.current())
+	}
+	pctx.advance() // consume comma
+
+	pctx.stripWhitespace()
+	if pctx.eof() {
+		return false, fmt.Errorf("trailing comma")
+	}
+	return false, nil
+}
+
+// emitMember reports an Item or an Inner List
+func (pctx *parseContext) emitMember(h *eventHandler) error {
+	if pctx.current() == tokens.OpenParen {
+		return pctx.emitInnerList(h)
+	}
+	return pctx.emitItem(h)
+}
+
+func (pctx *parseContext) emitInnerList(h *eventHandler) error {
+	pctx.stripWhitespace()
+	if pctx.current() != tokens.OpenParen {
+		return fmt.Errorf(`sfv: parse inner list: expected '%c', got '%c'`, tokens.OpenParen, pctx.current())
+	}
+	pctx.advance() // consume opening parenthesis
+
+	if err := h.handle(Event{Kind: StartInnerListEvent}); err != nil {
+		return err
+	}
+
+	for !pctx.eof() {
+		pctx.stripWhitespace()
+		if pctx.current() == tokens.CloseParen {
+			pctx.advance()
+			if err := h.handle(Event{Kind: EndEvent}); err != nil {
+				return err
+			}
+			if err := pctx.emitParameters(h); err != nil {
+				return fmt.Errorf("sfv: parse inner list: %w", err)
+			}
+			return nil
+		}
+
+		if err := pctx.emitItem(h); err != nil {
+			return fmt.Errorf("sfv: parse inner list: %w", err)
+		}
+
+		if !pctx.eof() {
+			if c := pctx.current(); !unicode.IsSpace(rune(c)) && c != tokens.CloseParen {
+				return fmt.Errorf("sfv: parse inner list: expected space or '%c' after item, got '%c'", tokens.CloseParen, c)
+			}
+		}
+	}
+	return fmt.Errorf("sfv: parse inner list: unexpected end of input, expected closing paren")
+}
+
+func (pctx *parseContext) emitItem(h *eventHandler) error {
+	bareItem, err := pctx.parseBareItem()
+	if err != nil {
+		return fmt.Errorf("sfv: failed to parse bare item: %w", err)
+	}
+	if err := h.handle(Event{Kind: BareItemEvent, Value: bareItem}); err != nil {
+		return err
+	}
+	if err := pctx.emitParameters(h); err != nil {
+		return fmt.Errorf("sfv: failed to parse parameters: %w", err)
+	}
+	return nil
+}
+
+// emitParameters reports parameters in the order they appear in the input
+func (pctx *parseContext) emitParameters(h *eventHandler) error {
+	for !pctx.eof() && pctx.current() == tokens.Semicolon {
+		pctx.advance()
+		pctx.stripWhitespace()
+
+		key, err := pctx.parseKey()
+		if err != nil {
+			return fmt.Errorf("sfv: failed to parse parameter key: %w", err)
+		}
+
+		var value BareItem = True()
+		if !pctx.eof() && pctx.current() == tokens.Equals {
+			pctx.advance()
+			value, err = pctx.parseBareItem()
+			if err != nil {
+				return fmt.Errorf("sfv: failed to parse parameter value: %w", err)
+			}
+		}
+
+		if err := h.handle(Event{Kind: ParamEvent, Key: key, Value: value}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
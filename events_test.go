@@ -0,0 +1,217 @@
+package sfv_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+// describeEvent renders an event in a compact form for comparison
+func describeEvent(t *testing.T, ev sfv.Event) string {
+	t.Helper()
+	switch ev.Kind {
+	case sfv.StartListEvent:
+		return "StartList"
+	case sfv.StartDictionaryEvent:
+		return "StartDictionary"
+	case sfv.StartInnerListEvent:
+		return "StartInnerList"
+	case sfv.EndEvent:
+		return "End"
+	case sfv.KeyEvent:
+		return "Key(" + ev.Key + ")"
+	case sfv.BareItemEvent:
+		v, err := ev.Value.MarshalSFV()
+		require.NoError(t, err)
+		return "BareItem(" + string(v) + ")"
+	case sfv.ParamEvent:
+		v, err := ev.Value.MarshalSFV()
+		require.NoError(t, err)
+		return "Param(" + ev.Key + "=" + string(v) + ")"
+	default:
+		return fmt.Sprintf("Unknown(%d)", ev.Kind)
+	}
+}
+
+func TestParseEvents(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		ft       sfv.FieldType
+		expected []string
+	}{
+		{
+			name:     "list",
+			input:    `sugar, ("a" "b";x);lvl=5, rum;q=0.5`,
+			ft:       sfv.ListField,
+			expected: []string{"StartList", "BareItem(sugar)", "StartInnerList", `BareItem("a")`, `BareItem("b")`, "Param(x=?1)", "End", "Param(lvl=5)", "BareItem(rum)", "Param(q=0.5)", "End"},
+		},
+		{
+			name:     "dictionary",
+			input:    `a=1, b;x, c=(1 2)`,
+			ft:       sfv.DictionaryField,
+			expected: []string{"StartDictionary", "Key(a)", "BareItem(1)", "Key(b)", "BareItem(?1)", "Param(x=?1)", "Key(c)", "StartInnerList", "BareItem(1)", "BareItem(2)", "End", "End"},
+		},
+		{
+			name:     "item",
+			input:    `"foo";a=1;b`,
+			ft:       sfv.ItemField,
+			expected: []string{`BareItem("foo")`, "Param(a=1)", "Param(b=?1)"},
+		},
+		{
+			name:     "guessed dictionary",
+			input:    `a=1`,
+			ft:       sfv.UnknownField,
+			expected: []string{"StartDictionary", "Key(a)", "BareItem(1)", "End"},
+		},
+		{
+			name:     "empty list",
+			input:    ``,
+			ft:       sfv.ListField,
+			expected: []string{"StartList", "End"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var actual []string
+			err := sfv.ParseEvents([]byte(test.input), test.ft, func(ev sfv.Event) error {
+				actual = append(actual, describeEvent(t, ev))
+				return nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, test.expected, actual, strings.Join(actual, ", "))
+		})
+	}
+}
+
+func TestParseEventsErrors(t *testing.T) {
+	t.Run("parse error", func(t *testing.T) {
+		for _, input := range []string{"a,", "(1 2", "a=1 b", `"foo`, "1 2"} {
+			err := sfv.ParseEvents([]byte(input), sfv.UnknownField, func(sfv.Event) error { return nil })
+			require.Error(t, err, "ParseEvents(%q) should fail", input)
+		}
+	})
+	t.Run("stop", func(t *testing.T) {
+		var count int
+		err := sfv.ParseEvents([]byte(`a, b, c, !!!`), sfv.ListField, func(ev sfv.Event) error {
+			count++
+			if ev.Kind == sfv.BareItemEvent {
+				return sfv.ErrStop
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+	})
+	t.Run("callback error", func(t *testing.T) {
+		myErr := errors.New("my error")
+		err := sfv.ParseEvents([]byte(`a, b`), sfv.ListField, func(sfv.Event) error { return myErr })
+		require.ErrorIs(t, err, myErr)
+	})
+	t.Run("invalid field type", func(t *testing.T) {
+		err := sfv.ParseEvents([]byte(`a`), sfv.FieldType(42), func(sfv.Event) error { return nil })
+		require.Error(t, err)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	valid := []struct {
+		input string
+		ft    sfv.FieldType
+	}{
+		{`a, (b c);d=1, "e"`, sfv.ListField},
+		{`a=1, b, c=(1 2);x`, sfv.DictionaryField},
+		{`:AQID:;ttl=10`, sfv.ItemField},
+		{`a=1`, sfv.UnknownField},
+		{``, sfv.ListField},
+	}
+	for _, test := range valid {
+		require.NoError(t, sfv.Validate([]byte(test.input), test.ft), "Validate(%q) should succeed", test.input)
+	}
+
+	invalid := []struct {
+		input string
+		ft    sfv.FieldType
+	}{
+		{`a,`, sfv.ListField},
+		{`(1 2`, sfv.ListField},
+		{`a=1 b`, sfv.DictionaryField},
+		{`"foo`, sfv.ItemField},
+		{`1, 2`, sfv.ItemField},
+		{`A=1`, sfv.DictionaryField},
+	}
+	for _, test := range invalid {
+		require.Error(t, sfv.Validate([]byte(test.input), test.ft), "Validate(%q) should fail", test.input)
+	}
+
+	require.Error(t, sfv.Validate([]byte(`a`), sfv.FieldType(42)))
+
+	// errors carry the same location as those of the parsing functions
+	input := []byte(`a=1, b=?2`)
+	err := sfv.Validate(input, sfv.DictionaryField)
+	var perr *sfv.ParseError
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, 8, perr.Offset)
+	_, parseErr := sfv.ParseDictionary(input)
+	require.Equal(t, sfv.FormatParseError(input, parseErr), sfv.FormatParseError(input, err))
+	require.Contains(t, sfv.FormatParseError(input, err), "^")
+
+	err = sfv.ParseEvents(input, sfv.DictionaryField, func(sfv.Event) error { return nil })
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, 8, perr.Offset)
+}
+
+// FuzzValidateAgreesWithParse checks that Validate, which runs on the
+// event-based parser, accepts exactly the inputs that the tree-building
+// parser accepts, for every field type
+func FuzzValidateAgreesWithParse(f *testing.F) {
+	for _, seed := range []string{
+		``,
+		`a, b, c`,
+		`a=1, b, c=?0;x`,
+		`sig1=("@method" "@path");created=1618884473;keyid="k"`,
+		`(a b);q=1, (), ( a  b )`,
+		`1.5;a;b=2, -3, :AQID:, @1659578233, %"f%c3%bc"`,
+		`"a\"b\\c", "unterminated`,
+		`a,`,
+		`a, , b`,
+		`(a b`,
+		`(a b)c`,
+		`a=`,
+		`A=1`,
+		`a;`,
+		`a;B=1`,
+		`a=(1 2);x, b=?1`,
+		"a,\tb",
+		`  a  ,  b  `,
+		`1234567890123456`,
+		`1.2345`,
+		`*foo, foo/bar:baz`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		parsers := []struct {
+			ft    sfv.FieldType
+			parse func([]byte) error
+		}{
+			{sfv.UnknownField, func(b []byte) error { _, err := sfv.Parse(b); return err }},
+			{sfv.ListField, func(b []byte) error { var l sfv.List; return l.UnmarshalText(b) }},
+			{sfv.DictionaryField, func(b []byte) error { _, err := sfv.ParseDictionary(b); return err }},
+			{sfv.ItemField, func(b []byte) error { _, err := sfv.ParseItem(b); return err }},
+		}
+		for _, p := range parsers {
+			parseErr := p.parse(data)
+			validateErr := sfv.Validate(data, p.ft)
+			if (parseErr == nil) != (validateErr == nil) {
+				t.Fatalf("%s field %q: parse error %v, validate error %v", p.ft, data, parseErr, validateErr)
+			}
+		}
+	})
+}
@@ -88,6 +88,6 @@ func Example() {
 	// Parsed list: "text", 42, ?1, @1659578233
 	// Parsed dictionary: key1="value1", key2=42, flag
 	// Serialized dictionary: name="John Doe", age=30, active, score=98.5, data=:aGVsbG8=:
-	// Item with parameters: "cached-resource"; max-age=3600; public
+	// Item with parameters: "cached-resource";max-age=3600;public
 	// Complex list: "item1", 42, ("inner1" "inner2")
 }
@@ -0,0 +1,92 @@
+package sfv
+
+import "github.com/lestrrat-go/sfv/internal/tokens"
+
+// parseTrivialItem attempts to parse data as one of the most common
+// shapes of a single item: a token, an integer, a string without escape
+// sequences, or a boolean, all without parameters or surrounding
+// whitespace. These make up the bulk of fields such as Priority or
+// Cache-Status members, and can be recognized in a single pass without
+// going through the general parser.
+//
+// The second return value is false if data is not one of these shapes,
+// in which case the caller must fall back to the general parser. The
+// fast path never reports an error: anything that is not obviously
+// valid is left for the general parser to diagnose.
+func parseTrivialItem(data []byte) (Item, bool) {
+	if len(data) == 0 {
+		return nil, false
+	}
+
+	switch c := data[0]; {
+	case isAlpha(c) || c == tokens.Asterisk:
+		for _, c := range data[1:] {
+			if !isTokenChar(c) {
+				return nil, false
+			}
+		}
+		return BareToken(string(data)).toItem(), true
+	case isDigit(c) || c == tokens.Dash:
+		digits := data
+		if c == tokens.Dash {
+			digits = data[1:]
+		}
+		if len(digits) == 0 || len(digits) > maxIntegerDigits {
+			return nil, false
+		}
+		var v int64
+		for _, c := range digits {
+			if !isDigit(c) {
+				return nil, false
+			}
+			v = v*10 + int64(c-'0')
+		}
+		if c == tokens.Dash {
+			v = -v
+		}
+		return BareInteger(v).toItem(), true
+	case c == tokens.DoubleQuote:
+		if len(data) < 2 || data[len(data)-1] != tokens.DoubleQuote {
+			return nil, false
+		}
+		content := data[1 : len(data)-1]
+		for _, c := range content {
+			if c == tokens.Backslash || c == tokens.DoubleQuote || c <= 0x1f || c >= 0x7f {
+				return nil, false
+			}
+		}
+		return BareString(string(content)).toItem(), true
+	case c == tokens.QuestionMark:
+		if len(data) != 2 {
+			return nil, false
+		}
+		switch data[1] {
+		case tokens.One:
+			return True().toItem(), true
+		case tokens.Zero:
+			return False().toItem(), true
+		}
+	}
+	return nil, false
+}
+
+// isTokenChar reports whether c is a tchar, or one of the additional
+// characters (":" and "/") allowed in the body of an sf-token
+func isTokenChar(c byte) bool {
+	if isAlpha(c) || isDigit(c) {
+		return true
+	}
+	switch c {
+	case tokens.Ampersand, tokens.Asterisk,
+		tokens.Backtick, tokens.Caret,
+		tokens.Colon, tokens.Dash,
+		tokens.Dollar, tokens.Exclamation,
+		tokens.Hash, tokens.Percent,
+		tokens.Period, tokens.Pipe,
+		tokens.Plus, tokens.SingleQuote,
+		tokens.Slash, tokens.Tilde,
+		tokens.Underscore:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package sfv_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreezeDictionary(t *testing.T) {
+	dict, err := sfv.ParseDictionaryString(`a=1;p=2, b=(x y);q, c`)
+	require.NoError(t, err)
+	dict.Freeze()
+	require.True(t, dict.Frozen())
+
+	require.ErrorIs(t, dict.Set("d", sfv.Integer(1)), sfv.ErrFrozen)
+	require.ErrorIs(t, dict.SetWithParams("d", 1, nil), sfv.ErrFrozen)
+	require.ErrorIs(t, dict.SetAll(map[string]any{"d": 1}), sfv.ErrFrozen)
+	require.ErrorIs(t, dict.Merge(sfv.NewDictionary()), sfv.ErrFrozen)
+	require.ErrorIs(t, dict.UnmarshalText([]byte(`z=1`)), sfv.ErrFrozen)
+
+	item, err := dict.GetItem("a")
+	require.NoError(t, err)
+	require.True(t, item.Frozen())
+	require.ErrorIs(t, item.Parameters().Set("p", 3), sfv.ErrFrozen)
+	// refused deletions are told apart from missing parameters
+	deleted, err := item.Parameters().Delete("p")
+	require.ErrorIs(t, err, sfv.ErrFrozen)
+	require.False(t, deleted)
+	deleted, err = item.Parameters().Delete("missing")
+	require.ErrorIs(t, err, sfv.ErrFrozen)
+	require.False(t, deleted)
+	require.ErrorIs(t, item.Parameters().SortKeys(), sfv.ErrFrozen)
+
+	il, err := dict.GetInnerList("b")
+	require.NoError(t, err)
+	require.True(t, il.Frozen())
+	require.ErrorIs(t, il.Add(sfv.Token("z")), sfv.ErrFrozen)
+	require.ErrorIs(t, il.Remove(0), sfv.ErrFrozen)
+	require.ErrorIs(t, il.Parameter("q", false), sfv.ErrFrozen)
+	require.ErrorIs(t, il.SetParameters(nil), sfv.ErrFrozen)
+	first, ok := il.Get(0)
+	require.True(t, ok)
+	require.True(t, first.Frozen())
+
+	require.Equal(t, `a=1;p=2, b=(x y);q, c`, marshalString(t, dict))
+
+	// copies can be modified
+	derived, err := dict.WithMember("d", sfv.Integer(4))
+	require.NoError(t, err)
+	require.False(t, derived.Frozen())
+	require.Equal(t, `a=1;p=2, b=(x y);q, c, d=4`, marshalString(t, derived))
+
+	clone := dict.Clone()
+	require.False(t, clone.Frozen())
+	require.NoError(t, clone.Set("e", sfv.Integer(5)))
+	cloned, err := clone.GetItem("a")
+	require.NoError(t, err)
+	require.NoError(t, cloned.Parameters().Set("p", 3))
+
+	withParam, err := item.WithParameter("p", 3)
+	require.NoError(t, err)
+	require.Equal(t, `1;p=3`, marshalString(t, withParam))
+}
+
+func TestFreezeList(t *testing.T) {
+	list := parseList(t, `a, (b c)`)
+	list.Freeze()
+	require.True(t, list.Frozen())
+
+	require.ErrorIs(t, list.Add(sfv.Token("z")), sfv.ErrFrozen)
+	require.ErrorIs(t, list.Append("z"), sfv.ErrFrozen)
+	require.ErrorIs(t, list.Set(0, sfv.Token("z")), sfv.ErrFrozen)
+	require.ErrorIs(t, list.Insert(0, sfv.Token("z")), sfv.ErrFrozen)
+	require.ErrorIs(t, list.Remove(0), sfv.ErrFrozen)
+	require.ErrorIs(t, list.UnmarshalText([]byte(`z`)), sfv.ErrFrozen)
+	require.Equal(t, `a, (b c)`, marshalString(t, list))
+
+	item := sfv.Token("x")
+	list2, err := sfv.NewList(item)
+	require.NoError(t, err)
+	list2.Freeze()
+	require.True(t, item.Frozen())
+	require.ErrorIs(t, item.Parameter("p", 1), sfv.ErrFrozen)
+	require.ErrorIs(t, item.UnmarshalText([]byte(`y`)), sfv.ErrFrozen)
+
+	var nilList *sfv.List
+	nilList.Freeze()
+	require.False(t, nilList.Frozen())
+}
+
+func TestFrozenDictionaryKeys(t *testing.T) {
+	dict, err := sfv.ParseDictionaryString(`a=1, b=2`)
+	require.NoError(t, err)
+	dict.Freeze()
+
+	keys := dict.Keys()
+	keys[0] = "zzz"
+	require.Equal(t, []string{"a", "b"}, dict.Keys(), "modifying the keys should not affect the dictionary")
+	require.Equal(t, `a=1, b=2`, marshalString(t, dict))
+}
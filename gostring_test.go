@@ -0,0 +1,33 @@
+package sfv_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoString(t *testing.T) {
+	testcases := []struct {
+		input    string
+		expected string
+	}{
+		{`foo`, `&sfv.List{sfv.Token("foo")}`},
+		{`1;a=2;b, 1.5, "s";x=?0`, `&sfv.List{sfv.Integer(1).With(&sfv.Parameters{"a": sfv.BareInteger(2), "b": sfv.BareBoolean(true)}), sfv.Decimal(1.5), sfv.String("s").With(&sfv.Parameters{"x": sfv.BareBoolean(false)})}`},
+		{`:aGk=:, @1659578233, %"caf%c3%a9"`, `&sfv.List{sfv.ByteSequence([]byte("hi")), sfv.Date(1659578233), sfv.DisplayString("café")}`},
+		{`(a 1);p=tok, ()`, `&sfv.List{&sfv.InnerList{sfv.Token("a"), sfv.Integer(1)}.With(&sfv.Parameters{"p": sfv.BareToken("tok")}), &sfv.InnerList{}}`},
+	}
+	for _, tc := range testcases {
+		v, err := sfv.ParseString(tc.input)
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, fmt.Sprintf("%#v", v), "input %q", tc.input)
+	}
+
+	dict, err := sfv.ParseDictionaryString(`b=1, a=(x), c`)
+	require.NoError(t, err)
+	require.Equal(t, `&sfv.Dictionary{"b": sfv.Integer(1), "a": &sfv.InnerList{sfv.Token("x")}, "c": sfv.BareBoolean(true)}`, fmt.Sprintf("%#v", dict))
+
+	require.Equal(t, `sfv.BareToken("foo")`, fmt.Sprintf("%#v", sfv.BareToken("foo")))
+	require.Equal(t, `(*sfv.List)(nil)`, fmt.Sprintf("%#v", (*sfv.List)(nil)))
+}
@@ -0,0 +1,82 @@
+package sfv
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// SetHeader marshals v and sets the result as the value of the field
+// called name in h, replacing any existing values. The name is
+// canonicalized by http.Header.Set. The same options as Marshal can be
+// used to customize the output, and WithMaxFieldLength can be used to
+// split long values over multiple field lines.
+//
+// RFC 9651 specifies that empty Lists and Dictionaries are not
+// serialized at all, so if v marshals to an empty value, the field is
+// removed from h instead, unless WithEmptyFieldError is used, in which
+// case SetHeader fails with ErrEmptyField. h is not modified if v cannot
+// be marshaled.
+func SetHeader(h http.Header, name string, v any, options ...EncodeOption) error {
+	values, err := marshalHeader(name, v, options)
+	if err != nil {
+		return err
+	}
+	h.Del(name)
+	for _, value := range values {
+		h.Add(name, value)
+	}
+	return nil
+}
+
+// AddHeader is the same as SetHeader, but appends the result to any
+// existing values of the field instead of replacing them. Recipients
+// combine multiple field lines into a single List or Dictionary, as
+// described in RFC 9651 Section 4.2. Nothing is added if v marshals to
+// an empty value.
+func AddHeader(h http.Header, name string, v any, options ...EncodeOption) error {
+	values, err := marshalHeader(name, v, options)
+	if err != nil {
+		return err
+	}
+	for _, value := range values {
+		h.Add(name, value)
+	}
+	return nil
+}
+
+// marshalHeader returns the field values to add to a header, which is
+// empty if v marshals to an empty value
+func marshalHeader(name string, v any, options []EncodeOption) ([]string, error) {
+	if !isValidFieldName(name) {
+		return nil, fmt.Errorf("invalid field name %q", name)
+	}
+
+	cfg := newEncodeConfig(options)
+	if cfg.maxFieldLength > 0 {
+		chunks, err := cfg.marshalSplit(v, cfg.maxFieldLength)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode field %q: %w", name, err)
+		}
+		if len(chunks) == 0 && cfg.emptyFieldError {
+			return nil, fmt.Errorf("failed to encode field %q: %w", name, ErrEmptyField)
+		}
+		values := make([]string, len(chunks))
+		for i, chunk := range chunks {
+			values[i] = string(chunk)
+		}
+		return values, nil
+	}
+
+	buf := getBuffer()
+	defer releaseBuffer(buf)
+
+	var err error
+	*buf, err = cfg.marshalField(*buf, v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode field %q: %w", name, err)
+	}
+	if len(*buf) == 0 {
+		return nil, nil
+	}
+	return []string{string(*buf)}, nil
+}
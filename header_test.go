@@ -0,0 +1,48 @@
+package sfv_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetHeader(t *testing.T) {
+	h := http.Header{}
+	require.NoError(t, sfv.SetHeader(h, "cache-status", []any{sfv.Token("ExampleCache"), sfv.Token("OriginCache")}))
+	require.Equal(t, []string{"ExampleCache, OriginCache"}, h.Values("Cache-Status"))
+	require.Contains(t, h, "Cache-Status", "the name should be canonicalized")
+
+	require.NoError(t, sfv.SetHeader(h, "Cache-Status", sfv.Token("Other")))
+	require.Equal(t, []string{"Other"}, h.Values("Cache-Status"), "existing values should be replaced")
+
+	require.NoError(t, sfv.SetHeader(h, "Example", map[string]int{"b": 2, "a": 1}, sfv.WithParameterSpacing(" ")))
+	require.Equal(t, "a=1, b=2", h.Get("Example"))
+
+	require.NoError(t, sfv.SetHeader(h, "Example", sfv.NewDictionary()))
+	require.NotContains(t, h, "Example", "empty values should remove the field")
+
+	require.Error(t, sfv.SetHeader(h, "Bad Name", 1))
+	require.Error(t, sfv.SetHeader(h, "Cache-Status", nil))
+	require.Error(t, sfv.SetHeader(h, "Cache-Status", sfv.String("\n")))
+	require.Equal(t, []string{"Other"}, h.Values("Cache-Status"), "failures should not modify the header")
+}
+
+func TestAddHeader(t *testing.T) {
+	h := http.Header{}
+	require.NoError(t, sfv.AddHeader(h, "Example", sfv.Integer(1)))
+	require.NoError(t, sfv.AddHeader(h, "example", sfv.Integer(2)))
+	require.NoError(t, sfv.AddHeader(h, "Example", &sfv.List{}))
+	require.Equal(t, []string{"1", "2"}, h.Values("Example"))
+
+	require.Error(t, sfv.AddHeader(h, "", 1))
+}
+
+func TestSetHeaderEmptyField(t *testing.T) {
+	h := http.Header{}
+	h.Set("Accept", "text/html")
+	err := sfv.SetHeader(h, "Accept", []string{}, sfv.WithEmptyFieldError(true))
+	require.ErrorIs(t, err, sfv.ErrEmptyField)
+	require.Equal(t, "text/html", h.Get("Accept"), "header is not modified on error")
+}
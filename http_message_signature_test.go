@@ -41,17 +41,22 @@ func TestHTTPMessageSignatureComponentIdentifiers(t *testing.T) {
 
 			// Test marshaling back with HTTP Message Signature formatting (no spaces)
 			var buf bytes.Buffer
-			encoder := sfv.NewEncoder(&buf)
-			encoder.SetParameterSpacing("") // HTTP Message Signature format
+			encoder := sfv.NewEncoder(&buf, sfv.WithParameterSpacing("")) // HTTP Message Signature format
 			require.NoError(t, encoder.Encode(parsed), "HTTP Message Signature encoder failed for input: %s", tt.input)
 
 			// Should match expected format (RFC 9421 style without spaces)
 			require.Equal(t, tt.expected, buf.String(), "Marshal result should match expected format")
 
-			// Also test that standard marshaling produces spaces (to verify the difference)
+			// RFC 9651 serialization does not use spaces either, so the
+			// default output should be the same
 			standardMarshaled, err := sfv.Marshal(parsed)
 			require.NoError(t, err, "Standard Marshal failed for input: %s", tt.input)
-			require.Contains(t, string(standardMarshaled), "; ", "Standard marshal should contain spaces after semicolons")
+			require.Equal(t, tt.expected, string(standardMarshaled), "Standard marshal should not contain spaces after semicolons")
+
+			// Spaces can still be requested explicitly
+			spacedMarshaled, err := sfv.Marshal(parsed, sfv.WithParameterSpacing(" "))
+			require.NoError(t, err, "Spaced Marshal failed for input: %s", tt.input)
+			require.Contains(t, string(spacedMarshaled), "; ", "Spaced marshal should contain spaces after semicolons")
 		})
 	}
 }
@@ -68,8 +73,7 @@ func TestInnerListWithHTTPMessageSignatureComponents(t *testing.T) {
 
 	// Test marshaling back with HTTP Message Signature formatting (no spaces)
 	var buf bytes.Buffer
-	encoder := sfv.NewEncoder(&buf)
-	encoder.SetParameterSpacing("") // HTTP Message Signature format
+	encoder := sfv.NewEncoder(&buf, sfv.WithParameterSpacing("")) // HTTP Message Signature format
 	require.NoError(t, encoder.Encode(parsed), "HTTP Message Signature encoder failed for input: %s", input)
 
 	// Should match expected format (RFC 9421 style without spaces)
@@ -134,7 +138,7 @@ func TestComponentIdentifierStructure(t *testing.T) {
 			require.NotNil(t, params, "Should have parameters")
 
 			for expectedKey, expectedValue := range tt.expectedParams {
-				paramValue, exists := params.Values[expectedKey]
+				paramValue, exists := params.Lookup(expectedKey)
 				require.True(t, exists, "Should have parameter %q", expectedKey)
 
 				switch expected := expectedValue.(type) {
@@ -0,0 +1,70 @@
+package sfv
+
+import "sync"
+
+const (
+	defaultInternMaxLength  = 64
+	defaultInternMaxEntries = 4096
+)
+
+// InternTable is a table of strings that can be shared between parses.
+// Fields such as Signature-Input repeat the same small keys and tokens
+// (e.g. "keyid", "created", "req") over and over again. When an
+// InternTable is passed to the parser via WithInternTable, keys and
+// tokens are looked up in the table, and repeated occurrences share
+// the same backing storage instead of allocating a new string each time.
+//
+// Only short strings are interned, and the table stops accepting new
+// entries once it is full, so that hostile input cannot make it grow
+// without bounds.
+//
+// An InternTable is safe for concurrent use by multiple goroutines.
+type InternTable struct {
+	mu         sync.RWMutex
+	strings    map[string]string
+	maxLength  int
+	maxEntries int
+}
+
+// NewInternTable creates a new, empty InternTable.
+func NewInternTable() *InternTable {
+	return &InternTable{
+		strings:    make(map[string]string),
+		maxLength:  defaultInternMaxLength,
+		maxEntries: defaultInternMaxEntries,
+	}
+}
+
+// Intern returns a string with the same contents as b. If the same
+// contents have been interned before, the previously stored string
+// is returned.
+func (t *InternTable) Intern(b []byte) string {
+	if len(b) > t.maxLength {
+		return string(b)
+	}
+
+	t.mu.RLock()
+	s, ok := t.strings[string(b)]
+	t.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if s, ok := t.strings[string(b)]; ok {
+		return s
+	}
+	s = string(b)
+	if len(t.strings) < t.maxEntries {
+		t.strings[s] = s
+	}
+	return s
+}
+
+// Len returns the number of strings stored in the table.
+func (t *InternTable) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.strings)
+}
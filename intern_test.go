@@ -0,0 +1,50 @@
+package sfv_test
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInternTable(t *testing.T) {
+	t.Run("shared storage across parses", func(t *testing.T) {
+		table := sfv.NewInternTable()
+		input := []byte(`sig1=("@method";req);keyid="a";alg=rsa, sig2=("@path";req);keyid="b";alg=rsa`)
+
+		first, err := sfv.ParseDictionary(input, sfv.WithInternTable(table))
+		require.NoError(t, err)
+		second, err := sfv.ParseDictionary(input, sfv.WithInternTable(table))
+		require.NoError(t, err)
+
+		require.Equal(t, first.Keys(), second.Keys())
+		for i, key := range first.Keys() {
+			require.Equal(t, unsafe.StringData(key), unsafe.StringData(second.Keys()[i]), "key %q should be interned", key)
+		}
+
+		var il *sfv.InnerList
+		require.NoError(t, first.GetValue("sig1", &il))
+		var alg sfv.BareItem
+		require.NoError(t, il.Parameters().Get("alg", &alg))
+		var tok1 string
+		require.NoError(t, alg.GetValue(&tok1))
+
+		require.NoError(t, second.GetValue("sig2", &il))
+		require.NoError(t, il.Parameters().Get("alg", &alg))
+		var tok2 string
+		require.NoError(t, alg.GetValue(&tok2))
+
+		require.Equal(t, "rsa", tok1)
+		require.Equal(t, unsafe.StringData(tok1), unsafe.StringData(tok2), "token should be interned")
+	})
+	t.Run("long strings are not stored", func(t *testing.T) {
+		table := sfv.NewInternTable()
+		long := strings.Repeat("a", 100)
+		require.Equal(t, long, table.Intern([]byte(long)))
+		require.Equal(t, 0, table.Len())
+		require.Equal(t, "abc", table.Intern([]byte("abc")))
+		require.Equal(t, 1, table.Len())
+	})
+}
@@ -29,4 +29,8 @@ const (
 	Semicolon    = ';'
 	Zero         = '0'
 	One          = '1'
+	Space        = ' '
+	Tab          = '\t'
+	CR           = '\r'
+	LF           = '\n'
 )
@@ -1,7 +1,10 @@
 package sfv
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/lestrrat-go/blackmagic"
 )
@@ -36,9 +39,9 @@ func bareItemFrom(value any, stringMode int) (BareItem, error) {
 	case bool:
 		return BareBoolean(v), nil
 	case int:
-		return BareInteger(int64(v)), nil
+		return newCheckedInteger(int64(v))
 	case int64:
-		return BareInteger(v), nil
+		return newCheckedInteger(v)
 	case float64:
 		return BareDecimal(v), nil
 	case float32:
@@ -48,6 +51,16 @@ func bareItemFrom(value any, stringMode int) (BareItem, error) {
 	}
 }
 
+// newCheckedInteger creates an IntegerBareItem, failing if v is out of
+// the range allowed for Integers
+func newCheckedInteger(v int64) (*IntegerBareItem, error) {
+	var bi IntegerBareItem
+	if err := bi.SetValue(v); err != nil {
+		return nil, err
+	}
+	return &bi, nil
+}
+
 // This is the actual value, and we're only providing this to avoid
 // having to write a lot of boilerplate code for each type.
 type uvalue[T any] struct {
@@ -67,46 +80,150 @@ func (iv uvalue[T]) GetValue(dst any) error {
 	return blackmagic.AssignIfCompatible(dst, iv.value)
 }
 
+// AsString and the other As* methods of uvalue report that the value
+// is not of the requested type. Each bare item type overrides the
+// methods that apply to it.
+func (iv uvalue[T]) AsString() (string, bool) { return "", false }
+func (iv uvalue[T]) AsInt64() (int64, bool)   { return 0, false }
+func (iv uvalue[T]) AsBytes() ([]byte, bool)  { return nil, false }
+func (iv uvalue[T]) AsBool() (bool, bool)     { return false, false }
+
 // FullItem is a generic container that combines a BareItem with Parameters
 // to create a complete SFV Item. It serves as the base implementation for
 // all typed item aliases (StringItem, IntegerItem, etc.) in the SFV format.
 type FullItem[BT BareItem, UT any] struct {
-	bare    BT
-	valuefn func() UT
-	params  *Parameters
+	bare   BT
+	params *Parameters
+	raw    []byte
+	span   *Span
+	frozen bool
+}
+
+func (fi *FullItem[BT, UT]) setRaw(raw []byte) {
+	fi.raw = raw
+}
+
+func (fi *FullItem[BT, UT]) setSpan(span Span) {
+	fi.span = &span
+}
+
+// Span returns the location in the input this item was parsed from,
+// including its parameters. The second return value is false unless the
+// item was parsed with the WithSpans option enabled.
+func (fi *FullItem[BT, UT]) Span() (Span, bool) {
+	if fi.span == nil {
+		return Span{}, false
+	}
+	return *fi.span, true
+}
+
+// Raw returns the exact input text this item was parsed from, including
+// its parameters. It returns nil unless the item was parsed with the
+// WithRawText option enabled. The returned slice must not be modified.
+func (fi *FullItem[BT, UT]) Raw() []byte {
+	return fi.raw
 }
 
 func (fi *FullItem[BT, UT]) Parameters() *Parameters {
 	return fi.params
 }
 
+// Value returns the value of the item
 func (fi *FullItem[BT, UT]) Value() UT {
-	return fi.valuefn()
+	var v UT
+	_ = fi.bare.GetValue(&v)
+	return v
 }
 
 func (fi *FullItem[BT, UT]) MarshalSFV() ([]byte, error) {
-	bi, err := fi.bare.MarshalSFV()
+	return fi.appendSFV(nil, &defaultEncodeConfig)
+}
+
+// AppendSFV appends the serialization of the item to dst.
+func (fi *FullItem[BT, UT]) AppendSFV(dst []byte) ([]byte, error) {
+	return fi.appendSFV(dst, &defaultEncodeConfig)
+}
+
+// MarshalText implements encoding.TextMarshaler, and returns the same
+// serialization as MarshalSFV.
+func (fi *FullItem[BT, UT]) MarshalText() ([]byte, error) {
+	return fi.MarshalSFV()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It parses text as an
+// Item, which must be of the same type as fi, and replaces the contents of
+// fi with it. For example, unmarshaling "5;a" into an *IntegerItem works,
+// but unmarshaling "foo" into it fails.
+func (fi *FullItem[BT, UT]) UnmarshalText(text []byte) error {
+	if fi.frozen {
+		return ErrFrozen
+	}
+	item, err := ParseItem(text)
+	if err != nil {
+		return err
+	}
+	parsed, ok := item.(*FullItem[BT, UT])
+	if !ok {
+		return fmt.Errorf("sfv: cannot unmarshal item of type %s into %T", item.Type(), fi)
+	}
+	*fi = *parsed
+	return nil
+}
+
+// WriteSFV writes the serialization of the item to w, and returns the
+// number of bytes written.
+func (fi *FullItem[BT, UT]) WriteSFV(w io.Writer) (int, error) {
+	return writeSFV(w, fi)
+}
+
+func (fi *FullItem[BT, UT]) appendSFV(dst []byte, cfg *encodeConfig) ([]byte, error) {
+	dst, err := cfg.appendBareItem(dst, fi.bare)
 	if err != nil {
 		return nil, fmt.Errorf("error marshaling bare item: %w", err)
 	}
 
 	// Add parameters if any
 	if fi.params != nil && fi.params.Len() > 0 {
-		paramBytes, err := fi.params.MarshalSFV()
+		dst, err = fi.params.appendSFV(dst, cfg)
 		if err != nil {
 			return nil, err
 		}
-		bi = append(bi, paramBytes...)
 	}
 
-	return bi, nil
+	return dst, nil
+}
+
+// GoString returns Go-like notation for the item, such as
+// sfv.Token("foo").With(&sfv.Parameters{"a": sfv.BareInteger(1)}), which
+// is used by the %#v verb. It is meant to make test failures readable,
+// and is not guaranteed to be valid Go code.
+func (fi *FullItem[BT, UT]) GoString() string {
+	if fi == nil {
+		return fmt.Sprintf("(%T)(nil)", fi)
+	}
+	var sb strings.Builder
+	// the item constructors are named after the bare item constructors,
+	// without the "Bare" prefix
+	sb.WriteString("sfv.")
+	sb.WriteString(strings.TrimPrefix(fmt.Sprintf("%#v", fi.bare), "sfv.Bare"))
+	if fi.params.Len() > 0 {
+		sb.WriteString(".With(")
+		sb.WriteString(fi.params.GoString())
+		sb.WriteByte(')')
+	}
+	return sb.String()
 }
 
 func (fi *FullItem[BT, UT]) GetValue(dst any) error {
 	return fi.bare.GetValue(dst)
 }
 
-func (fi *FullItem[BT, UT]) Type() int {
+func (fi *FullItem[BT, UT]) AsString() (string, bool) { return fi.bare.AsString() }
+func (fi *FullItem[BT, UT]) AsInt64() (int64, bool)   { return fi.bare.AsInt64() }
+func (fi *FullItem[BT, UT]) AsBytes() ([]byte, bool)  { return fi.bare.AsBytes() }
+func (fi *FullItem[BT, UT]) AsBool() (bool, bool)     { return fi.bare.AsBool() }
+
+func (fi *FullItem[BT, UT]) Type() Type {
 	return fi.bare.Type()
 }
 
@@ -117,11 +234,22 @@ func (fi *FullItem[BT, UT]) Parameter(name string, value any) error {
 	}
 
 	if err := fi.params.Set(name, bi); err != nil {
-		return fmt.Errorf("failed to set parameter %s: %v", name, err)
+		return fmt.Errorf("failed to set parameter %s: %w", name, err)
 	}
 	return nil
 }
 
+// ParseInner parses the content of a String item as another structured
+// field of type ft. See StringBareItem.ParseInner for details. It is an
+// error to call this method on an item that is not a String.
+func (fi *FullItem[BT, UT]) ParseInner(ft FieldType, options ...ParseOption) (any, error) {
+	s, ok := any(fi.bare).(*StringBareItem)
+	if !ok {
+		return nil, fmt.Errorf("sfv: cannot parse inner value of non-string item (type %s)", fi.bare.Type())
+	}
+	return s.ParseInner(ft, options...)
+}
+
 func (fi *FullItem[BT, UT]) With(params *Parameters) Item {
 	return &FullItem[BT, UT]{
 		bare:   fi.bare,
@@ -129,11 +257,152 @@ func (fi *FullItem[BT, UT]) With(params *Parameters) Item {
 	}
 }
 
+// WithParameter returns a copy of the item, with the parameter key added
+// or replaced by value, which is converted in the same way as by
+// Parameters.Set. The item and its parameters are not modified, so that
+// items parsed once, such as templates, can be safely shared between
+// goroutines, and derived from as needed. The copy shares the value of
+// the item, and its other parameters are copied.
+func (fi *FullItem[BT, UT]) WithParameter(key string, value any) (Item, error) {
+	if !isValidKey(key) {
+		return nil, fmt.Errorf("invalid parameter key %q", key)
+	}
+	params := fi.params.Clone()
+	if params == nil {
+		params = NewParameters()
+	}
+	if err := params.Set(key, value); err != nil {
+		return nil, err
+	}
+	return &FullItem[BT, UT]{
+		bare:   fi.bare,
+		params: params,
+	}, nil
+}
+
+// Freeze makes the item and its parameters read-only. See Parameters.Freeze.
+func (fi *FullItem[BT, UT]) Freeze() {
+	fi.frozen = true
+	fi.params.Freeze()
+}
+
+// Frozen reports whether Freeze was called on the item.
+func (fi *FullItem[BT, UT]) Frozen() bool {
+	return fi.frozen
+}
+
+// Clone returns a deep copy of the item, including its parameters and,
+// for byte sequences, the underlying bytes. Modifying the copy does not
+// affect the original, and vice versa.
+func (fi *FullItem[BT, UT]) Clone() Item {
+	if fi == nil {
+		return nil
+	}
+	//nolint:forcetypeassert
+	c := &FullItem[BT, UT]{
+		bare:   cloneBareItem(fi.bare).(BT),
+		params: fi.params.Clone(),
+		raw:    fi.raw,
+	}
+	if fi.span != nil {
+		span := *fi.span
+		c.span = &span
+	}
+	return c
+}
+
+// cloneBareItem returns a deep copy of v. Bare items of types defined
+// outside of this package are returned as is.
+func cloneBareItem(v BareItem) BareItem {
+	switch v := v.(type) {
+	case *IntegerBareItem:
+		c := *v
+		return &c
+	case *DecimalBareItem:
+		c := *v
+		return &c
+	case *StringBareItem:
+		c := *v
+		return &c
+	case *TokenBareItem:
+		c := *v
+		return &c
+	case *DateBareItem:
+		c := *v
+		return &c
+	case *DisplayStringBareItem:
+		c := *v
+		return &c
+	case *ByteSequenceBareItem:
+		return BareByteSequence(bytes.Clone(v.bytes()))
+	default:
+		// BooleanBareItem is a value type, and needs no copying
+		return v
+	}
+}
+
+// ItemValue returns the value of item as a T. Strings, int64s, byte
+// slices, and bools are retrieved through the As* methods of the item,
+// without reflection. Other types, such as int, float64, or time.Time,
+// are assigned using GetValue, and support the same conversions. The
+// parameters of the item, if any, are ignored.
+func ItemValue[T any](item CoreItem) (T, error) {
+	var v T
+	if item == nil {
+		return v, fmt.Errorf("sfv: cannot get value of nil item")
+	}
+
+	var ok bool
+	switch p := any(&v).(type) {
+	case *string:
+		*p, ok = item.AsString()
+	case *int64:
+		*p, ok = item.AsInt64()
+	case *[]byte:
+		*p, ok = item.AsBytes()
+	case *bool:
+		*p, ok = item.AsBool()
+	}
+	if ok {
+		return v, nil
+	}
+
+	if err := item.GetValue(&v); err != nil {
+		var zero T
+		return zero, fmt.Errorf("sfv: cannot get value of %s item as %T: %w", item.Type(), zero, err)
+	}
+	return v, nil
+}
+
 // CoreItem represents the core API that is shared by both
 // Item and BareItem.
 type CoreItem interface {
 	Marshaler
-	Type() int
+	Appender
+	Type() Type
+
+	// IsInteger and the other Is* methods report whether the item is of
+	// the corresponding type, without having to compare the result of
+	// Type with a type constant.
+	IsInteger() bool
+	IsDecimal() bool
+	IsString() bool
+	IsToken() bool
+	IsByteSequence() bool
+	IsBoolean() bool
+	IsDate() bool
+	IsDisplayString() bool
+
+	// AsString returns the value of a String, Token, or Display String.
+	// AsInt64 returns the value of an Integer, AsBytes the decoded value
+	// of a Byte Sequence, and AsBool the value of a Boolean. The second
+	// return value is false if the item is of another type. Unlike
+	// GetValue, they do not go through reflection, and never fail.
+	AsString() (string, bool)
+	AsInt64() (int64, bool)
+	AsBytes() ([]byte, bool)
+	AsBool() (bool, bool)
+
 	// GetValue is a method that assigns the underlying value of the item to dst.
 	// It is used to retrieve the value without needing to know the type, or
 	// without having to go through type conversion.
@@ -160,4 +429,35 @@ type Item interface {
 
 	With(*Parameters) Item
 	Parameters() *Parameters
+
+	// WriteSFV writes the serialization of the item to w
+	WriteSFV(w io.Writer) (int, error)
+
+	// Raw returns the input text the item was parsed from, if the
+	// WithRawText option was enabled. Otherwise it returns nil.
+	Raw() []byte
+
+	// Span returns the location in the input the item was parsed from,
+	// if the WithSpans option was enabled.
+	Span() (Span, bool)
+
+	// Clone returns a deep copy of the item, including its parameters.
+	Clone() Item
+
+	// WithParameter returns a copy of the item with the parameter key
+	// set to value, leaving the item itself unchanged.
+	WithParameter(key string, value any) (Item, error)
+
+	// Freeze makes the item and its parameters read-only, and Frozen
+	// reports whether it was called.
+	Freeze()
+	Frozen() bool
+
+	// Kind returns ItemField, so that Items can be told apart from Lists
+	// and Dictionaries without a type switch. See KindOf.
+	Kind() FieldType
+}
+
+func (fi *FullItem[BT, UT]) bareItem() BareItem {
+	return fi.bare
 }
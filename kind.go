@@ -0,0 +1,43 @@
+package sfv
+
+// KindOf reports whether v, typically the result of Parse or ParseInner,
+// is a List, a Dictionary, or an Item, so that generic code can dispatch
+// on the kind of a field without a type switch over the concrete types.
+// Bare items are reported as Items. It returns UnknownField for nil
+// pointers, Inner Lists, and any other value.
+func KindOf(v any) FieldType {
+	switch v := v.(type) {
+	case interface{ Kind() FieldType }:
+		return v.Kind()
+	case List:
+		return ListField
+	case CoreItem:
+		return ItemField
+	default:
+		return UnknownField
+	}
+}
+
+// Kind returns ListField. It returns UnknownField if l is nil.
+func (l *List) Kind() FieldType {
+	if l == nil {
+		return UnknownField
+	}
+	return ListField
+}
+
+// Kind returns DictionaryField. It returns UnknownField if d is nil.
+func (d *Dictionary) Kind() FieldType {
+	if d == nil {
+		return UnknownField
+	}
+	return DictionaryField
+}
+
+// Kind returns ItemField. It returns UnknownField if fi is nil.
+func (fi *FullItem[BT, UT]) Kind() FieldType {
+	if fi == nil {
+		return UnknownField
+	}
+	return ItemField
+}
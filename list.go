@@ -1,8 +1,11 @@
 package sfv
 
 import (
-	"bytes"
 	"fmt"
+	"io"
+	"iter"
+	"slices"
+	"strings"
 )
 
 // InnerList represents a grouped sequence of Items with optional parameters
@@ -11,6 +14,9 @@ import (
 type InnerList struct {
 	values []Item
 	params *Parameters
+	raw    []byte
+	span   *Span
+	frozen bool
 }
 
 // NewInnerList creates a new empty InnerList with properly initialized parameters.
@@ -27,16 +33,64 @@ func NewInnerList() *InnerList {
 // BareItems are automatically converted to Items. Returns an error if the
 // item type is not supported.
 func (il *InnerList) Add(in any) error {
-	var item Item
+	if il.frozen {
+		return ErrFrozen
+	}
+	item, err := innerListItem(in)
+	if err != nil {
+		return err
+	}
+	il.values = append(il.values, item)
+	il.discardSource()
+	return nil
+}
+
+// innerListItem checks that in can be an inner list item, converting
+// BareItems to Items
+func innerListItem(in any) (Item, error) {
 	switch v := in.(type) {
 	case Item:
-		item = v
+		return v, nil
 	case BareItem:
-		item = v.ToItem()
+		return v.ToItem(), nil
 	default:
-		return fmt.Errorf("item must be of type Item or BareItem, got %T", item)
+		return nil, fmt.Errorf("item must be of type Item or BareItem, got %T", in)
 	}
-	il.values = append(il.values, item)
+}
+
+// Insert inserts in at index i, shifting the items at i and after it by
+// one. in is converted in the same way as by Add. i may be equal to Len,
+// in which case Insert is the same as Add. The parameters of the inner
+// list are kept, but the text and location recorded by WithRawText and
+// WithSpans are discarded, as they no longer describe the inner list.
+func (il *InnerList) Insert(i int, in any) error {
+	if il.frozen {
+		return ErrFrozen
+	}
+	if i < 0 || i > il.Len() {
+		return fmt.Errorf("index %d out of range for inner list of length %d", i, il.Len())
+	}
+	item, err := innerListItem(in)
+	if err != nil {
+		return err
+	}
+	il.values = slices.Insert(il.values, i, item)
+	il.discardSource()
+	return nil
+}
+
+// Remove removes the item at index i, shifting the items after it by one.
+// Like Insert, it keeps the parameters of the inner list, and discards
+// the text and location recorded by WithRawText and WithSpans.
+func (il *InnerList) Remove(i int) error {
+	if il.frozen {
+		return ErrFrozen
+	}
+	if i < 0 || i >= il.Len() {
+		return fmt.Errorf("index %d out of range for inner list of length %d", i, il.Len())
+	}
+	il.values = slices.Delete(il.values, i, i+1)
+	il.discardSource()
 	return nil
 }
 
@@ -56,14 +110,70 @@ func (il *InnerList) Get(index int) (Item, bool) {
 	return il.values[index], true
 }
 
+// All returns an iterator over the index and the value of each item of
+// the inner list, in order. The inner list must not be modified during
+// the iteration.
+func (il *InnerList) All() iter.Seq2[int, Item] {
+	return func(yield func(int, Item) bool) {
+		for i := range il.Len() {
+			if !yield(i, il.values[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns a copy of the items of the inner list. Modifying the
+// returned slice does not affect the inner list, but the items are shared.
+func (il *InnerList) Values() []Item {
+	if il == nil {
+		return nil
+	}
+	return slices.Clone(il.values)
+}
+
 // MarshalSFV implements the Marshaler interface for InnerList
 func (il *InnerList) MarshalSFV() ([]byte, error) {
-	var buf bytes.Buffer
-	buf.WriteByte('(')
+	return il.appendSFV(nil, &defaultEncodeConfig)
+}
+
+// AppendSFV appends the serialization of the inner list to dst.
+func (il *InnerList) AppendSFV(dst []byte) ([]byte, error) {
+	return il.appendSFV(dst, &defaultEncodeConfig)
+}
+
+// MarshalText implements encoding.TextMarshaler, and returns the same
+// serialization as MarshalSFV.
+func (il *InnerList) MarshalText() ([]byte, error) {
+	return il.MarshalSFV()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It parses text as
+// an Inner List, and replaces the contents of il with it.
+func (il *InnerList) UnmarshalText(text []byte) error {
+	if il.frozen {
+		return ErrFrozen
+	}
+	parsed, err := ParseInnerList(text)
+	if err != nil {
+		return err
+	}
+	*il = *parsed
+	return nil
+}
+
+// WriteSFV writes the serialization of the inner list to w, and returns
+// the number of bytes written.
+func (il *InnerList) WriteSFV(w io.Writer) (int, error) {
+	return writeSFV(w, il)
+}
+
+func (il *InnerList) appendSFV(dst []byte, cfg *encodeConfig) ([]byte, error) {
+	dst = append(dst, '(')
 
 	for i := range il.Len() {
 		if i > 0 {
-			buf.WriteByte(' ')
+			dst = append(dst, ' ')
 		}
 
 		item, ok := il.Get(i)
@@ -71,26 +181,131 @@ func (il *InnerList) MarshalSFV() ([]byte, error) {
 			continue
 		}
 
-		itemBytes, err := item.MarshalSFV()
+		var err error
+		dst, err = appendValue(dst, item, cfg)
 		if err != nil {
 			return nil, err
 		}
-
-		buf.Write(itemBytes)
 	}
 
-	buf.WriteByte(')')
+	dst = append(dst, ')')
 
 	// Add parameters if any
 	if il.params != nil && il.params.Len() > 0 {
-		paramBytes, err := il.params.MarshalSFV()
+		var err error
+		dst, err = il.params.appendSFV(dst, cfg)
 		if err != nil {
 			return nil, err
 		}
-		buf.Write(paramBytes)
 	}
 
-	return buf.Bytes(), nil
+	return dst, nil
+}
+
+// GoString returns Go-like notation for the inner list, such as
+// &sfv.InnerList{sfv.Token("a"), sfv.Integer(1)}, which is used by the
+// %#v verb. It is meant to make test failures readable, and is not
+// guaranteed to be valid Go code.
+func (il *InnerList) GoString() string {
+	if il == nil {
+		return "(*sfv.InnerList)(nil)"
+	}
+	var sb strings.Builder
+	sb.WriteString("&sfv.InnerList{")
+	for i, item := range il.values {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "%#v", item)
+	}
+	sb.WriteByte('}')
+	if il.params.Len() > 0 {
+		sb.WriteString(".With(")
+		sb.WriteString(il.params.GoString())
+		sb.WriteByte(')')
+	}
+	return sb.String()
+}
+
+// Freeze makes the inner list, its items, and its parameters read-only:
+// the methods that modify them fail with ErrFrozen from then on. See
+// List.Freeze for details.
+func (il *InnerList) Freeze() {
+	if il == nil {
+		return
+	}
+	il.frozen = true
+	il.params.Freeze()
+	for _, item := range il.values {
+		item.Freeze()
+	}
+}
+
+// Frozen reports whether Freeze was called on the inner list.
+func (il *InnerList) Frozen() bool {
+	return il != nil && il.frozen
+}
+
+// Clone returns a deep copy of the inner list, including its items and
+// parameters. Modifying the copy does not affect the original, and vice
+// versa. Cloning nil returns nil.
+func (il *InnerList) Clone() *InnerList {
+	if il == nil {
+		return nil
+	}
+	c := &InnerList{
+		values: make([]Item, len(il.values)),
+		params: il.params.Clone(),
+		raw:    il.raw,
+	}
+	for i, item := range il.values {
+		c.values[i] = item.Clone()
+	}
+	if il.span != nil {
+		span := *il.span
+		c.span = &span
+	}
+	return c
+}
+
+// Raw returns the exact input text this inner list was parsed from,
+// including the parentheses and any parameters. It returns nil unless the
+// inner list was parsed with the WithRawText option enabled, or if it
+// has been modified since with Add, Insert, Remove, SetParameters, or
+// Parameter. Items and parameters modified in place, such as through the
+// Parameters returned by the Parameters method, are not detected. The
+// returned slice must not be modified.
+func (il *InnerList) Raw() []byte {
+	if il == nil {
+		return nil
+	}
+	return il.raw
+}
+
+// Span returns the location in the input this inner list was parsed
+// from, including the parentheses and any parameters. The second return
+// value is false unless the inner list was parsed with the WithSpans
+// option enabled, or if it has been modified since, as for Raw.
+func (il *InnerList) Span() (Span, bool) {
+	if il == nil || il.span == nil {
+		return Span{}, false
+	}
+	return *il.span, true
+}
+
+func (il *InnerList) setRaw(raw []byte) {
+	il.raw = raw
+}
+
+func (il *InnerList) setSpan(span Span) {
+	il.span = &span
+}
+
+// discardSource forgets the text and location the inner list was parsed
+// from, once it has been modified
+func (il *InnerList) discardSource() {
+	il.raw = nil
+	il.span = nil
 }
 
 // Parameters returns the parameters associated with this InnerList
@@ -101,28 +316,177 @@ func (il *InnerList) Parameters() *Parameters {
 	return il.params
 }
 
+// SetParameters replaces the parameters of the inner list with params.
+// Passing nil removes all parameters. It returns ErrFrozen if the inner
+// list is frozen.
+func (il *InnerList) SetParameters(params *Parameters) error {
+	if il.frozen {
+		return ErrFrozen
+	}
+	if params == nil {
+		params = NewParameters()
+	}
+	il.params = params
+	il.discardSource()
+	return nil
+}
+
+// Parameter sets the parameter called name on the inner list. value is
+// converted to a BareItem in the same way as by BareItemFrom.
+func (il *InnerList) Parameter(name string, value any) error {
+	if il.frozen {
+		return ErrFrozen
+	}
+	bi, err := bareItemFrom(value, bareItemStringMode)
+	if err != nil {
+		return fmt.Errorf("failed to create bare item for parameter %s: %w", name, err)
+	}
+
+	if il.params == nil {
+		il.params = NewParameters()
+	}
+	if err := il.params.Set(name, bi); err != nil {
+		return fmt.Errorf("failed to set parameter %s: %w", name, err)
+	}
+	il.discardSource()
+	return nil
+}
+
 // List represents an ordered sequence of Items and InnerLists in the SFV format.
 // Lists can contain Items (with optional parameters) and InnerLists as comma-separated
 // values according to RFC 9651.
 type List struct {
 	values []any
+	raw    []byte
+	frozen bool
+}
+
+// NewList creates a new List holding the given values, which are
+// converted in the same way as by Append.
+func NewList(values ...any) (*List, error) {
+	var l List
+	if err := l.Append(values...); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// Append adds the given values to the end of the list. In addition to
+// Items, BareItems, and *InnerLists, values can be any Go value that
+// Marshal can convert to an Item or an Inner List, such as a string, an
+// int, or a slice of them. Either all values are added, or, if any of
+// them cannot be converted, none is and an error is returned.
+func (l *List) Append(values ...any) error {
+	if l.frozen {
+		return ErrFrozen
+	}
+	members := make([]any, len(values))
+	for i, value := range values {
+		member, err := memberFrom(value)
+		if err != nil {
+			return fmt.Errorf("invalid list member %d: %w", i, err)
+		}
+		members[i] = member
+	}
+	l.values = append(l.values, members...)
+	l.raw = nil
+	return nil
 }
 
 // Add adds an item to the list. The item must be an Item, BareItem, or *InnerList.
 // BareItems are automatically converted to Items. Returns an error if the
 // item type is not supported.
 func (l *List) Add(in any) error {
-	// Process the input to ensure it's a proper SFV item
+	if l.frozen {
+		return ErrFrozen
+	}
+	member, err := listMember(in)
+	if err != nil {
+		return err
+	}
+	l.values = append(l.values, member)
+	l.raw = nil
+	return nil
+}
+
+// listMember checks that in can be a List member, converting BareItems
+// to Items
+func listMember(in any) (any, error) {
 	switch v := in.(type) {
 	case Item:
-		l.values = append(l.values, v)
+		return v, nil
 	case BareItem:
-		l.values = append(l.values, v.ToItem())
+		return v.ToItem(), nil
 	case *InnerList:
-		l.values = append(l.values, v)
+		return v, nil
 	default:
-		return fmt.Errorf("list item must be of type Item, BareItem, or *InnerList, got %T", in)
+		return nil, fmt.Errorf("list item must be of type Item, BareItem, or *InnerList, got %T", in)
+	}
+}
+
+// Set replaces the member at index i with in, which is converted in the
+// same way as by Add. It is an error if i is out of range.
+func (l *List) Set(i int, in any) error {
+	if l.frozen {
+		return ErrFrozen
+	}
+	if i < 0 || i >= l.Len() {
+		return fmt.Errorf("index %d out of range for list of length %d", i, l.Len())
+	}
+	member, err := listMember(in)
+	if err != nil {
+		return err
+	}
+	l.values[i] = member
+	l.raw = nil
+	return nil
+}
+
+// WithMember returns a copy of the list, with the member at index i
+// replaced by in, which is converted in the same way as by Add. The list
+// itself is not modified. As with Dictionary.WithMember, the other
+// members are shared between the two lists.
+func (l *List) WithMember(i int, in any) (*List, error) {
+	c := &List{}
+	if l != nil {
+		c.values = slices.Clone(l.values)
+	}
+	if err := c.Set(i, in); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Insert inserts in at index i, shifting the members at i and after it
+// by one. in is converted in the same way as by Add. i may be equal to
+// Len, in which case Insert is the same as Add.
+func (l *List) Insert(i int, in any) error {
+	if l.frozen {
+		return ErrFrozen
+	}
+	if i < 0 || i > l.Len() {
+		return fmt.Errorf("index %d out of range for list of length %d", i, l.Len())
 	}
+	member, err := listMember(in)
+	if err != nil {
+		return err
+	}
+	l.values = slices.Insert(l.values, i, member)
+	l.raw = nil
+	return nil
+}
+
+// Remove removes the member at index i, shifting the members after it by
+// one. It is an error if i is out of range.
+func (l *List) Remove(i int) error {
+	if l.frozen {
+		return ErrFrozen
+	}
+	if i < 0 || i >= l.Len() {
+		return fmt.Errorf("index %d out of range for list of length %d", i, l.Len())
+	}
+	l.values = slices.Delete(l.values, i, i+1)
+	l.raw = nil
 	return nil
 }
 
@@ -131,8 +495,45 @@ func (l List) MarshalSFV() ([]byte, error) {
 	if l.Len() == 0 {
 		return nil, nil
 	}
+	return l.appendSFV(nil, &defaultEncodeConfig)
+}
+
+// AppendSFV appends the serialization of the list to dst.
+func (l *List) AppendSFV(dst []byte) ([]byte, error) {
+	return l.appendSFV(dst, &defaultEncodeConfig)
+}
+
+// MarshalText implements encoding.TextMarshaler, and returns the same
+// serialization as MarshalSFV.
+func (l *List) MarshalText() ([]byte, error) {
+	return l.MarshalSFV()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It parses text as a
+// List, and replaces the contents of l with it.
+func (l *List) UnmarshalText(text []byte) error {
+	if l.frozen {
+		return ErrFrozen
+	}
+	v, err := parse(text, parseModeList, nil)
+	if err != nil {
+		return err
+	}
+	parsed, ok := v.(*List)
+	if !ok {
+		return fmt.Errorf("expected *List, got %T", v)
+	}
+	*l = *parsed
+	return nil
+}
 
-	var buf bytes.Buffer
+// WriteSFV writes the serialization of the list to w, and returns the
+// number of bytes written.
+func (l *List) WriteSFV(w io.Writer) (int, error) {
+	return writeSFV(w, l)
+}
+
+func (l *List) appendSFV(dst []byte, cfg *encodeConfig) ([]byte, error) {
 	for i := range l.Len() {
 		value, ok := l.Get(i)
 		if !ok {
@@ -140,23 +541,113 @@ func (l List) MarshalSFV() ([]byte, error) {
 		}
 
 		if i > 0 {
-			buf.WriteString(", ")
+			dst = append(dst, ',', ' ')
 		}
 
-		vfsv, err := valueToSFV(value)
+		vfsv, err := cfg.valueToSFV(value)
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert value to SFV: %w", err)
 		}
 
-		item, err := vfsv.MarshalSFV()
+		dst, err = appendValue(dst, vfsv, cfg)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal value to SFV: %w", err)
 		}
+	}
+
+	return dst, nil
+}
+
+// GoString returns Go-like notation for the list, such as
+// &sfv.List{sfv.Token("a"), &sfv.InnerList{sfv.Integer(1)}}, which is
+// used by the %#v verb. It is meant to make test failures readable, and
+// is not guaranteed to be valid Go code.
+func (l *List) GoString() string {
+	if l == nil {
+		return "(*sfv.List)(nil)"
+	}
+	var sb strings.Builder
+	sb.WriteString("&sfv.List{")
+	for i, value := range l.values {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		fmt.Fprintf(&sb, "%#v", value)
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+// Clone returns a deep copy of the list, including its members and their
+// parameters. Modifying the copy does not affect the original, and vice
+// versa. Cloning nil returns nil.
+func (l *List) Clone() *List {
+	if l == nil {
+		return nil
+	}
+	c := &List{values: make([]any, len(l.values)), raw: l.raw}
+	for i, value := range l.values {
+		c.values[i] = cloneMember(value)
+	}
+	return c
+}
+
+// Freeze makes the list and all of its members read-only, including
+// Inner Lists, Items, and their parameters: the methods that modify them
+// fail with ErrFrozen from then on. A frozen list can be shared between
+// goroutines, as long as its members are only read, or derived from with
+// methods such as WithMember. Bare items that were added to the list as
+// is, and are retrieved as such, are not covered. There is no way to
+// unfreeze a list, but Clone returns a copy that is not frozen.
+func (l *List) Freeze() {
+	if l == nil {
+		return
+	}
+	l.frozen = true
+	for _, member := range l.values {
+		freezeMember(member)
+	}
+}
 
-		buf.Write(item)
+// Frozen reports whether Freeze was called on the list.
+func (l *List) Frozen() bool {
+	return l != nil && l.frozen
+}
+
+// freezeMember freezes a List or Dictionary member
+func freezeMember(v any) {
+	switch v := v.(type) {
+	case Item:
+		v.Freeze()
+	case *InnerList:
+		v.Freeze()
+	}
+}
+
+// cloneMember returns a deep copy of a List or Dictionary member
+func cloneMember(v any) any {
+	switch v := v.(type) {
+	case Item:
+		return v.Clone()
+	case *InnerList:
+		return v.Clone()
+	case BareItem:
+		return cloneBareItem(v)
+	default:
+		return v
 	}
+}
 
-	return buf.Bytes(), nil
+// Raw returns the exact input text the whole list was parsed from,
+// without leading and trailing whitespace. It returns nil unless the list
+// was parsed with the WithRawText option enabled, or if the list has been
+// modified since with methods such as Add or Remove. Members modified in
+// place are not detected. The returned slice must not be modified.
+func (l *List) Raw() []byte {
+	if l == nil {
+		return nil
+	}
+	return l.raw
 }
 
 // Len returns the number of values in the list
@@ -174,3 +665,111 @@ func (l *List) Get(index int) (any, bool) {
 	}
 	return l.values[index], true
 }
+
+// All returns an iterator over the index and the member of each element
+// of the list, in order. Members are either Items or *InnerLists. The list
+// must not be modified during the iteration.
+func (l *List) All() iter.Seq2[int, any] {
+	return func(yield func(int, any) bool) {
+		for i := range l.Len() {
+			if !yield(i, l.values[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Items returns an iterator over the members of the list that are Items,
+// along with their index in the list. Inner Lists are skipped. The list
+// must not be modified during the iteration.
+func (l *List) Items() iter.Seq2[int, Item] {
+	return func(yield func(int, Item) bool) {
+		for i := range l.Len() {
+			item, ok := l.values[i].(Item)
+			if !ok {
+				continue
+			}
+			if !yield(i, item) {
+				return
+			}
+		}
+	}
+}
+
+// Filter returns a new List holding the members of l for which fn returns
+// true, in order. Members are either Items or *InnerLists. l is not
+// modified, but the members are shared between l and the new List; use
+// Clone first if that is not desired.
+func (l *List) Filter(fn func(member any) bool) *List {
+	var ret List
+	for _, member := range l.All() {
+		if fn(member) {
+			ret.values = append(ret.values, member)
+		}
+	}
+	return &ret
+}
+
+// MapItems returns a new List in which each Item member of l is replaced
+// by the Item returned by fn, which can for example add parameters using
+// With. If fn returns a nil Item, the member is dropped. Inner Lists are
+// copied to the new List as is. l is not modified.
+//
+// If fn returns an error, MapItems stops and returns that error.
+func (l *List) MapItems(fn func(Item) (Item, error)) (*List, error) {
+	var ret List
+	for i, member := range l.All() {
+		item, ok := member.(Item)
+		if !ok {
+			ret.values = append(ret.values, member)
+			continue
+		}
+		mapped, err := fn(item)
+		if err != nil {
+			return nil, fmt.Errorf("failed to map list member %d: %w", i, err)
+		}
+		if mapped != nil {
+			ret.values = append(ret.values, mapped)
+		}
+	}
+	return &ret, nil
+}
+
+// Strings returns the values of the members of the list, which must all
+// be String Items. Their parameters are ignored.
+func (l *List) Strings() ([]string, error) {
+	return listValues[string](l, StringType)
+}
+
+// Tokens returns the values of the members of the list, which must all be
+// Token Items. Their parameters are ignored.
+func (l *List) Tokens() ([]string, error) {
+	return listValues[string](l, TokenType)
+}
+
+// Int64s returns the values of the members of the list, which must all
+// be Integer Items. Their parameters are ignored.
+func (l *List) Int64s() ([]int64, error) {
+	return listValues[int64](l, IntegerType)
+}
+
+// listValues returns the values of the members of l, which must all be
+// Items of type typ
+func listValues[T any](l *List, typ Type) ([]T, error) {
+	ret := make([]T, 0, l.Len())
+	for i, member := range l.All() {
+		item, ok := member.(Item)
+		if !ok {
+			return nil, fmt.Errorf("list member %d is not an item, got %T", i, member)
+		}
+		if item.Type() != typ {
+			return nil, fmt.Errorf("list member %d is of type %s, expected %s", i, item.Type(), typ)
+		}
+		var v T
+		if err := item.GetValue(&v); err != nil {
+			return nil, fmt.Errorf("failed to get value of list member %d: %w", i, err)
+		}
+		ret = append(ret, v)
+	}
+	return ret, nil
+}
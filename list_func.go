@@ -0,0 +1,55 @@
+package sfv
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrStop can be returned from the callback passed to ParseListFunc to
+// stop parsing without reporting an error.
+var ErrStop = errors.New("sfv: stop")
+
+// ParseListFunc parses data as a List, calling fn for each member as soon
+// as it has been parsed, instead of collecting all members into a *List.
+// This is useful when the caller is only looking for a particular member
+// of a potentially long list.
+//
+// member is either an Item or an *InnerList, and params holds the
+// parameters attached to it.
+//
+// If fn returns ErrStop, parsing stops and ParseListFunc returns nil. Note
+// that in this case the remainder of the input is not validated. If fn
+// returns any other error, parsing stops and that error is returned as is.
+func ParseListFunc(data []byte, fn func(member any, params *Parameters) error, options ...ParseOption) error {
+	pctx := getParseContext()
+	defer releaseParseContext(pctx)
+
+	pctx.init(data, parseModeList, options)
+
+	var fnErr error
+	pctx.stripWhitespace()
+	err := pctx.parseListMembers(func(member any) error {
+		var params *Parameters
+		switch v := member.(type) {
+		case Item:
+			params = v.Parameters()
+		case *InnerList:
+			params = v.Parameters()
+		}
+		fnErr = fn(member, params)
+		return fnErr
+	})
+	if fnErr != nil {
+		if errors.Is(fnErr, ErrStop) {
+			return nil
+		}
+		return fnErr
+	}
+	if err != nil {
+		return pctx.positionalError(fmt.Errorf("sfv: failed to parse list: %w", err))
+	}
+	if len(pctx.errors) > 0 {
+		return errors.Join(pctx.errors...)
+	}
+	return nil
+}
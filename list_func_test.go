@@ -0,0 +1,56 @@
+package sfv_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseListFunc(t *testing.T) {
+	const input = `sugar, tea;hot, ("a" "b");lvl=5, rum`
+
+	t.Run("visit all members", func(t *testing.T) {
+		var visited []string
+		err := sfv.ParseListFunc([]byte(input), func(member any, params *sfv.Parameters) error {
+			require.NotNil(t, params)
+			serialized, err := sfv.Marshal(member)
+			require.NoError(t, err)
+			visited = append(visited, string(serialized))
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"sugar", "tea;hot", `("a" "b");lvl=5`, "rum"}, visited)
+	})
+	t.Run("stop early", func(t *testing.T) {
+		var count int
+		// The garbage after the matching member is never looked at
+		err := sfv.ParseListFunc([]byte(`a, b;target, c, !!!`), func(_ any, params *sfv.Parameters) error {
+			count++
+			var target sfv.BareItem
+			if params.Get("target", &target) == nil {
+				return sfv.ErrStop
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+	})
+	t.Run("callback error", func(t *testing.T) {
+		myErr := errors.New("my error")
+		err := sfv.ParseListFunc([]byte(input), func(any, *sfv.Parameters) error {
+			return myErr
+		})
+		require.ErrorIs(t, err, myErr)
+	})
+	t.Run("parse error", func(t *testing.T) {
+		var count int
+		err := sfv.ParseListFunc([]byte(`a, b,`), func(any, *sfv.Parameters) error {
+			count++
+			return nil
+		})
+		require.Error(t, err)
+		require.Equal(t, 2, count)
+	})
+}
@@ -0,0 +1,264 @@
+package sfv_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+func parseList(t *testing.T, s string) *sfv.List {
+	t.Helper()
+	v, err := sfv.ParseString(s)
+	require.NoError(t, err)
+	list, ok := v.(*sfv.List)
+	require.True(t, ok, "expected *sfv.List, got %T", v)
+	return list
+}
+
+func marshalString(t *testing.T, v sfv.Marshaler) string {
+	t.Helper()
+	b, err := v.MarshalSFV()
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestListMutation(t *testing.T) {
+	list := parseList(t, `a, b, c`)
+
+	require.NoError(t, list.Set(1, sfv.BareToken("x")))
+	require.Equal(t, `a, x, c`, marshalString(t, list))
+
+	require.NoError(t, list.Insert(0, sfv.Integer(1)))
+	require.NoError(t, list.Insert(list.Len(), sfv.NewInnerList()))
+	require.Equal(t, `1, a, x, c, ()`, marshalString(t, list))
+
+	require.NoError(t, list.Remove(2))
+	require.NoError(t, list.Remove(list.Len()-1))
+	require.Equal(t, `1, a, c`, marshalString(t, list))
+
+	require.Error(t, list.Set(3, sfv.Token("y")))
+	require.Error(t, list.Set(0, "not an item"))
+	require.Error(t, list.Insert(-1, sfv.Token("y")))
+	require.Error(t, list.Insert(4, sfv.Token("y")))
+	require.Error(t, list.Insert(0, 42))
+	require.Error(t, list.Remove(3))
+	require.Error(t, list.Remove(-1))
+	require.Equal(t, `1, a, c`, marshalString(t, list), "failed calls should not modify the list")
+
+	var empty sfv.List
+	require.NoError(t, empty.Insert(0, sfv.Token("first")))
+	require.Equal(t, `first`, marshalString(t, &empty))
+}
+
+func TestNewList(t *testing.T) {
+	list, err := sfv.NewList("a", 1, 2.5, true, sfv.Token("tok"), sfv.BareInteger(7), []string{"x", "y"}, sfv.NewInnerList())
+	require.NoError(t, err)
+	require.Equal(t, `"a", 1, 2.5, ?1, tok, 7, ("x" "y"), ()`, marshalString(t, list))
+
+	require.NoError(t, list.Append())
+	require.NoError(t, list.Append(sfv.Token("z"), 3))
+	require.Equal(t, 10, list.Len())
+
+	require.Error(t, list.Append(sfv.Token("w"), make(chan int)))
+	require.Error(t, list.Append(nil))
+	require.Equal(t, 10, list.Len(), "nothing should be appended on error")
+
+	_, err = sfv.NewList(map[string]int{"a": 1})
+	require.Error(t, err, "dictionaries cannot be list members")
+
+	list, err = sfv.NewList()
+	require.NoError(t, err)
+	require.Zero(t, list.Len())
+}
+
+func TestListIterators(t *testing.T) {
+	list := parseList(t, `a, (b c), d;x`)
+
+	var indexes []int
+	var members []any
+	for i, member := range list.All() {
+		indexes = append(indexes, i)
+		members = append(members, member)
+	}
+	require.Equal(t, []int{0, 1, 2}, indexes)
+	require.IsType(t, &sfv.InnerList{}, members[1])
+
+	var tokens []string
+	indexes = nil
+	for i, item := range list.Items() {
+		indexes = append(indexes, i)
+		var s string
+		require.NoError(t, item.GetValue(&s))
+		tokens = append(tokens, s)
+	}
+	require.Equal(t, []int{0, 2}, indexes, "inner lists should be skipped")
+	require.Equal(t, []string{"a", "d"}, tokens)
+
+	for i := range list.All() {
+		require.Zero(t, i)
+		break
+	}
+	for range (*sfv.List)(nil).All() {
+		t.Fatal("nil list should have no members")
+	}
+}
+
+func TestListFilterAndMap(t *testing.T) {
+	list := parseList(t, `a;drop, (b c), d, e;drop`)
+
+	filtered := list.Filter(func(member any) bool {
+		item, ok := member.(sfv.Item)
+		if !ok {
+			return true
+		}
+		return !slices.Contains(item.Parameters().Keys(), "drop")
+	})
+	require.Equal(t, `(b c), d`, marshalString(t, filtered))
+	require.Equal(t, 4, list.Len(), "the original list should not be modified")
+
+	mapped, err := list.MapItems(func(item sfv.Item) (sfv.Item, error) {
+		var s string
+		if err := item.GetValue(&s); err != nil {
+			return nil, err
+		}
+		if s == "e" {
+			return nil, nil
+		}
+		params := item.Parameters().Clone()
+		if err := params.Set("seen", sfv.True()); err != nil {
+			return nil, err
+		}
+		return item.With(params), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, `a;drop;seen, (b c), d;seen`, marshalString(t, mapped))
+	require.Equal(t, `a;drop, (b c), d, e;drop`, marshalString(t, list), "the original list should not be modified")
+
+	_, err = list.MapItems(func(sfv.Item) (sfv.Item, error) {
+		return nil, errors.New("boom")
+	})
+	require.ErrorContains(t, err, "boom")
+}
+
+func TestListTypedValues(t *testing.T) {
+	strs, err := parseList(t, `"a", "b";x`).Strings()
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, strs)
+
+	tokens, err := parseList(t, `gzip;q=1, br`).Tokens()
+	require.NoError(t, err)
+	require.Equal(t, []string{"gzip", "br"}, tokens)
+
+	ints, err := parseList(t, `1, -2, 3`).Int64s()
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, -2, 3}, ints)
+
+	empty, err := (&sfv.List{}).Strings()
+	require.NoError(t, err)
+	require.Empty(t, empty)
+
+	_, err = parseList(t, `"a", b`).Strings()
+	require.ErrorContains(t, err, "list member 1 is of type token, expected string")
+	_, err = parseList(t, `1, (2)`).Int64s()
+	require.ErrorContains(t, err, "list member 1 is not an item")
+}
+
+func TestInnerListParameters(t *testing.T) {
+	il := sfv.NewInnerList()
+	require.NoError(t, il.Add(sfv.String("@method")))
+	require.NoError(t, il.Parameter("created", 1618884473))
+	require.NoError(t, il.Parameter("keyid", "test-key"))
+	require.Equal(t, `("@method");created=1618884473;keyid="test-key"`, marshalString(t, il))
+
+	require.Error(t, il.Parameter("bad", []int{1}))
+
+	params := sfv.NewParameters()
+	require.NoError(t, params.Set("alg", sfv.BareToken("ed25519")))
+	require.NoError(t, il.SetParameters(params))
+	require.Equal(t, `("@method");alg=ed25519`, marshalString(t, il))
+
+	require.NoError(t, il.SetParameters(nil))
+	require.Equal(t, `("@method")`, marshalString(t, il))
+
+	var zero sfv.InnerList
+	require.NoError(t, zero.Parameter("a", true))
+	require.Equal(t, `();a`, marshalString(t, &zero))
+}
+
+func TestInnerListIterators(t *testing.T) {
+	il, err := sfv.ParseInnerList([]byte(`("@method" "@path" "content-digest");created=1`))
+	require.NoError(t, err)
+
+	var names []string
+	for i, item := range il.All() {
+		require.Equal(t, len(names), i)
+		var s string
+		require.NoError(t, item.GetValue(&s))
+		names = append(names, s)
+	}
+	require.Equal(t, []string{"@method", "@path", "content-digest"}, names)
+
+	values := il.Values()
+	require.Len(t, values, 3)
+	values[0] = sfv.String("replaced")
+	first, _ := il.Get(0)
+	require.Equal(t, `"@method"`, marshalString(t, first), "modifying the copy should not affect the inner list")
+
+	require.Nil(t, (*sfv.InnerList)(nil).Values())
+	for range (*sfv.InnerList)(nil).All() {
+		t.Fatal("nil inner list should have no items")
+	}
+}
+
+func TestInnerListMutation(t *testing.T) {
+	il, err := sfv.ParseInnerList([]byte(`("@method" "@path" "content-digest");created=1`), sfv.WithRawText(true))
+	require.NoError(t, err)
+
+	require.NoError(t, il.Remove(1))
+	require.Nil(t, il.Raw(), "the recorded text should be discarded")
+	require.NoError(t, il.Insert(0, sfv.String("@authority")))
+	require.NoError(t, il.Insert(il.Len(), sfv.BareString("@status")))
+	require.Equal(t, `("@authority" "@method" "content-digest" "@status");created=1`, marshalString(t, il))
+
+	require.Error(t, il.Insert(-1, sfv.String("x")))
+	require.Error(t, il.Insert(5, sfv.String("x")))
+	require.ErrorContains(t, il.Insert(0, sfv.NewInnerList()), "got *sfv.InnerList")
+	require.Error(t, il.Remove(4))
+	require.Error(t, il.Remove(-1))
+	require.Equal(t, 4, il.Len())
+}
+
+func TestInnerListMutationDiscardsSource(t *testing.T) {
+	mutations := map[string]func(il *sfv.InnerList) error{
+		"Add":    func(il *sfv.InnerList) error { return il.Add(sfv.Integer(9)) },
+		"Insert": func(il *sfv.InnerList) error { return il.Insert(0, sfv.Integer(9)) },
+		"Remove": func(il *sfv.InnerList) error { return il.Remove(0) },
+		"SetParameters": func(il *sfv.InnerList) error {
+			params := sfv.NewParameters()
+			if err := params.Set("y", 1); err != nil {
+				return err
+			}
+			return il.SetParameters(params)
+		},
+		"Parameter": func(il *sfv.InnerList) error { return il.Parameter("y", 1) },
+	}
+	for name, mutate := range mutations {
+		t.Run(name, func(t *testing.T) {
+			dict, err := sfv.ParseDictionaryString(`b=(c d)`, sfv.WithRawText(true), sfv.WithSpans(true))
+			require.NoError(t, err)
+			var il *sfv.InnerList
+			require.NoError(t, dict.GetValue("b", &il))
+			require.Equal(t, `(c d)`, string(il.Raw()))
+			_, ok := il.Span()
+			require.True(t, ok)
+
+			require.NoError(t, mutate(il))
+			require.Nil(t, il.Raw(), "the recorded text should be discarded")
+			_, ok = il.Span()
+			require.False(t, ok, "the recorded location should be discarded")
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package sfv
+
+import (
+	"context"
+	"log/slog"
+)
+
+// debug logs msg to l at the debug level. l may be nil, in which case
+// nothing is logged. Callers on hot paths should check for a nil logger
+// themselves before building attributes.
+func debug(l *slog.Logger, msg string, attrs ...slog.Attr) {
+	if l == nil {
+		return
+	}
+	l.LogAttrs(context.Background(), slog.LevelDebug, msg, attrs...)
+}
+
+// parseModeName returns a human readable name of a parse mode, for logging
+func parseModeName(mode int) string {
+	switch mode {
+	case parseModeDefault:
+		return "unknown"
+	case parseModeList:
+		return "list"
+	case parseModeDictionary:
+		return "dictionary"
+	case parseModeItem:
+		return "item"
+	case parseModeBareItem:
+		return "bare item"
+	case parseModeInnerList:
+		return "inner list"
+	default:
+		return "invalid"
+	}
+}
@@ -2,83 +2,323 @@ package sfv
 
 import (
 	"bytes"
+	"encoding"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
 
 // Encoder provides configurable encoding of SFV (Structured Field Value) data.
-// It allows customization of formatting options like parameter spacing to support
-// different specifications (standard SFV vs HTTP Message Signature format).
+// It allows customization of formatting options like parameter spacing, for
+// compatibility with consumers that expect output other than the RFC 9651
+// serialization.
 type Encoder struct {
-	dst              io.Writer
-	parameterSpacing string // " " ---> "component; parameter", "" ---> "component;parameter"
+	dst io.Writer
+	cfg encodeConfig
 }
 
-// NewEncoder creates a new Encoder with default settings for encoding
-// Structured Field Values. The default format uses standard SFV spacing
-// with spaces after semicolons in parameters.
-func NewEncoder(dst io.Writer) *Encoder {
+// encodeConfig holds the settings that control serialization. It is
+// shared by Encoder and Marshal.
+type encodeConfig struct {
+	parameterSpacing      string // "" ---> "component;parameter", " " ---> "component; parameter"
+	sortDictionaryKeys    bool
+	displayStringFallback bool
+	fieldNaming           func(string) string // nil ---> strings.ToLower
+	textAsToken           bool
+	logger                *slog.Logger
+	integerOverflow       IntegerOverflowPolicy
+	maxFieldLength        int // 0 ---> no limit
+	emptyFieldError       bool
+	allowNil              bool
+}
+
+func newEncodeConfig(options []EncodeOption) encodeConfig {
+	var cfg encodeConfig
+	for _, option := range options {
+		switch option.Ident() {
+		case identParameterSpacing{}:
+			cfg.parameterSpacing = option.Value().(string) //nolint:forcetypeassert
+		case identSortedDictionaryKeys{}:
+			cfg.sortDictionaryKeys = option.Value().(bool) //nolint:forcetypeassert
+		case identDisplayStringFallback{}:
+			cfg.displayStringFallback = option.Value().(bool) //nolint:forcetypeassert
+		case identFieldNaming{}:
+			cfg.fieldNaming = option.Value().(func(string) string) //nolint:forcetypeassert
+		case identTextMarshalerAsToken{}:
+			cfg.textAsToken = option.Value().(bool) //nolint:forcetypeassert
+		case identLogger{}:
+			cfg.logger = option.Value().(*slog.Logger) //nolint:forcetypeassert
+		case identIntegerOverflow{}:
+			cfg.integerOverflow = option.Value().(IntegerOverflowPolicy) //nolint:forcetypeassert
+		case identMaxFieldLength{}:
+			cfg.maxFieldLength = option.Value().(int) //nolint:forcetypeassert
+		case identEmptyFieldError{}:
+			cfg.emptyFieldError = option.Value().(bool) //nolint:forcetypeassert
+		case identAllowNil{}:
+			cfg.allowNil = option.Value().(bool) //nolint:forcetypeassert
+		}
+	}
+	return cfg
+}
+
+// NewEncoder creates a new Encoder for encoding Structured Field Values.
+// By default the output is byte-for-byte the serialization specified by
+// RFC 9651, which does not put any space after the semicolons that precede
+// parameters. The behavior of the encoder can be customized by passing
+// options such as WithParameterSpacing.
+func NewEncoder(dst io.Writer, options ...EncodeOption) *Encoder {
 	return &Encoder{
-		dst:              dst,
-		parameterSpacing: " ", // Standard SFV format
+		dst: dst,
+		cfg: newEncodeConfig(options),
 	}
 }
 
 // SetParameterSpacing sets the spacing used after semicolons in parameters.
-// Use " " for standard SFV formatting, "" for HTTP Message Signature formatting.
+// Use "" for RFC 9651 formatting, " " to add a space after each semicolon.
+//
+// Deprecated: pass WithParameterSpacing to NewEncoder instead.
 func (enc *Encoder) SetParameterSpacing(spacing string) {
-	enc.parameterSpacing = spacing
+	enc.cfg.parameterSpacing = spacing
 }
 
 // Encode encodes the given value using the encoder's settings.
 func (enc *Encoder) Encode(v any) error {
-	if v == nil {
-		return fmt.Errorf(`cannot encode nil value`)
+	buf := getBuffer()
+	defer releaseBuffer(buf)
+
+	var err error
+	*buf, err = enc.cfg.marshalField(*buf, v)
+	if err != nil {
+		return err
+	}
+	if _, err = enc.dst.Write(*buf); err != nil {
+		return fmt.Errorf("failed to write encoded data: %w", err)
 	}
+	return nil
+}
+
+// Field is a single HTTP field, as written by Encoder.EncodeFields.
+type Field struct {
+	Name  string
+	Value any
+}
+
+// EncodeField writes v as the value of the HTTP field called name, in the
+// HTTP/1.1 format "Name: value\r\n". The field name must be a valid token
+// as defined by RFC 9110 Section 5.1. Nothing is written if the name is
+// invalid, or if the value cannot be encoded. Nothing is written either
+// if v is an empty List or Dictionary, as RFC 9651 specifies that such
+// fields are omitted; see WithEmptyFieldError.
+func (enc *Encoder) EncodeField(name string, v any) error {
+	buf := getBuffer()
+	defer releaseBuffer(buf)
+
+	var err error
+	*buf, err = enc.cfg.appendField(*buf, name, v)
+	if err != nil {
+		return err
+	}
+	if _, err := enc.dst.Write(*buf); err != nil {
+		return fmt.Errorf("failed to write encoded data: %w", err)
+	}
+	return nil
+}
 
-	if marshaler, ok := v.(Marshaler); ok {
-		result, err := marshaler.MarshalSFV()
+// EncodeFields writes each of the given fields in the same format as
+// EncodeField. All fields are encoded before anything is written, so
+// nothing is written if any of them fails to encode.
+func (enc *Encoder) EncodeFields(fields ...Field) error {
+	buf := getBuffer()
+	defer releaseBuffer(buf)
+
+	for _, field := range fields {
+		var err error
+		*buf, err = enc.cfg.appendField(*buf, field.Name, field.Value)
 		if err != nil {
 			return err
 		}
-		processed := enc.postProcessParameters(result)
-		if _, err = enc.dst.Write(processed); err != nil {
-			return fmt.Errorf("failed to write encoded data: %w", err)
+	}
+	if _, err := enc.dst.Write(*buf); err != nil {
+		return fmt.Errorf("failed to write encoded data: %w", err)
+	}
+	return nil
+}
+
+// appendField appends a complete field line to dst
+func (cfg *encodeConfig) appendField(dst []byte, name string, v any) ([]byte, error) {
+	if !isValidFieldName(name) {
+		return nil, fmt.Errorf("invalid field name %q", name)
+	}
+
+	if cfg.maxFieldLength > 0 {
+		chunks, err := cfg.marshalSplit(v, cfg.maxFieldLength)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode field %q: %w", name, err)
+		}
+		if len(chunks) == 0 && cfg.emptyFieldError {
+			return nil, fmt.Errorf("failed to encode field %q: %w", name, ErrEmptyField)
 		}
-		return nil
+		for _, chunk := range chunks {
+			dst = append(dst, name...)
+			dst = append(dst, ':', ' ')
+			dst = append(dst, chunk...)
+			dst = append(dst, '\r', '\n')
+		}
+		return dst, nil
+	}
+
+	start := len(dst)
+	dst = append(dst, name...)
+	dst = append(dst, ':', ' ')
+	n := len(dst)
+	dst, err := cfg.marshalField(dst, v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode field %q: %w", name, err)
+	}
+	if len(dst) == n {
+		// empty fields are omitted
+		return dst[:start], nil
+	}
+	return append(dst, '\r', '\n'), nil
+}
+
+// isValidFieldName checks if s is a valid field name, which is a token
+// as defined by RFC 9110 Section 5.6.2
+func isValidFieldName(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for i := range len(s) {
+		c := s[i]
+		if isAlpha(c) || isDigit(c) {
+			continue
+		}
+		switch c {
+		case '!', '#', '$', '%', '&', '\'', '*', '+', '-', '.', '^', '_', '`', '|', '~':
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// marshal appends the serialization of v to dst according to the
+// configuration
+func (cfg *encodeConfig) marshal(dst []byte, v any) ([]byte, error) {
+	marshaler, ok := v.(Marshaler)
+	if !ok {
+		// Convert to SFV type and marshal
+		sfvValue, err := cfg.valueToSFV(v)
+		if err != nil {
+			if cfg.logger != nil {
+				debug(cfg.logger, "sfv: failed to convert value", slog.String("type", fmt.Sprintf("%T", v)), slog.Any("error", err))
+			}
+			return nil, fmt.Errorf("failed to convert value to SFV: %w", err)
+		}
+		marshaler = sfvValue
+	}
+
+	dst, err := appendValue(dst, marshaler, cfg)
+	if err != nil && cfg.logger != nil {
+		debug(cfg.logger, "sfv: failed to serialize value", slog.String("type", fmt.Sprintf("%T", marshaler)), slog.Any("error", err))
+	}
+	return dst, err
+}
+
+// marshalField is the same as marshal, but fails with ErrEmptyField if v
+// serializes to an empty value and WithEmptyFieldError is enabled. It is
+// used for top-level values only, and also applies WithAllowNil. On
+// error, it returns dst as it was passed.
+func (cfg *encodeConfig) marshalField(dst []byte, v any) ([]byte, error) {
+	if v == nil {
+		if !cfg.allowNil {
+			return dst, errNilValue
+		}
+		if cfg.emptyFieldError {
+			return dst, ErrEmptyField
+		}
+		return dst, nil
 	}
 
-	// Convert to SFV type and marshal
-	sfvValue, err := valueToSFV(v)
+	n := len(dst)
+	out, err := cfg.marshal(dst, v)
 	if err != nil {
-		return fmt.Errorf("failed to convert value to SFV: %w", err)
+		return dst, err
+	}
+	if cfg.emptyFieldError && len(out) == n {
+		return dst, ErrEmptyField
 	}
+	return out, nil
+}
+
+// errNilValue is returned when encoding nil without WithAllowNil
+var errNilValue = errors.New("cannot encode nil value")
+
+// defaultEncodeConfig is the configuration used by the MarshalSFV methods,
+// which produce the serialization specified by RFC 9651
+var defaultEncodeConfig encodeConfig
 
-	return enc.Encode(sfvValue)
+// appender is implemented by the values in this package whose
+// serialization depends on the encoder configuration
+type appender interface {
+	appendSFV(dst []byte, cfg *encodeConfig) ([]byte, error)
 }
 
-// postProcessParameters adjusts parameter spacing based on encoder settings
-func (enc *Encoder) postProcessParameters(data []byte) []byte {
-	if enc.parameterSpacing == " " {
-		// Standard format - no changes needed
-		return data
+// appendValue appends the serialization of v to dst. Values that do not
+// depend on the encoder configuration, such as bare items and custom
+// Marshalers, are serialized using their AppendSFV or MarshalSFV method.
+func appendValue(dst []byte, v Marshaler, cfg *encodeConfig) ([]byte, error) {
+	switch v := v.(type) {
+	case appender:
+		return v.appendSFV(dst, cfg)
+	case BareItem:
+		return cfg.appendBareItem(dst, v)
+	case Appender:
+		return v.AppendSFV(dst)
 	}
 
-	if enc.parameterSpacing == "" {
-		// Remove spaces after semicolons for HTTP Message Signature format
-		return bytes.ReplaceAll(data, []byte("; "), []byte(";"))
+	b, err := v.MarshalSFV()
+	if err != nil {
+		return nil, err
 	}
+	return append(dst, b...), nil
+}
 
-	// Custom spacing - replace default " " with custom spacing
-	if enc.parameterSpacing != " " {
-		return bytes.ReplaceAll(data, []byte("; "), []byte(";"+enc.parameterSpacing))
+// appendBareItem appends the serialization of a bare item to dst
+func (cfg *encodeConfig) appendBareItem(dst []byte, v BareItem) ([]byte, error) {
+	if cfg.displayStringFallback {
+		if s, ok := v.(*StringBareItem); ok && !isASCII(s.value) {
+			return BareDisplayString(s.value).AppendSFV(dst)
+		}
 	}
+	return v.AppendSFV(dst)
+}
 
-	return data
+// Appender is the interface implemented by types that can append their
+// SFV serialization to an existing byte slice. All value types in this
+// package implement it, which allows callers to serialize into buffers
+// they manage themselves.
+type Appender interface {
+	AppendSFV(dst []byte) ([]byte, error)
+}
+
+// writeSFV writes the serialization of v to w
+func writeSFV(w io.Writer, v Appender) (int, error) {
+	buf := getBuffer()
+	defer releaseBuffer(buf)
+
+	var err error
+	*buf, err = v.AppendSFV(*buf)
+	if err != nil {
+		return 0, err
+	}
+	return w.Write(*buf)
 }
 
 // Marshaler is the interface implemented by types that can marshal themselves
@@ -91,31 +331,72 @@ type Marshaler interface { //nolint:iface
 // Marshal encodes the given value as a Structured Field Value and returns
 // the encoded bytes. The value can be any Go type that can be converted to
 // an SFV type (Item, List, Dictionary, etc.) or any type that implements
-// the Marshaler interface.
-func Marshal(v any) ([]byte, error) {
-	if v == nil {
-		return nil, nil
-	}
+// the Marshaler interface. The same options as NewEncoder can be used to
+// customize the output.
+//
+// Empty Lists and Dictionaries marshal to an empty value. Since RFC 9651
+// specifies that such fields must be omitted, use WithEmptyFieldError to
+// have Marshal fail with ErrEmptyField instead. Marshaling nil fails,
+// unless WithAllowNil is used, in which case nil is treated as an empty
+// value.
+//
+// Values that implement encoding.TextMarshaler are marshaled as Strings
+// holding their text, or as Tokens if WithTextMarshalerAsToken is used.
+//
+// Structs are marshaled as Dictionaries, with one member per exported
+// field. The `sfv` struct tag controls how each field is marshaled, and
+// takes the form `sfv:"name,option,..."`. The name overrides the key
+// derived from the field name, and a tag of "-" skips the field. The
+// following options are supported:
+//
+//   - omitempty: skip the field if it holds the zero value of its type
+//   - token: marshal a string, or the text of an encoding.TextMarshaler,
+//     as a Token instead of a String
+//   - displaystring: marshal a string, or the text of an
+//     encoding.TextMarshaler, as a Display String
+//   - date: marshal an integer or a time.Time as a Date
+//   - seconds: marshal a time.Duration as an Integer number of seconds,
+//     failing if it is not a whole number of seconds
+//   - decimalseconds: marshal a time.Duration as a Decimal number of
+//     seconds, rounded to the millisecond
+//   - innerlist: marshal a slice or an array as an Inner List
+//
+// When the field is a slice or an array, the token, displaystring, date,
+// seconds, and decimalseconds options apply to each of its elements.
+//
+// A struct can also represent a single Item or Inner List with parameters,
+// by tagging one of its fields with the "params" option. That field must
+// be a struct or a map, whose members become the parameters, and the
+// struct must have exactly one other field, which holds the value. The
+// omitempty option on the parameter field omits all the parameters when
+// the field holds the zero value.
+func Marshal(v any, options ...EncodeOption) ([]byte, error) {
+	cfg := newEncodeConfig(options)
 
-	if marshaler, ok := v.(Marshaler); ok {
-		return marshaler.MarshalSFV()
-	}
+	// Serialize into a pooled buffer, so that the garbage created while
+	// growing it is not repeated on every call, and copy the result out
+	buf := getBuffer()
+	defer releaseBuffer(buf)
 
-	// Convert to SFV type and marshal
-	sfvValue, err := valueToSFV(v)
-	if err != nil {
+	var err error
+	*buf, err = cfg.marshalField(*buf, v)
+	if err != nil || len(*buf) == 0 {
 		return nil, err
 	}
+	return bytes.Clone(*buf), nil
+}
 
-	if marshaler, ok := sfvValue.(Marshaler); ok {
-		return marshaler.MarshalSFV()
-	}
-
-	return nil, fmt.Errorf("SFV value does not implement Marshaler interface")
+// MarshalAppend is the same as Marshal, but appends the encoded bytes to
+// dst and returns the extended buffer, so that callers can reuse their
+// own buffers. On error, it returns dst unchanged along with the error,
+// although the bytes past len(dst) may have been overwritten.
+func MarshalAppend(dst []byte, v any, options ...EncodeOption) ([]byte, error) {
+	cfg := newEncodeConfig(options)
+	return cfg.marshalField(dst, v)
 }
 
 // valueToSFV converts a Go value to an SFV type (Item, List, Dictionary, or InnerList)
-func valueToSFV(v any) (Value, error) {
+func (cfg *encodeConfig) valueToSFV(v any) (Value, error) {
 	if v == nil {
 		return nil, fmt.Errorf("cannot marshal nil value")
 	}
@@ -124,9 +405,22 @@ func valueToSFV(v any) (Value, error) {
 	case Item, BareItem, *InnerList, *List, *Dictionary:
 		//nolint:forcetypeassert
 		return v.(Value), nil // Already an SFV type
+	case Marshaler:
+		return v, nil // Custom types that serialize themselves
 	}
 
 	rv := reflect.ValueOf(v)
+	if sfvValue, ok, err := lookupMarshaler(rv); ok {
+		if cfg.logger != nil {
+			debug(cfg.logger, "sfv: converted value using registered marshaler", slog.String("type", rv.Type().String()), slog.Any("error", err))
+		}
+		return sfvValue, err
+	}
+
+	if cfg.logger != nil {
+		debug(cfg.logger, "sfv: converting value", slog.String("type", rv.Type().String()))
+	}
+
 	for rv.Kind() == reflect.Ptr {
 		if rv.IsNil() {
 			return nil, fmt.Errorf("cannot marshal nil pointer")
@@ -134,6 +428,17 @@ func valueToSFV(v any) (Value, error) {
 		rv = rv.Elem()
 	}
 
+	// time.Time implements encoding.TextMarshaler, but is marshaled as a Date
+	if rv.Type() == reflect.TypeOf(time.Time{}) {
+		//nolint:forcetypeassert
+		t := rv.Interface().(time.Time)
+		return BareDate(t.Unix()), nil
+	}
+
+	if tm, ok := v.(encoding.TextMarshaler); ok {
+		return cfg.textToSFV(tm)
+	}
+
 	switch rv.Kind() {
 	case reflect.Bool:
 		if rv.Bool() {
@@ -142,23 +447,26 @@ func valueToSFV(v any) (Value, error) {
 		return False(), nil
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		val := rv.Int()
-		// RFC 9651: integers can have at most 15 decimal digits
-		// For negative numbers, this includes the minus sign, so the absolute value can be at most 14 digits
-		// But actually, the spec says 15 digits for the integer itself, sign doesn't count toward digit limit
+		// RFC 9651: integers can have at most 15 decimal digits, not
+		// counting the sign
 		if val > maxSFVInteger || val < -maxSFVInteger {
-			return nil, fmt.Errorf("int value %d too large to marshal as SFV integer (max 15 decimal digits)", val)
+			return cfg.overflowingInteger(strconv.FormatInt(val, 10), val < 0)
 		}
 		return BareInteger(val), nil
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		val := rv.Uint()
 		if val > maxSFVInteger { // RFC 9651: max 15 decimal digits
-			return nil, fmt.Errorf("uint value %d too large to marshal as SFV integer (max 15 decimal digits)", val)
+			return cfg.overflowingInteger(strconv.FormatUint(val, 10), false)
 		}
 		return BareInteger(int64(val)), nil
 
 	case reflect.Float32, reflect.Float64:
-		return BareDecimal(rv.Float()), nil
+		var d DecimalBareItem
+		if err := d.SetValue(rv.Float()); err != nil {
+			return nil, err
+		}
+		return &d, nil
 
 	case reflect.String:
 		str := rv.String()
@@ -170,7 +478,7 @@ func valueToSFV(v any) (Value, error) {
 			return BareByteSequence(rv.Bytes()), nil
 		}
 		// Other slices become Lists
-		return sliceToList(rv)
+		return cfg.sliceToList(rv)
 
 	case reflect.Array:
 		if rv.Type().Elem().Kind() == reflect.Uint8 {
@@ -180,72 +488,112 @@ func valueToSFV(v any) (Value, error) {
 			return BareByteSequence(bytes), nil
 		}
 		// Other arrays become Lists
-		return arrayToList(rv)
+		return cfg.arrayToList(rv)
 
 	case reflect.Map:
-		return mapToDictionary(rv)
+		return cfg.mapToDictionary(rv)
 
 	case reflect.Struct:
-		// Handle time.Time specially
-		if rv.Type() == reflect.TypeOf(time.Time{}) {
-			//nolint:forcetypeassert
-			t := rv.Interface().(time.Time)
-			return BareDate(t.Unix()), nil
-		}
-		// Other structs become dictionaries with field names as keys
-		return structToDictionary(rv)
+		// Structs become dictionaries with field names as keys
+		return cfg.structToSFV(rv)
 
 	default:
 		return nil, fmt.Errorf("unsupported type for SFV marshaling: %T", v)
 	}
 }
 
-// sliceToList converts a slice to an SFV List
-func sliceToList(rv reflect.Value) (*List, error) {
-	values := make([]any, rv.Len())
-	for i := range rv.Len() {
-		elem := rv.Index(i)
-		sfvValue, err := valueToSFV(elem.Interface())
-		if err != nil {
-			return nil, fmt.Errorf("error marshaling slice element %d: %w", i, err)
+// overflowingInteger converts an integer that is out of the range allowed
+// for Integers according to the configured policy. s is the decimal
+// representation of the integer.
+func (cfg *encodeConfig) overflowingInteger(s string, negative bool) (Value, error) {
+	switch cfg.integerOverflow {
+	case IntegerOverflowClamp:
+		if negative {
+			return BareInteger(-maxSFVInteger), nil
 		}
+		return BareInteger(maxSFVInteger), nil
+	case IntegerOverflowString:
+		return BareString(s), nil
+	default:
+		return nil, fmt.Errorf("integer value %s too large to marshal as SFV integer (max 15 decimal digits)", s)
+	}
+}
 
-		values[i] = sfvValue
+// textToSFV converts a value that implements encoding.TextMarshaler to a
+// String, or to a Token if the configuration says so
+func (cfg *encodeConfig) textToSFV(tm encoding.TextMarshaler) (Value, error) {
+	text, err := tm.MarshalText()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %T as text: %w", tm, err)
 	}
-	l := &List{values: values}
-	return l, nil
+	if cfg.textAsToken {
+		return BareToken(string(text)), nil
+	}
+	return BareString(string(text)), nil
+}
+
+// sliceToList converts a slice to an SFV List
+func (cfg *encodeConfig) sliceToList(rv reflect.Value) (*List, error) {
+	return cfg.sequenceToList(rv, "slice")
 }
 
 // arrayToList converts an array to an SFV List
-func arrayToList(rv reflect.Value) (*List, error) {
+func (cfg *encodeConfig) arrayToList(rv reflect.Value) (*List, error) {
+	return cfg.sequenceToList(rv, "array")
+}
+
+// sequenceToList converts a slice or an array to an SFV List. Elements
+// may be Go values or SFV values, freely mixed. Nested slices become
+// Inner Lists.
+func (cfg *encodeConfig) sequenceToList(rv reflect.Value, kind string) (*List, error) {
 	values := make([]any, rv.Len())
 	for i := range rv.Len() {
 		elem := rv.Index(i)
-		sfvValue, err := valueToSFV(elem.Interface())
+		sfvValue, err := cfg.valueToSFV(elem.Interface())
 		if err != nil {
-			return nil, fmt.Errorf("error marshaling array element %d: %w", i, err)
+			return nil, fmt.Errorf("error marshaling %s element %d: %w", kind, i, err)
 		}
 
-		// Convert BareItem to Item if needed
-		switch v := sfvValue.(type) {
-		case Item:
-			values[i] = v
-		case BareItem:
-			values[i] = v.ToItem()
-		default:
+		if isCustomMarshaler(sfvValue) {
+			// The List is serialized by calling MarshalSFV on the element
 			values[i] = sfvValue
+			continue
 		}
+
+		member, err := toMember(sfvValue)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling %s element %d: %w", kind, i, err)
+		}
+		values[i] = member
 	}
 	return &List{values: values}, nil
 }
 
 // mapToDictionary converts a map to an SFV Dictionary
-func mapToDictionary(rv reflect.Value) (*Dictionary, error) {
-	if rv.Type().Key().Kind() != reflect.String {
-		return nil, fmt.Errorf("dictionary keys must be strings, got %s", rv.Type().Key())
+func (cfg *encodeConfig) mapToDictionary(rv reflect.Value) (*Dictionary, error) {
+	dict := NewDictionary()
+	err := cfg.mapMembers(rv, func(key string, v Value) error {
+		dictValue, err := toMember(v)
+		if err != nil {
+			return fmt.Errorf("error marshaling dictionary value for key %q: %w", key, err)
+		}
+		if err := dict.Set(key, dictValue); err != nil {
+			return fmt.Errorf("error setting dictionary key %q: %w", key, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return dict, nil
+}
 
-	dict := NewDictionary()
+// mapMembers calls fn with the key and the converted value of each entry
+// of the map rv, in sorted key order
+func (cfg *encodeConfig) mapMembers(rv reflect.Value, fn func(key string, v Value) error) error {
+	if rv.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("dictionary keys must be strings, got %s", rv.Type().Key())
+	}
 
 	// Get keys and sort them for deterministic output
 	keys := rv.MapKeys()
@@ -257,53 +605,124 @@ func mapToDictionary(rv reflect.Value) (*Dictionary, error) {
 
 	for _, keyStr := range keyStrings {
 		if !isValidKey(keyStr) {
-			return nil, fmt.Errorf("invalid dictionary key: %q", keyStr)
+			return fmt.Errorf("invalid dictionary key: %q", keyStr)
 		}
 
-		key := reflect.ValueOf(keyStr)
+		key := reflect.ValueOf(keyStr).Convert(rv.Type().Key())
 		value := rv.MapIndex(key)
-		sfvValue, err := valueToSFV(value.Interface())
+		sfvValue, err := cfg.valueToSFV(value.Interface())
 		if err != nil {
-			return nil, fmt.Errorf("error marshaling dictionary value for key %q: %w", keyStr, err)
-		}
-
-		// Convert the SFV value to Item or InnerList as expected by Dictionary
-		var dictValue any
-		switch v := sfvValue.(type) {
-		case Item:
-			dictValue = v
-		case BareItem:
-			// Convert BareItem to Item
-			dictValue = v.ToItem()
-		case *List:
-			// Convert List to InnerList for dictionary
-			innerList := &InnerList{values: make([]Item, 0)}
-			for i := range v.Len() {
-				if val, ok := v.Get(i); ok {
-					if item, ok := val.(Item); ok {
-						innerList.values = append(innerList.values, item)
-					} else {
-						return nil, fmt.Errorf("list element is not an Item: %T", val)
-					}
-				}
+			return fmt.Errorf("error marshaling dictionary value for key %q: %w", keyStr, err)
+		}
+
+		if err := fn(keyStr, sfvValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isCustomMarshaler reports whether v is a Marshaler that is not one of
+// the value types of this package
+func isCustomMarshaler(v Value) bool {
+	switch v.(type) {
+	case Item, BareItem, *InnerList, *List, List, *Dictionary:
+		return false
+	default:
+		return true
+	}
+}
+
+// toMember converts an SFV value to an Item or an InnerList, as expected
+// by List and Dictionary
+func toMember(v Value) (any, error) {
+	switch v := v.(type) {
+	case Item:
+		return v, nil
+	case BareItem:
+		return v.ToItem(), nil
+	case *InnerList:
+		return v, nil
+	case *List:
+		// Convert List to InnerList for dictionary. Inner Lists can only
+		// hold Items, so Lists of Lists cannot be converted.
+		innerList := NewInnerList()
+		for i := range v.Len() {
+			val, _ := v.Get(i)
+			switch val := val.(type) {
+			case Item:
+				innerList.values = append(innerList.values, val)
+			case BareItem:
+				innerList.values = append(innerList.values, val.ToItem())
+			default:
+				return nil, fmt.Errorf("inner list element %d must be an Item, got %T", i, val)
 			}
-			dictValue = innerList
-		default:
-			return nil, fmt.Errorf("dictionary values must be Items or Lists, got %T", v)
 		}
+		return innerList, nil
+	default:
+		return nil, fmt.Errorf("members must be Items, Inner Lists, or Lists, got %T", v)
+	}
+}
 
-		if err := dict.Set(keyStr, dictValue); err != nil {
-			return nil, fmt.Errorf("error setting dictionary key %q: %w", keyStr, err)
+// toBareItem converts an SFV value to a BareItem, as expected by Parameters
+func toBareItem(v Value) (BareItem, error) {
+	switch v := v.(type) {
+	case BareItem:
+		return v, nil
+	case interface{ bareItem() BareItem }:
+		if item, ok := v.(Item); ok && item.Parameters().Len() > 0 {
+			return nil, fmt.Errorf("parameter values cannot have parameters")
 		}
+		return v.bareItem(), nil
+	default:
+		return nil, fmt.Errorf("parameter values must be bare items, got %T", v)
 	}
-	return dict, nil
 }
 
-// structToDictionary converts a struct to an SFV Dictionary using field names as keys
-func structToDictionary(rv reflect.Value) (*Dictionary, error) {
+// structToSFV converts a struct to an SFV type. Structs with a field
+// tagged with the "params" option become an Item or an Inner List with
+// parameters, and all other structs become Dictionaries
+func (cfg *encodeConfig) structToSFV(rv reflect.Value) (Value, error) {
 	rt := rv.Type()
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag, err := parseFieldTag(field.Tag.Get("sfv"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid sfv tag on struct field %s: %w", field.Name, err)
+		}
+		if tag.params {
+			return cfg.structToMember(rv)
+		}
+	}
+	return cfg.structToDictionary(rv)
+}
+
+// structToDictionary converts a struct to an SFV Dictionary using field names as keys
+func (cfg *encodeConfig) structToDictionary(rv reflect.Value) (*Dictionary, error) {
 	dict := NewDictionary()
+	err := cfg.structMembers(rv, func(field, key string, v Value) error {
+		dictValue, err := toMember(v)
+		if err != nil {
+			return fmt.Errorf("error marshaling struct field %s: %w", field, err)
+		}
+		if err := dict.Set(key, dictValue); err != nil {
+			return fmt.Errorf("error setting dictionary key %q from field %s: %w", key, field, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dict, nil
+}
 
+// structMembers calls fn with the name, the key, and the converted value
+// of each exported field of the struct rv, honoring the `sfv` struct tags
+func (cfg *encodeConfig) structMembers(rv reflect.Value, fn func(field, key string, v Value) error) error {
+	rt := rv.Type()
 	for i := range rt.NumField() {
 		field := rt.Field(i)
 		fieldValue := rv.Field(i)
@@ -313,57 +732,341 @@ func structToDictionary(rv reflect.Value) (*Dictionary, error) {
 			continue
 		}
 
-		// Use struct tag if available, otherwise use field name
-		keyName := field.Name
-		if tag := field.Tag.Get("sfv"); tag != "" {
-			if tag == "-" {
-				continue // Skip this field
+		tag, err := parseFieldTag(field.Tag.Get("sfv"))
+		if err != nil {
+			return fmt.Errorf("invalid sfv tag on struct field %s: %w", field.Name, err)
+		}
+		if tag.skip {
+			continue
+		}
+		if tag.params {
+			return fmt.Errorf("struct field %s: params option cannot be used in a struct marshaled as parameters", field.Name)
+		}
+		if tag.omitEmpty && fieldValue.IsZero() {
+			if cfg.logger != nil {
+				debug(cfg.logger, "sfv: omitting empty struct field", slog.String("field", field.Name))
 			}
-			keyName = tag
+			continue
 		}
 
-		// Convert field name to lowercase for SFV key format
-		keyName = strings.ToLower(keyName)
+		// Use the name from the struct tag if available, otherwise derive
+		// the key from the field name using the configured naming strategy
+		var keyName string
+		if tag.name != "" {
+			keyName = strings.ToLower(tag.name)
+		} else {
+			keyName = cfg.fieldName(field.Name)
+		}
 
 		if !isValidKey(keyName) {
-			return nil, fmt.Errorf("invalid dictionary key from field %s: %q", field.Name, keyName)
+			return fmt.Errorf("invalid dictionary key from field %s: %q", field.Name, keyName)
 		}
 
-		sfvValue, err := valueToSFV(fieldValue.Interface())
+		sfvValue, err := cfg.fieldToSFV(fieldValue, tag)
+		if err != nil {
+			return fmt.Errorf("error marshaling struct field %s: %w", field.Name, err)
+		}
+
+		if err := fn(field.Name, keyName, sfvValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// structToMember converts a struct with a field tagged with the "params"
+// option to an Item or an Inner List. The struct must have exactly one
+// other field, which holds the value of the member.
+func (cfg *encodeConfig) structToMember(rv reflect.Value) (Value, error) {
+	rt := rv.Type()
+
+	var value Value
+	var params *Parameters
+	var valueField string
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, err := parseFieldTag(field.Tag.Get("sfv"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid sfv tag on struct field %s: %w", field.Name, err)
+		}
+		if tag.skip {
+			continue
+		}
+
+		fieldValue := rv.Field(i)
+		if tag.params {
+			if params != nil {
+				return nil, fmt.Errorf("struct field %s: only one field may have the params option", field.Name)
+			}
+			params = NewParameters()
+			if tag.omitEmpty && fieldValue.IsZero() {
+				continue
+			}
+			if err := cfg.fillParameters(params, fieldValue); err != nil {
+				return nil, fmt.Errorf("error marshaling parameters from struct field %s: %w", field.Name, err)
+			}
+			continue
+		}
+
+		if valueField != "" {
+			return nil, fmt.Errorf("struct field %s: a struct with parameters must have a single value field, found %s as well", field.Name, valueField)
+		}
+		valueField = field.Name
+		value, err = cfg.fieldToSFV(fieldValue, tag)
 		if err != nil {
 			return nil, fmt.Errorf("error marshaling struct field %s: %w", field.Name, err)
 		}
+	}
+
+	if valueField == "" {
+		return nil, fmt.Errorf("a struct with parameters must have a value field")
+	}
+
+	member, err := toMember(value)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling struct field %s: %w", valueField, err)
+	}
+
+	switch member := member.(type) {
+	case *InnerList:
+		il := *member
+		il.params = params
+		return &il, nil
+	default:
+		//nolint:forcetypeassert
+		return member.(Item).With(params), nil
+	}
+}
+
+// fillParameters adds the fields of the struct, or the entries of the
+// map, held by rv to params
+func (cfg *encodeConfig) fillParameters(params *Parameters, rv reflect.Value) error {
+	rv, err := indirectValue(rv)
+	if err != nil {
+		return err
+	}
+
+	set := func(key string, v Value) error {
+		bi, err := toBareItem(v)
+		if err != nil {
+			return fmt.Errorf("error marshaling parameter %q: %w", key, err)
+		}
+		return params.Set(key, bi)
+	}
 
-		// Convert the SFV value to Item or InnerList as expected by Dictionary
-		var dictValue any
-		switch v := sfvValue.(type) {
-		case Item:
-			dictValue = v
-		case BareItem:
-			// Convert BareItem to Item
-			dictValue = v.ToItem()
-		case *List:
-			// Convert List to InnerList for dictionary
-			innerList := &InnerList{values: make([]Item, 0)}
-			for j := range v.Len() {
-				if val, ok := v.Get(j); ok {
-					if item, ok := val.(Item); ok {
-						innerList.values = append(innerList.values, item)
-					} else {
-						return nil, fmt.Errorf("list element is not an Item: %T", val)
-					}
-				}
+	switch rv.Kind() {
+	case reflect.Struct:
+		return cfg.structMembers(rv, func(_, key string, v Value) error {
+			return set(key, v)
+		})
+	case reflect.Map:
+		return cfg.mapMembers(rv, set)
+	default:
+		return fmt.Errorf("params option requires a struct or a map, got %s", rv.Type())
+	}
+}
+
+// fieldTag holds the contents of an `sfv` struct tag, which takes the
+// form `sfv:"name,option,..."`
+type fieldTag struct {
+	name      string
+	skip      bool
+	omitEmpty bool
+	innerList bool
+	params    bool
+	as        string // type option such as "token". "" ---> inferred from the Go type
+}
+
+func parseFieldTag(tag string) (fieldTag, error) {
+	var ft fieldTag
+	if tag == "-" {
+		ft.skip = true
+		return ft, nil
+	}
+
+	name, options, _ := strings.Cut(tag, ",")
+	ft.name = name
+	for options != "" {
+		var option string
+		option, options, _ = strings.Cut(options, ",")
+
+		switch option {
+		case "omitempty":
+			ft.omitEmpty = true
+		case "innerlist":
+			ft.innerList = true
+		case "params":
+			ft.params = true
+		case "token", "displaystring", "date", "seconds", "decimalseconds":
+			if ft.as != "" {
+				return ft, fmt.Errorf("option %q conflicts with option %q", option, ft.as)
 			}
-			dictValue = innerList
+			ft.as = option
 		default:
-			return nil, fmt.Errorf("struct field values must be convertible to Items or Lists, got %T", v)
+			return ft, fmt.Errorf("unknown option %q", option)
+		}
+	}
+	return ft, nil
+}
+
+// isSequence reports whether rv is a slice or array that is marshaled as
+// a List, that is, anything other than a byte sequence
+func isSequence(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		return rv.Type().Elem().Kind() != reflect.Uint8
+	default:
+		return false
+	}
+}
+
+// fieldToSFV converts the value of a struct field to an SFV type, honoring
+// the options in its tag. Type options such as "token" apply to each
+// element when the field is a slice or an array.
+func (cfg *encodeConfig) fieldToSFV(rv reflect.Value, tag fieldTag) (Value, error) {
+	if !tag.innerList && tag.as == "" {
+		return cfg.valueToSFV(rv.Interface())
+	}
+
+	rv, err := indirectValue(rv)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isSequence(rv) {
+		if tag.innerList {
+			return nil, fmt.Errorf("innerlist option requires a slice or array, got %s", rv.Type())
 		}
+		return cfg.taggedValueToSFV(rv, tag.as)
+	}
 
-		if err := dict.Set(keyName, dictValue); err != nil {
-			return nil, fmt.Errorf("error setting dictionary key %q from field %s: %w", keyName, field.Name, err)
+	il := NewInnerList()
+	for i := range rv.Len() {
+		v, err := cfg.taggedValueToSFV(rv.Index(i), tag.as)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling inner list element %d: %w", i, err)
+		}
+		if err := il.Add(v); err != nil {
+			return nil, fmt.Errorf("error marshaling inner list element %d: %w", i, err)
 		}
 	}
-	return dict, nil
+	return il, nil
+}
+
+// taggedValueToSFV converts rv as specified by the type option as, such
+// as "token". If as is empty, the item type is inferred from the Go type
+// as in valueToSFV
+func (cfg *encodeConfig) taggedValueToSFV(rv reflect.Value, as string) (Value, error) {
+	if as == "" {
+		return cfg.valueToSFV(rv.Interface())
+	}
+
+	rv, err := indirectValue(rv)
+	if err != nil {
+		return nil, err
+	}
+
+	switch as {
+	case "token", "displaystring":
+		str, err := textValue(rv)
+		if err != nil {
+			return nil, fmt.Errorf("%s option: %w", as, err)
+		}
+		if as == "token" {
+			return BareToken(str), nil
+		}
+		return BareDisplayString(str), nil
+	case "date":
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return BareDate(rv.Int()), nil
+		}
+		if t, ok := rv.Interface().(time.Time); ok {
+			return BareDate(t.Unix()), nil
+		}
+		return nil, fmt.Errorf("date option requires an integer or time.Time, got %s", rv.Type())
+	case "seconds", "decimalseconds":
+		if rv.Type() != reflect.TypeOf(time.Duration(0)) {
+			return nil, fmt.Errorf("%s option requires a time.Duration, got %s", as, rv.Type())
+		}
+		//nolint:forcetypeassert
+		d := rv.Interface().(time.Duration)
+		if as == "decimalseconds" {
+			var bi DecimalBareItem
+			if err := bi.SetValue(d.Seconds()); err != nil {
+				return nil, err
+			}
+			return &bi, nil
+		}
+		if d%time.Second != 0 {
+			return nil, fmt.Errorf("duration %s is not a whole number of seconds (use the decimalseconds option instead)", d)
+		}
+		secs := int64(d / time.Second)
+		if secs > maxSFVInteger || secs < -maxSFVInteger {
+			return cfg.overflowingInteger(strconv.FormatInt(secs, 10), secs < 0)
+		}
+		return BareInteger(secs), nil
+	}
+	return cfg.valueToSFV(rv.Interface())
+}
+
+// textValue returns the text held by rv, which must be a string or
+// implement encoding.TextMarshaler
+func textValue(rv reflect.Value) (string, error) {
+	if rv.Kind() == reflect.String {
+		return rv.String(), nil
+	}
+
+	var tm encoding.TextMarshaler
+	if rv.CanAddr() {
+		tm, _ = rv.Addr().Interface().(encoding.TextMarshaler)
+	} else {
+		tm, _ = rv.Interface().(encoding.TextMarshaler)
+	}
+	if tm == nil {
+		return "", fmt.Errorf("value must be a string or implement encoding.TextMarshaler, got %s", rv.Type())
+	}
+
+	text, err := tm.MarshalText()
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s as text: %w", rv.Type(), err)
+	}
+	return string(text), nil
+}
+
+// indirectValue follows pointers and interfaces until it reaches a
+// concrete value
+func indirectValue(rv reflect.Value) (reflect.Value, error) {
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return rv, fmt.Errorf("cannot marshal nil value")
+		}
+		rv = rv.Elem()
+	}
+	return rv, nil
+}
+
+// fieldName converts the name of a struct field to a Dictionary key
+func (cfg *encodeConfig) fieldName(name string) string {
+	if cfg.fieldNaming == nil {
+		return strings.ToLower(name)
+	}
+	return cfg.fieldNaming(name)
+}
+
+// KeyStrict returns s if it is a valid Dictionary or Parameter key, as
+// specified in RFC 9651 Section 3.1.2: a lowercase letter or "*",
+// followed by lowercase letters, digits, "_", "-", ".", or "*".
+// Otherwise it returns an error.
+func KeyStrict(s string) (string, error) {
+	if !isValidKey(s) {
+		return "", fmt.Errorf("sfv: invalid key %q", s)
+	}
+	return s, nil
 }
 
 // isValidKey checks if a string is a valid SFV dictionary key
@@ -1,6 +1,13 @@
 package sfv_test
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/netip"
+	"reflect"
 	"testing"
 	"time"
 
@@ -63,7 +70,7 @@ func TestMarshal(t *testing.T) {
 				tok.Parameter("param", "value")
 				return tok
 			},
-			expected: `token; param="value"`,
+			expected: `token;param="value"`,
 		},
 		{
 			name:     "Token with numbers",
@@ -243,15 +250,43 @@ func TestMarshalWithCustomMarshaler(t *testing.T) {
 }
 
 func TestMarshalNil(t *testing.T) {
-	result, err := sfv.Marshal(nil)
-	if err != nil {
-		t.Errorf("Marshal(nil) unexpected error: %v", err)
-		return
-	}
+	t.Run("default", func(t *testing.T) {
+		_, err := sfv.Marshal(nil)
+		require.Error(t, err)
 
-	if result != nil {
-		t.Errorf("Marshal(nil) = %v, want nil", result)
-	}
+		_, err = sfv.MarshalAppend([]byte("x"), nil)
+		require.Error(t, err)
+
+		_, err = sfv.MarshalSplit(nil, 10)
+		require.Error(t, err)
+
+		var buf bytes.Buffer
+		require.Error(t, sfv.NewEncoder(&buf).Encode(nil))
+		require.Zero(t, buf.Len())
+	})
+	t.Run("WithAllowNil", func(t *testing.T) {
+		opt := sfv.WithAllowNil(true)
+		result, err := sfv.Marshal(nil, opt)
+		require.NoError(t, err)
+		require.Nil(t, result)
+
+		result, err = sfv.MarshalAppend([]byte("x"), nil, opt)
+		require.NoError(t, err)
+		require.Equal(t, "x", string(result))
+
+		chunks, err := sfv.MarshalSplit(nil, 10, opt)
+		require.NoError(t, err)
+		require.Empty(t, chunks)
+
+		var buf bytes.Buffer
+		enc := sfv.NewEncoder(&buf, opt)
+		require.NoError(t, enc.Encode(nil))
+		require.NoError(t, enc.EncodeField("X-Nil", nil))
+		require.Zero(t, buf.Len())
+
+		_, err = sfv.Marshal(nil, opt, sfv.WithEmptyFieldError(true))
+		require.ErrorIs(t, err, sfv.ErrEmptyField)
+	})
 }
 
 func TestMarshalItem(t *testing.T) {
@@ -427,3 +462,975 @@ func TestMarshalDictionary(t *testing.T) {
 		t.Errorf("Marshal() = %q, want %q", string(result), expected)
 	}
 }
+
+func TestEncodeOptions(t *testing.T) {
+	item := sfv.String("foo")
+	require.NoError(t, item.Parameter("a", 1))
+	require.NoError(t, item.Parameter("b", true))
+
+	dict := sfv.NewDictionary()
+	require.NoError(t, dict.Set("zeta", item))
+	require.NoError(t, dict.Set("alpha", sfv.Integer(1)))
+
+	t.Run("default", func(t *testing.T) {
+		result, err := sfv.Marshal(dict)
+		require.NoError(t, err)
+		require.Equal(t, `zeta="foo";a=1;b, alpha=1`, string(result))
+	})
+	t.Run("spaced parameters", func(t *testing.T) {
+		result, err := sfv.Marshal(dict, sfv.WithParameterSpacing(" "))
+		require.NoError(t, err)
+		require.Equal(t, `zeta="foo"; a=1; b, alpha=1`, string(result))
+	})
+	t.Run("parameter spacing", func(t *testing.T) {
+		result, err := sfv.Marshal(dict, sfv.WithParameterSpacing(""))
+		require.NoError(t, err)
+		require.Equal(t, `zeta="foo";a=1;b, alpha=1`, string(result))
+	})
+	t.Run("sorted keys", func(t *testing.T) {
+		result, err := sfv.Marshal(dict, sfv.WithSortedDictionaryKeys(true), sfv.WithParameterSpacing(""))
+		require.NoError(t, err)
+		require.Equal(t, `alpha=1, zeta="foo";a=1;b`, string(result))
+		require.Equal(t, []string{"zeta", "alpha"}, dict.Keys(), "original dictionary should not be modified")
+	})
+	t.Run("encoder", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := sfv.NewEncoder(&buf, sfv.WithSortedDictionaryKeys(true), sfv.WithParameterSpacing(""))
+		require.NoError(t, enc.Encode(dict))
+		require.Equal(t, `alpha=1, zeta="foo";a=1;b`, buf.String())
+	})
+	t.Run("deprecated setter", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := sfv.NewEncoder(&buf)
+		enc.SetParameterSpacing("")
+		require.NoError(t, enc.Encode(item))
+		require.Equal(t, `"foo";a=1;b`, buf.String())
+	})
+}
+
+func TestParameterSpacingPreservesStrings(t *testing.T) {
+	item := sfv.String("a; b;c")
+	require.NoError(t, item.Parameter("p", "x; y"))
+
+	list := &sfv.List{}
+	require.NoError(t, list.Add(item))
+	inner := sfv.NewInnerList()
+	require.NoError(t, inner.Add(item))
+	require.NoError(t, inner.Parameters().Set("q", sfv.BareString(";")))
+	require.NoError(t, list.Add(inner))
+
+	tests := []struct {
+		spacing  string
+		expected string
+	}{
+		{"", `"a; b;c";p="x; y", ("a; b;c";p="x; y");q=";"`},
+		{" ", `"a; b;c"; p="x; y", ("a; b;c"; p="x; y"); q=";"`},
+	}
+	for _, test := range tests {
+		result, err := sfv.Marshal(list, sfv.WithParameterSpacing(test.spacing))
+		require.NoError(t, err)
+		require.Equal(t, test.expected, string(result), "spacing %q", test.spacing)
+	}
+}
+
+func TestMarshalAppend(t *testing.T) {
+	item := sfv.Token("gzip")
+	require.NoError(t, item.Parameter("q", 0.5))
+
+	dict := sfv.NewDictionary()
+	require.NoError(t, dict.Set("a", sfv.Integer(1)))
+	require.NoError(t, dict.Set("b", item))
+
+	buf := []byte("Accept-Encoding: ")
+	buf, err := sfv.MarshalAppend(buf, item)
+	require.NoError(t, err)
+	require.Equal(t, `Accept-Encoding: gzip;q=0.5`, string(buf))
+
+	buf, err = sfv.MarshalAppend(buf[:0], dict, sfv.WithParameterSpacing(" "))
+	require.NoError(t, err)
+	require.Equal(t, `a=1, b=gzip; q=0.5`, string(buf))
+
+	t.Run("errors keep dst", func(t *testing.T) {
+		prefix := []byte("prefix: ")
+		result, err := sfv.MarshalAppend(prefix, []any{1, 1 << 60})
+		require.Error(t, err)
+		require.Equal(t, "prefix: ", string(result))
+
+		result, err = sfv.MarshalAppend(prefix, nil)
+		require.Error(t, err)
+		require.Equal(t, "prefix: ", string(result))
+
+		result, err = sfv.MarshalAppend(prefix, &sfv.List{}, sfv.WithEmptyFieldError(true))
+		require.ErrorIs(t, err, sfv.ErrEmptyField)
+		require.Equal(t, "prefix: ", string(result))
+
+		result, err = sfv.MarshalAppend(prefix, nil, sfv.WithAllowNil(true), sfv.WithEmptyFieldError(true))
+		require.ErrorIs(t, err, sfv.ErrEmptyField)
+		require.Equal(t, "prefix: ", string(result))
+	})
+
+	t.Run("AppendSFV", func(t *testing.T) {
+		values := []sfv.Appender{
+			sfv.BareInteger(-42),
+			sfv.BareDecimal(1.25),
+			sfv.BareString(`say "hi"`),
+			sfv.BareToken("foo/bar"),
+			sfv.BareByteSequence([]byte("hello")),
+			sfv.True(),
+			sfv.BareDate(1659578233),
+			sfv.BareDisplayString("füü"),
+			item,
+			dict,
+			item.Parameters(),
+		}
+		for _, v := range values {
+			expected, err := v.(sfv.Marshaler).MarshalSFV()
+			require.NoError(t, err)
+
+			appended, err := v.AppendSFV([]byte("prefix:"))
+			require.NoError(t, err)
+			require.Equal(t, "prefix:"+string(expected), string(appended))
+		}
+	})
+}
+
+func TestWriteSFV(t *testing.T) {
+	item := sfv.String("foo")
+	require.NoError(t, item.Parameter("a", 1))
+
+	inner := sfv.NewInnerList()
+	require.NoError(t, inner.Add(item))
+	require.NoError(t, inner.Add(sfv.Integer(2)))
+
+	list := &sfv.List{}
+	require.NoError(t, list.Add(item))
+	require.NoError(t, list.Add(inner))
+
+	dict := sfv.NewDictionary()
+	require.NoError(t, dict.Set("x", inner))
+	require.NoError(t, dict.Set("y", sfv.True()))
+
+	tests := []struct {
+		name     string
+		value    interface{ WriteSFV(io.Writer) (int, error) }
+		expected string
+	}{
+		{"item", item, `"foo";a=1`},
+		{"inner list", inner, `("foo";a=1 2)`},
+		{"list", list, `"foo";a=1, ("foo";a=1 2)`},
+		{"dictionary", dict, `x=("foo";a=1 2), y`},
+		{"parameters", item.Parameters(), `;a=1`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			buf.WriteString("> ")
+			n, err := test.value.WriteSFV(&buf)
+			require.NoError(t, err)
+			require.Equal(t, len(test.expected), n)
+			require.Equal(t, "> "+test.expected, buf.String())
+		})
+	}
+}
+
+func TestEncodeField(t *testing.T) {
+	item := sfv.Token("gzip")
+	require.NoError(t, item.Parameter("q", 0.5))
+
+	t.Run("single", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := sfv.NewEncoder(&buf)
+		require.NoError(t, enc.EncodeField("Accept-Encoding", item))
+		require.NoError(t, enc.EncodeField("Priority", map[string]any{"u": 1, "i": true}))
+		require.Equal(t, "Accept-Encoding: gzip;q=0.5\r\nPriority: i, u=1\r\n", buf.String())
+	})
+	t.Run("batch", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := sfv.NewEncoder(&buf, sfv.WithParameterSpacing(" "))
+		require.NoError(t, enc.EncodeFields(
+			sfv.Field{Name: "Accept-Encoding", Value: item},
+			sfv.Field{Name: "X-Count", Value: 3},
+		))
+		require.Equal(t, "Accept-Encoding: gzip; q=0.5\r\nX-Count: 3\r\n", buf.String())
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, name := range []string{"", "Bad Name", "Bad:Name", "Bad\r\nName", "Bäd"} {
+			var buf bytes.Buffer
+			enc := sfv.NewEncoder(&buf)
+			require.Error(t, enc.EncodeField(name, item), "field name %q should be rejected", name)
+			require.Zero(t, buf.Len())
+		}
+
+		var buf bytes.Buffer
+		enc := sfv.NewEncoder(&buf)
+		require.Error(t, enc.EncodeField("X-Nil", nil))
+		require.Error(t, enc.EncodeFields(
+			sfv.Field{Name: "Good", Value: item},
+			sfv.Field{Name: "Bad Name", Value: item},
+		))
+		require.Zero(t, buf.Len(), "nothing should be written when a field fails")
+	})
+}
+
+func TestMarshalTokenValidation(t *testing.T) {
+	valid := []string{"foo", "*", "a1", "foo/bar", "text/html", "x:y", "a!#$%&'*+-.^_`|~"}
+	for _, s := range valid {
+		result, err := sfv.Marshal(sfv.Token(s))
+		require.NoError(t, err, "token %q should be valid", s)
+		require.Equal(t, s, string(result))
+	}
+
+	invalid := []string{"", "not a token!", "1abc", "/foo", "foo,bar", `foo"`, "fü", "foo;bar", "foo=bar"}
+	for _, s := range invalid {
+		_, err := sfv.Marshal(sfv.Token(s))
+		require.Error(t, err, "token %q should be rejected", s)
+
+		// the error surfaces from containers as well
+		list := &sfv.List{}
+		require.NoError(t, list.Add(sfv.Token(s)))
+		_, err = sfv.Marshal(list)
+		require.Error(t, err, "list containing token %q should be rejected", s)
+	}
+}
+
+func TestMarshalStringValidation(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		result, err := sfv.Marshal(sfv.String(` ~printable "ASCII" \ only`))
+		require.NoError(t, err)
+		require.Equal(t, `" ~printable \"ASCII\" \\ only"`, string(result))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, s := range []string{"tab\there", "new\nline", "del\x7f", "füü", "\x00"} {
+			_, err := sfv.Marshal(sfv.String(s))
+			require.Error(t, err, "string %q should be rejected", s)
+		}
+	})
+	t.Run("error message", func(t *testing.T) {
+		_, err := sfv.Marshal(sfv.String("a\tb"))
+		require.ErrorContains(t, err, "invalid byte 0x09 at position 1")
+		require.NotContains(t, err.Error(), "display string", "control characters cannot be fixed with a display string")
+
+		_, err = sfv.Marshal(sfv.String("aé"))
+		require.ErrorContains(t, err, "invalid byte 0xc3 at position 1")
+		require.ErrorContains(t, err, "use a display string")
+	})
+	t.Run("display string fallback", func(t *testing.T) {
+		item := sfv.String("füü")
+		require.NoError(t, item.Parameter("title", "Grüße"))
+
+		result, err := sfv.Marshal(item, sfv.WithDisplayStringFallback(true))
+		require.NoError(t, err)
+		require.Equal(t, `%"f%c3%bc%c3%bc";title=%"Gr%c3%bc%c3%9fe"`, string(result))
+
+		result, err = sfv.Marshal(map[string]any{"a": "ascii", "b": "ü"}, sfv.WithDisplayStringFallback(true))
+		require.NoError(t, err)
+		require.Equal(t, `a="ascii", b=%"%c3%bc"`, string(result))
+
+		_, err = sfv.Marshal(sfv.String("ü\n"), sfv.WithDisplayStringFallback(true))
+		require.NoError(t, err, "control characters are representable in display strings")
+
+		_, err = sfv.Marshal(sfv.String("new\nline"), sfv.WithDisplayStringFallback(true))
+		require.Error(t, err, "ASCII strings with control characters should still be rejected")
+	})
+}
+
+func TestMarshalStringEscaping(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{``, `""`},
+		{`plain`, `"plain"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`back\slash`, `"back\\slash"`},
+		{`\"`, `"\\\""`},
+		{`it's {ok} <really>`, `"it's {ok} <really>"`},
+	}
+	for _, test := range tests {
+		result, err := sfv.Marshal(sfv.String(test.value))
+		require.NoError(t, err)
+		require.Equal(t, test.expected, string(result))
+
+		// the serialization should parse back to the original value
+		item, err := sfv.ParseItem(result)
+		require.NoError(t, err)
+		var s string
+		require.NoError(t, item.GetValue(&s))
+		require.Equal(t, test.value, s)
+	}
+
+	for _, s := range []string{"é", "\r", "\x1b[0m"} {
+		_, err := sfv.Marshal(sfv.String(s))
+		require.Error(t, err, "string %q should not be representable", s)
+	}
+}
+
+func TestMarshalDecimalRounding(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{1.0, "1.0"},
+		{1.5, "1.5"},
+		{0.0625, "0.062"},
+		{0.0635, "0.064"},
+		{0.0015, "0.002"},
+		{0.0025, "0.002"},
+		{0.0005, "0.0"},
+		{1.0055, "1.006"},
+		{0.00051, "0.001"},
+		{0.00025, "0.0"},
+		{9.9995, "10.0"},
+		{999.9995, "1000.0"},
+		{-0.0625, "-0.062"},
+		{-2.0035, "-2.004"},
+		{123456789012.125, "123456789012.125"},
+	}
+	for _, test := range tests {
+		result, err := sfv.Marshal(sfv.Decimal(test.value))
+		require.NoError(t, err)
+		require.Equal(t, test.expected, string(result), "Marshal(%v)", test.value)
+	}
+}
+
+func TestMarshalDecimalRange(t *testing.T) {
+	valid := []float64{999999999999.999, -999999999999.999, 999999999999.9994}
+	for _, f := range valid {
+		_, err := sfv.Marshal(sfv.Decimal(f))
+		require.NoError(t, err, "Marshal(%v) should succeed", f)
+	}
+
+	invalid := []float64{1e12, -1e12, 999999999999.9996, 1e300, math.Inf(1), math.Inf(-1), math.NaN()}
+	for _, f := range invalid {
+		_, err := sfv.Marshal(sfv.Decimal(f))
+		require.Error(t, err, "Marshal(sfv.Decimal(%v)) should fail", f)
+
+		_, err = sfv.Marshal(f)
+		require.Error(t, err, "Marshal(%v) should fail", f)
+
+		var d sfv.DecimalBareItem
+		require.NoError(t, d.SetValue(1.5))
+		require.Error(t, d.SetValue(f), "SetValue(%v) should fail", f)
+		require.Equal(t, 1.5, d.Value(), "failed SetValue should not modify the value")
+	}
+}
+
+func TestMarshalExactDecimal(t *testing.T) {
+	milli := []struct {
+		value    int64
+		expected string
+	}{
+		{0, "0.0"},
+		{100, "0.1"},
+		{1250, "1.25"},
+		{-5, "-0.005"},
+		{999_999_999_999_999, "999999999999.999"},
+		{-999_999_999_999_999, "-999999999999.999"},
+	}
+	for _, test := range milli {
+		result, err := sfv.Marshal(sfv.DecimalFromMilli(test.value))
+		require.NoError(t, err)
+		require.Equal(t, test.expected, string(result), "DecimalFromMilli(%d)", test.value)
+	}
+	require.InDelta(t, 1.25, sfv.BareDecimalFromMilli(1250).Value(), 0)
+
+	for _, m := range []int64{1_000_000_000_000_000, -1_000_000_000_000_000} {
+		_, err := sfv.Marshal(sfv.DecimalFromMilli(m))
+		require.Error(t, err, "DecimalFromMilli(%d) should fail to serialize", m)
+	}
+
+	strs := []struct {
+		value    string
+		expected string
+	}{
+		{"0.1", "0.1"},
+		{"1", "1.0"},
+		{"1.250", "1.25"},
+		{"-0.005", "-0.005"},
+		{"999999999999.999", "999999999999.999"},
+	}
+	for _, test := range strs {
+		item, err := sfv.DecimalFromString(test.value)
+		require.NoError(t, err, "DecimalFromString(%q)", test.value)
+		result, err := sfv.Marshal(item)
+		require.NoError(t, err)
+		require.Equal(t, test.expected, string(result), "DecimalFromString(%q)", test.value)
+	}
+
+	for _, s := range []string{"", "-", ".5", "1.", "1.0005", "1e3", "+1", "1.2.3", "1000000000000.0", "0x10"} {
+		_, err := sfv.DecimalFromString(s)
+		require.Error(t, err, "DecimalFromString(%q) should fail", s)
+	}
+
+	// parsed decimals are serialized exactly as they were written
+	for _, s := range []string{"0.1", "123456789012.345", "-0.001", "4.5"} {
+		item, err := sfv.ParseItemString(s)
+		require.NoError(t, err)
+		require.Equal(t, s, marshalString(t, item))
+	}
+
+	// SetValue replaces an exact value
+	d := sfv.BareDecimalFromMilli(100)
+	require.NoError(t, d.SetValue(2.5))
+	require.Equal(t, "2.5", marshalString(t, d))
+}
+
+func TestIntegerRange(t *testing.T) {
+	const maxInteger = 999_999_999_999_999
+
+	for _, v := range []int64{0, maxInteger, -maxInteger} {
+		_, err := sfv.Marshal(sfv.Integer(v))
+		require.NoError(t, err, "Marshal(%d) should succeed", v)
+
+		bi, err := sfv.BareItemFrom(v)
+		require.NoError(t, err)
+		require.Equal(t, sfv.IntegerType, bi.Type())
+	}
+
+	for _, v := range []int64{maxInteger + 1, -maxInteger - 1, 10_000_000_000_000_000} {
+		_, err := sfv.Marshal(sfv.Integer(v))
+		require.Error(t, err, "Marshal(sfv.Integer(%d)) should fail", v)
+
+		_, err = sfv.BareItemFrom(v)
+		require.Error(t, err, "BareItemFrom(%d) should fail", v)
+
+		item := sfv.Token("foo")
+		require.Error(t, item.Parameter("n", v), "Parameter(%d) should fail", v)
+
+		var i sfv.IntegerBareItem
+		require.NoError(t, i.SetValue(42))
+		require.Error(t, i.SetValue(v), "SetValue(%d) should fail", v)
+		require.Equal(t, int64(42), i.Value(), "failed SetValue should not modify the value")
+	}
+}
+
+func TestSortedDictionaryKeys(t *testing.T) {
+	type header struct {
+		Zeta  int `sfv:"zeta"`
+		Alpha int `sfv:"alpha"`
+		Mid   int `sfv:"mid"`
+	}
+	v := header{Zeta: 1, Alpha: 2, Mid: 3}
+
+	result, err := sfv.Marshal(v)
+	require.NoError(t, err)
+	require.Equal(t, `zeta=1, alpha=2, mid=3`, string(result), "struct fields are written in declaration order by default")
+
+	result, err = sfv.Marshal(v, sfv.WithSortedDictionaryKeys(true))
+	require.NoError(t, err)
+	require.Equal(t, `alpha=2, mid=3, zeta=1`, string(result))
+
+	dict, err := sfv.ParseDictionary([]byte(`b=1, a=(x y);p, c`))
+	require.NoError(t, err)
+	result, err = sfv.Marshal(dict, sfv.WithSortedDictionaryKeys(true))
+	require.NoError(t, err)
+	require.Equal(t, `a=(x y);p, b=1, c`, string(result))
+	require.Equal(t, []string{"b", "a", "c"}, dict.Keys(), "the dictionary should not be modified")
+
+	result, err = sfv.Marshal(dict, sfv.WithSortedDictionaryKeys(false))
+	require.NoError(t, err)
+	require.Equal(t, `b=1, a=(x y);p, c`, string(result))
+}
+
+func TestFieldNaming(t *testing.T) {
+	type header struct {
+		MaxAge     int
+		HTTPStatus int
+		Public     bool
+		Tagged     int `sfv:"x-tagged"`
+	}
+	v := header{MaxAge: 60, HTTPStatus: 200, Public: true, Tagged: 1}
+
+	result, err := sfv.Marshal(v)
+	require.NoError(t, err)
+	require.Equal(t, `maxage=60, httpstatus=200, public, x-tagged=1`, string(result), "field names are lowercased by default")
+
+	result, err = sfv.Marshal(v, sfv.WithFieldNaming(sfv.SnakeCase))
+	require.NoError(t, err)
+	require.Equal(t, `max_age=60, http_status=200, public, x-tagged=1`, string(result))
+
+	var buf bytes.Buffer
+	require.NoError(t, sfv.NewEncoder(&buf, sfv.WithFieldNaming(sfv.KebabCase)).Encode(v))
+	require.Equal(t, `max-age=60, http-status=200, public, x-tagged=1`, buf.String())
+
+	result, err = sfv.Marshal(v, sfv.WithFieldNaming(func(name string) string {
+		return "x-" + sfv.KebabCase(name)
+	}))
+	require.NoError(t, err)
+	require.Equal(t, `x-max-age=60, x-http-status=200, x-public, x-tagged=1`, string(result))
+
+	_, err = sfv.Marshal(v, sfv.WithFieldNaming(func(name string) string { return name }))
+	require.Error(t, err, "keys that are not valid dictionary keys should be rejected")
+
+	for _, tc := range []struct {
+		in, snake, kebab string
+	}{
+		{"MaxAge", "max_age", "max-age"},
+		{"HTTPStatus", "http_status", "http-status"},
+		{"ID", "id", "id"},
+		{"UserID", "user_id", "user-id"},
+		{"TTL2", "ttl2", "ttl2"},
+		{"Version2Name", "version2_name", "version2-name"},
+		{"already_snake", "already_snake", "already_snake"},
+	} {
+		require.Equal(t, tc.snake, sfv.SnakeCase(tc.in), "SnakeCase(%q)", tc.in)
+		require.Equal(t, tc.kebab, sfv.KebabCase(tc.in), "KebabCase(%q)", tc.in)
+	}
+}
+
+func TestStructTagOptions(t *testing.T) {
+	type header struct {
+		Algo     string    `sfv:"algo,token"`
+		Title    string    `sfv:"title,displaystring"`
+		Created  int64     `sfv:"created,date"`
+		Expires  time.Time `sfv:"expires,date"`
+		Methods  []string  `sfv:"methods,innerlist,token"`
+		Sizes    []int     `sfv:"sizes,innerlist"`
+		Note     string    `sfv:"note,omitempty"`
+		Count    int       `sfv:",omitempty"`
+		Fallback *string   `sfv:"fallback,omitempty,token"`
+	}
+
+	v := header{
+		Algo:    "ed25519",
+		Title:   "café",
+		Created: 1700000000,
+		Expires: time.Unix(1800000000, 0),
+		Methods: []string{"GET", "HEAD"},
+		Sizes:   []int{1, 2},
+	}
+	result, err := sfv.Marshal(v)
+	require.NoError(t, err)
+	require.Equal(t, `algo=ed25519, title=%"caf%c3%a9", created=@1700000000, expires=@1800000000, methods=(GET HEAD), sizes=(1 2)`, string(result))
+
+	fallback := "none"
+	v.Note = "hi"
+	v.Count = 3
+	v.Fallback = &fallback
+	result, err = sfv.Marshal(v)
+	require.NoError(t, err)
+	require.Contains(t, string(result), `, note="hi", count=3, fallback=none`)
+
+	t.Run("invalid tags", func(t *testing.T) {
+		testcases := []struct {
+			name  string
+			value any
+		}{
+			{"unknown option", struct {
+				A string `sfv:"a,bogus"`
+			}{"x"}},
+			{"conflicting types", struct {
+				A string `sfv:"a,token,displaystring"`
+			}{"x"}},
+			{"token on integer", struct {
+				A int `sfv:"a,token"`
+			}{1}},
+			{"date on string", struct {
+				A string `sfv:"a,date"`
+			}{"x"}},
+			{"innerlist on scalar", struct {
+				A string `sfv:"a,innerlist"`
+			}{"x"}},
+			{"invalid token", struct {
+				A string `sfv:"a,token"`
+			}{"not a token"}},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := sfv.Marshal(tc.value)
+				require.Error(t, err)
+			})
+		}
+	})
+}
+
+func TestStructParams(t *testing.T) {
+	type cacheParams struct {
+		Hit bool `sfv:"hit,omitempty"`
+		TTL int  `sfv:"ttl,omitempty"`
+		Key string
+	}
+	type cacheStatus struct {
+		Cache  string      `sfv:",token"`
+		Params cacheParams `sfv:",params"`
+	}
+
+	result, err := sfv.Marshal([]cacheStatus{
+		{Cache: "ExampleCache", Params: cacheParams{Hit: true, Key: "a"}},
+		{Cache: "OriginCache", Params: cacheParams{TTL: 30, Key: "b"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `ExampleCache;hit;key="a", OriginCache;ttl=30;key="b"`, string(result))
+
+	result, err = sfv.Marshal(cacheStatus{Cache: "ExampleCache", Params: cacheParams{Hit: true, Key: "a"}})
+	require.NoError(t, err)
+	require.Equal(t, `ExampleCache;hit;key="a"`, string(result), "a struct with params marshals as an item")
+
+	type accept struct {
+		Types  []string          `sfv:",innerlist,token"`
+		Params map[string]string `sfv:",params,omitempty"`
+	}
+	type header struct {
+		Sig    accept `sfv:"sig"`
+		Nonce  accept `sfv:"nonce"`
+		Simple int    `sfv:"simple"`
+	}
+	result, err = sfv.Marshal(header{
+		Sig:    accept{Types: []string{"a", "b"}, Params: map[string]string{"keyid": "x", "alg": "y"}},
+		Nonce:  accept{Types: []string{"c"}},
+		Simple: 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, `sig=(a b);alg="y";keyid="x", nonce=(c), simple=1`, string(result))
+
+	t.Run("invalid", func(t *testing.T) {
+		testcases := []struct {
+			name  string
+			value any
+		}{
+			{"no value field", struct {
+				P map[string]int `sfv:",params"`
+			}{}},
+			{"two value fields", struct {
+				A int
+				B int
+				P map[string]int `sfv:",params"`
+			}{}},
+			{"two params fields", struct {
+				A int
+				P map[string]int `sfv:",params"`
+				Q map[string]int `sfv:",params"`
+			}{}},
+			{"params on scalar", struct {
+				A int
+				P int `sfv:",params"`
+			}{}},
+			{"non bare parameter value", struct {
+				A int
+				P map[string][]int `sfv:",params"`
+			}{A: 1, P: map[string][]int{"x": {1}}}},
+		}
+		for _, tc := range testcases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := sfv.Marshal(tc.value)
+				require.Error(t, err)
+			})
+		}
+	})
+}
+
+func TestStructDuration(t *testing.T) {
+	type cacheStatus struct {
+		TTL     time.Duration   `sfv:"ttl,seconds"`
+		Window  time.Duration   `sfv:"w,decimalseconds"`
+		Retries []time.Duration `sfv:"r,seconds"`
+		Timeout *time.Duration  `sfv:"timeout,omitempty,seconds"`
+	}
+
+	result, err := sfv.Marshal(cacheStatus{
+		TTL:     90 * time.Second,
+		Window:  1500 * time.Millisecond,
+		Retries: []time.Duration{time.Second, time.Minute},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `ttl=90, w=1.5, r=(1 60)`, string(result))
+
+	timeout := -5 * time.Second
+	result, err = sfv.Marshal(cacheStatus{Window: 1234567 * time.Microsecond, Timeout: &timeout})
+	require.NoError(t, err)
+	require.Equal(t, `ttl=0, w=1.235, r=(), timeout=-5`, string(result))
+
+	_, err = sfv.Marshal(cacheStatus{TTL: 1500 * time.Millisecond})
+	require.Error(t, err, "fractional seconds cannot be marshaled as an integer")
+
+	_, err = sfv.Marshal(struct {
+		N int64 `sfv:"n,seconds"`
+	}{N: 1})
+	require.Error(t, err, "seconds option requires a time.Duration")
+
+	_, err = sfv.Marshal(struct {
+		D time.Duration `sfv:"d,seconds,decimalseconds"`
+	}{})
+	require.Error(t, err)
+}
+
+type level int
+
+func (l *level) MarshalText() ([]byte, error) {
+	switch *l {
+	case 0:
+		return []byte("low"), nil
+	case 1:
+		return []byte("high"), nil
+	}
+	return nil, fmt.Errorf("unknown level %d", int(*l))
+}
+
+func TestMarshalTextMarshaler(t *testing.T) {
+	addr := netip.MustParseAddr("192.0.2.1")
+	result, err := sfv.Marshal(addr)
+	require.NoError(t, err)
+	require.Equal(t, `"192.0.2.1"`, string(result))
+
+	lvl := level(1)
+	result, err = sfv.Marshal(&lvl, sfv.WithTextMarshalerAsToken(true))
+	require.NoError(t, err)
+	require.Equal(t, `high`, string(result))
+
+	lvl = level(5)
+	_, err = sfv.Marshal(&lvl)
+	require.Error(t, err, "MarshalText errors should be reported")
+
+	result, err = sfv.Marshal(time.Unix(1700000000, 0), sfv.WithTextMarshalerAsToken(true))
+	require.NoError(t, err)
+	require.Equal(t, `@1700000000`, string(result), "time.Time is always a date")
+
+	type header struct {
+		Addr  netip.Addr `sfv:"addr"`
+		Level *level     `sfv:"level,token"`
+	}
+	lvl = level(0)
+	result, err = sfv.Marshal(header{
+		Addr:  addr,
+		Level: &lvl,
+	})
+	require.NoError(t, err)
+	require.Equal(t, `addr="192.0.2.1", level=low`, string(result))
+}
+
+func TestRegisterMarshaler(t *testing.T) {
+	type uuid [16]byte
+
+	typ := reflect.TypeOf(uuid{})
+	sfv.RegisterMarshaler(typ, func(v any) (sfv.Value, error) {
+		u := v.(uuid) //nolint:forcetypeassert
+		if u == (uuid{}) {
+			return nil, fmt.Errorf("nil uuid")
+		}
+		return sfv.BareToken(fmt.Sprintf("u%x", u[:4])), nil
+	})
+	t.Cleanup(func() { sfv.RegisterMarshaler(typ, nil) })
+
+	id := uuid{0xde, 0xad, 0xbe, 0xef}
+	result, err := sfv.Marshal(id)
+	require.NoError(t, err)
+	require.Equal(t, `udeadbeef`, string(result))
+
+	result, err = sfv.Marshal(&id)
+	require.NoError(t, err)
+	require.Equal(t, `udeadbeef`, string(result), "pointers use the function registered for the element type")
+
+	result, err = sfv.Marshal(struct {
+		ID uuid `sfv:"id"`
+	}{ID: id})
+	require.NoError(t, err)
+	require.Equal(t, `id=udeadbeef`, string(result))
+
+	_, err = sfv.Marshal(uuid{})
+	require.Error(t, err, "errors from the function should be reported")
+
+	sfv.RegisterMarshaler(typ, nil)
+	result, err = sfv.Marshal(id)
+	require.NoError(t, err)
+	require.Equal(t, `:3q2+7wAAAAAAAAAAAAAAAA==:`, string(result), "unregistered types use the default conversion")
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	_, err := sfv.Marshal(struct {
+		A int    `sfv:"a"`
+		B string `sfv:"b,omitempty"`
+	}{A: 1}, sfv.WithLogger(logger))
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), `msg="sfv: converting value"`)
+	require.Contains(t, buf.String(), `msg="sfv: omitting empty struct field" field=B`)
+
+	buf.Reset()
+	_, err = sfv.ParseDictionary([]byte(`a=1, b=(, c=3`), sfv.WithErrorAggregation(true), sfv.WithLogger(logger))
+	require.Error(t, err)
+	require.Contains(t, buf.String(), `msg="sfv: parsing field" type=dictionary length=13`)
+	require.Contains(t, buf.String(), `msg="sfv: skipping member" offset=5`)
+
+	buf.Reset()
+	_, err = sfv.ParseItem([]byte(`1;a=`), sfv.WithLogger(nil))
+	require.Error(t, err, "a nil logger is allowed")
+	require.Empty(t, buf.String())
+}
+
+func TestMarshalSliceValuedMembers(t *testing.T) {
+	result, err := sfv.Marshal(map[string][]string{
+		"accept": {"a", "b"},
+		"empty":  {},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `accept=("a" "b"), empty=()`, string(result))
+
+	result, err = sfv.Marshal(map[string]any{
+		"n":     []int{1, 2},
+		"mixed": []any{"a", 1, true, sfv.Token("t")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `mixed=("a" 1 ?1 t), n=(1 2)`, string(result))
+
+	result, err = sfv.Marshal(struct {
+		Hosts []string   `sfv:"hosts"`
+		Addrs [2]float64 `sfv:"addrs"`
+	}{Hosts: []string{"x"}, Addrs: [2]float64{1.5, 2}})
+	require.NoError(t, err)
+	require.Equal(t, `hosts=("x"), addrs=(1.5 2.0)`, string(result))
+
+	_, err = sfv.Marshal(map[string][][]string{"nested": {{"a"}}})
+	require.Error(t, err, "inner lists cannot be nested")
+}
+
+func TestMarshalMixedValues(t *testing.T) {
+	il := sfv.NewInnerList()
+	require.NoError(t, il.Add(sfv.Token("a")))
+	require.NoError(t, il.Add(sfv.BareInteger(2)))
+
+	item := sfv.Token("t")
+	require.NoError(t, item.Parameter("p", 1))
+
+	result, err := sfv.Marshal([]any{il, 1, "x", item, sfv.BareDecimal(1.5), []any{"n", sfv.Token("m")}, CustomType{value: "v"}})
+	require.NoError(t, err)
+	require.Equal(t, `(a 2), 1, "x", t;p=1, 1.5, ("n" m), custom:v`, string(result))
+
+	result, err = sfv.Marshal([2]any{il, true})
+	require.NoError(t, err)
+	require.Equal(t, `(a 2), ?1`, string(result))
+
+	result, err = sfv.Marshal(map[string]any{"a": il, "b": item, "c": sfv.BareToken("bt"), "d": []any{1, sfv.String("s")}})
+	require.NoError(t, err)
+	require.Equal(t, `a=(a 2), b=t;p=1, c=bt, d=(1 "s")`, string(result))
+
+	_, err = sfv.Marshal([]any{map[string]int{"a": 1}})
+	require.Error(t, err, "dictionaries cannot be list members")
+
+	_, err = sfv.Marshal([]any{[]any{[]any{1}}})
+	require.Error(t, err, "inner lists cannot be nested")
+
+	_, err = sfv.Marshal(map[string]any{"a": CustomType{value: "v"}})
+	require.Error(t, err, "custom marshalers cannot be dictionary members")
+}
+
+func TestIntegerOverflowPolicy(t *testing.T) {
+	type sample struct {
+		Bytes  uint64        `sfv:"bytes"`
+		Delta  int64         `sfv:"delta"`
+		Uptime time.Duration `sfv:"uptime,seconds"`
+	}
+	v := sample{Bytes: math.MaxUint64, Delta: -1_000_000_000_000_000, Uptime: 5 * time.Second}
+
+	_, err := sfv.Marshal(v)
+	require.Error(t, err, "overflowing integers fail by default")
+
+	_, err = sfv.Marshal(v, sfv.WithIntegerOverflow(sfv.IntegerOverflowError))
+	require.Error(t, err)
+
+	result, err := sfv.Marshal(v, sfv.WithIntegerOverflow(sfv.IntegerOverflowClamp))
+	require.NoError(t, err)
+	require.Equal(t, `bytes=999999999999999, delta=-999999999999999, uptime=5`, string(result))
+
+	result, err = sfv.Marshal(v, sfv.WithIntegerOverflow(sfv.IntegerOverflowString))
+	require.NoError(t, err)
+	require.Equal(t, `bytes="18446744073709551615", delta="-1000000000000000", uptime=5`, string(result))
+
+	result, err = sfv.Marshal([]int64{1, math.MaxInt64}, sfv.WithIntegerOverflow(sfv.IntegerOverflowClamp))
+	require.NoError(t, err)
+	require.Equal(t, `1, 999999999999999`, string(result))
+
+	_, err = sfv.Marshal(sfv.BareInteger(math.MaxInt64), sfv.WithIntegerOverflow(sfv.IntegerOverflowClamp))
+	require.Error(t, err, "explicit integer items are not affected")
+}
+
+func TestEmptyField(t *testing.T) {
+	empties := []any{&sfv.List{}, &sfv.Dictionary{}, []string{}, map[string]int{}}
+
+	t.Run("default", func(t *testing.T) {
+		for _, v := range empties {
+			result, err := sfv.Marshal(v)
+			require.NoError(t, err, "%T", v)
+			require.Empty(t, result, "%T", v)
+		}
+
+		var buf bytes.Buffer
+		enc := sfv.NewEncoder(&buf)
+		require.NoError(t, enc.EncodeFields(
+			sfv.Field{Name: "Accept", Value: []string{}},
+			sfv.Field{Name: "X-Count", Value: 3},
+		))
+		require.Equal(t, "X-Count: 3\r\n", buf.String(), "empty fields are omitted")
+	})
+	t.Run("WithEmptyFieldError", func(t *testing.T) {
+		opt := sfv.WithEmptyFieldError(true)
+		for _, v := range empties {
+			_, err := sfv.Marshal(v, opt)
+			require.ErrorIs(t, err, sfv.ErrEmptyField, "%T", v)
+
+			_, err = sfv.MarshalSplit(v, 10, opt)
+			require.ErrorIs(t, err, sfv.ErrEmptyField, "%T", v)
+
+			var buf bytes.Buffer
+			require.ErrorIs(t, sfv.NewEncoder(&buf, opt).EncodeField("Accept", v), sfv.ErrEmptyField, "%T", v)
+			require.Zero(t, buf.Len())
+		}
+
+		result, err := sfv.Marshal(sfv.String(""), opt)
+		require.NoError(t, err, "empty-looking items are not empty fields")
+		require.Equal(t, `""`, string(result))
+	})
+}
+
+func TestStrictConstructors(t *testing.T) {
+	tok, err := sfv.TokenStrict("foo/bar")
+	require.NoError(t, err)
+	require.Equal(t, "foo/bar", marshalString(t, tok))
+	for _, s := range []string{"", "1a", "a b", "é"} {
+		_, err := sfv.TokenStrict(s)
+		require.Error(t, err, "TokenStrict(%q) should fail", s)
+	}
+
+	str, err := sfv.StringStrict(`say "hi"`)
+	require.NoError(t, err)
+	require.Equal(t, `"say \"hi\""`, marshalString(t, str))
+	for _, s := range []string{"tab\there", "café", "\x7f"} {
+		_, err := sfv.StringStrict(s)
+		require.Error(t, err, "StringStrict(%q) should fail", s)
+	}
+
+	ds, err := sfv.DisplayStringStrict("café")
+	require.NoError(t, err)
+	require.Equal(t, `%"caf%c3%a9"`, marshalString(t, ds))
+	_, err = sfv.DisplayStringStrict("caf\xe9")
+	require.Error(t, err)
+
+	n, err := sfv.IntegerStrict(-999_999_999_999_999)
+	require.NoError(t, err)
+	require.Equal(t, "-999999999999999", marshalString(t, n))
+	_, err = sfv.IntegerStrict(1_000_000_000_000_000)
+	require.Error(t, err)
+
+	d, err := sfv.DecimalStrict(0.25)
+	require.NoError(t, err)
+	require.Equal(t, "0.25", marshalString(t, d))
+	for _, f := range []float64{1e12, math.NaN(), math.Inf(1)} {
+		_, err := sfv.DecimalStrict(f)
+		require.Error(t, err, "DecimalStrict(%v) should fail", f)
+	}
+
+	date, err := sfv.DateStrict(1659578233)
+	require.NoError(t, err)
+	require.Equal(t, "@1659578233", marshalString(t, date))
+	_, err = sfv.DateStrict(-1_000_000_000_000_000)
+	require.Error(t, err)
+
+	key, err := sfv.KeyStrict("*a.b-c_d")
+	require.NoError(t, err)
+	require.Equal(t, "*a.b-c_d", key)
+	for _, s := range []string{"", "A", "1a", "a b"} {
+		_, err := sfv.KeyStrict(s)
+		require.Error(t, err, "KeyStrict(%q) should fail", s)
+	}
+}
@@ -0,0 +1,43 @@
+package sfv
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SnakeCase converts a Go identifier such as "MaxAge" to snake case, as in
+// "max_age". Runs of uppercase letters are treated as a single word, so
+// that "HTTPStatus" becomes "http_status". It can be passed to
+// WithFieldNaming.
+func SnakeCase(name string) string {
+	return splitWords(name, '_')
+}
+
+// KebabCase converts a Go identifier such as "MaxAge" to kebab case, as in
+// "max-age". Runs of uppercase letters are treated as a single word, so
+// that "HTTPStatus" becomes "http-status". It can be passed to
+// WithFieldNaming.
+func KebabCase(name string) string {
+	return splitWords(name, '-')
+}
+
+// splitWords lowercases name, inserting sep at each word boundary
+func splitWords(name string, sep byte) string {
+	var sb strings.Builder
+	sb.Grow(len(name) + 4)
+
+	var prev rune
+	for i, r := range name {
+		if unicode.IsUpper(r) && i > 0 {
+			next, _ := utf8.DecodeRuneInString(name[i+utf8.RuneLen(r):])
+			// "aB" and "1B" start a new word, and so does "Bc" in "ABc"
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && unicode.IsLower(next)) {
+				sb.WriteByte(sep)
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+		prev = r
+	}
+	return sb.String()
+}
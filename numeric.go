@@ -2,8 +2,14 @@ package sfv
 
 import (
 	"bytes"
+	"fmt"
+	"math"
+	"reflect"
 	"strconv"
 	"strings"
+
+	"github.com/lestrrat-go/blackmagic"
+	"github.com/lestrrat-go/sfv/internal/tokens"
 )
 
 // DecimalItem represents a decimal value,
@@ -20,6 +26,14 @@ var _ Item = (*DecimalItem)(nil)
 // (e.g. dictionary values).
 type DecimalBareItem struct {
 	uvalue[float64]
+
+	// milli holds the value multiplied by 1000 if exact is true, which
+	// is the case for decimals that were parsed, or created with
+	// BareDecimalFromMilli or BareDecimalFromString. Exact decimals are
+	// serialized from milli, so that they are not subject to rounding
+	// errors. value always holds the closest float64 to the decimal.
+	milli int64
+	exact bool
 }
 
 var _ BareItem = (*DecimalBareItem)(nil)
@@ -49,10 +63,130 @@ func (d *DecimalBareItem) toItem() *DecimalItem {
 // If you need a full decimal item (with parameters), use Decimal() instead.
 func BareDecimal(f float64) *DecimalBareItem {
 	var v DecimalBareItem
-	_ = v.SetValue(f)
+	// not using SetValue, so that invalid values are reported when
+	// the item is marshaled, instead of being silently dropped
+	v.value = f
 	return &v
 }
 
+// DecimalFromMilli creates a new Decimal (DecimalItem) whose value is
+// m thousandths, so that DecimalFromMilli(100) represents 0.1 exactly.
+// As for Decimal, the value is only validated when the item is
+// marshaled.
+//
+// If you need a bare decimal item, use BareDecimalFromMilli() instead.
+func DecimalFromMilli(m int64) *DecimalItem {
+	return BareDecimalFromMilli(m).toItem()
+}
+
+// BareDecimalFromMilli creates a new DecimalBareItem whose value is m
+// thousandths. Unlike decimals created from a float64, the value is
+// stored as is, and serialized without rounding. Values whose integer
+// component has more than 12 digits, that is when m is not between
+// -999999999999999 and 999999999999999, fail to serialize.
+//
+// If you need a full decimal item (with parameters), use DecimalFromMilli()
+// instead.
+func BareDecimalFromMilli(m int64) *DecimalBareItem {
+	return &DecimalBareItem{
+		uvalue: uvalue[float64]{value: float64(m) / 1000},
+		milli:  m,
+		exact:  true,
+	}
+}
+
+// DecimalFromString creates a new Decimal (DecimalItem) from its
+// decimal representation, such as "0.1" or "-12.345". See
+// BareDecimalFromString for the accepted syntax.
+//
+// If you need a bare decimal item, use BareDecimalFromString() instead.
+func DecimalFromString(s string) (*DecimalItem, error) {
+	d, err := BareDecimalFromString(s)
+	if err != nil {
+		return nil, err
+	}
+	return d.toItem(), nil
+}
+
+// BareDecimalFromString creates a new DecimalBareItem from its decimal
+// representation, which is stored exactly, as by BareDecimalFromMilli.
+// s is made of an optional minus sign, 1 to 12 integer digits, and
+// optionally a period followed by 1 to 3 fractional digits, so that both
+// "1" and "1.250" are accepted. Values that would need rounding, such as
+// "0.0005", are rejected.
+//
+// If you need a full decimal item (with parameters), use
+// DecimalFromString() instead.
+func BareDecimalFromString(s string) (*DecimalBareItem, error) {
+	digits := s
+	negative := strings.HasPrefix(digits, "-")
+	if negative {
+		digits = digits[1:]
+	}
+
+	intPart, fracPart, hasPeriod := strings.Cut(digits, ".")
+	switch {
+	case len(intPart) == 0 || len(intPart) > maxDecimalIntegerDigits:
+		return nil, fmt.Errorf("sfv: invalid decimal %q: expected 1 to %d integer digits", s, maxDecimalIntegerDigits)
+	case hasPeriod && (len(fracPart) == 0 || len(fracPart) > maxDecimalFractionDigits):
+		return nil, fmt.Errorf("sfv: invalid decimal %q: expected 1 to %d fractional digits", s, maxDecimalFractionDigits)
+	}
+	for _, c := range []byte(intPart + fracPart) {
+		if !isDigit(c) {
+			return nil, fmt.Errorf("sfv: invalid decimal %q: unexpected character %q", s, c)
+		}
+	}
+
+	m := milliFromDigits(intPart, fracPart)
+	if negative {
+		m = -m
+	}
+	return BareDecimalFromMilli(m), nil
+}
+
+// milliFromDigits returns the number of thousandths represented by the
+// given integer and fractional digits, which must have been validated
+func milliFromDigits[T string | []byte](intPart, fracPart T) int64 {
+	var m int64
+	for i := range len(intPart) {
+		m = m*10 + int64(intPart[i]-'0')
+	}
+	for i := range maxDecimalFractionDigits {
+		m *= 10
+		if i < len(fracPart) {
+			m += int64(fracPart[i] - '0')
+		}
+	}
+	return m
+}
+
+// DecimalStrict creates a new DecimalBareItem with the given value, like
+// BareDecimal, but returns an error if f cannot be serialized as a
+// Decimal, instead of when the item is marshaled. See SetValue for the
+// values that are rejected.
+func DecimalStrict(f float64) (*DecimalBareItem, error) {
+	var d DecimalBareItem
+	if err := d.SetValue(f); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// SetValue sets the value of the DecimalBareItem. It returns an error,
+// and leaves the item unchanged, if f cannot be serialized as a Decimal:
+// NaN, infinities, and values with more than 12 digits in their integer
+// component after rounding to three fractional digits are rejected.
+func (d *DecimalBareItem) SetValue(f float64) error {
+	var buf [32]byte
+	if _, err := appendDecimal(buf[:0], f); err != nil {
+		return err
+	}
+	d.value = f
+	d.milli = 0
+	d.exact = false
+	return nil
+}
+
 // ToItem converts the DecimalBareItem to a full Item.
 func (d *DecimalBareItem) ToItem() Item {
 	return d.toItem()
@@ -60,27 +194,165 @@ func (d *DecimalBareItem) ToItem() Item {
 
 // MarshalSFV implements the Marshaler interface for DecimalBareItem.
 func (d DecimalBareItem) MarshalSFV() ([]byte, error) {
-	var buf bytes.Buffer
+	return d.AppendSFV(nil)
+}
 
-	// Format with up to 3 decimal places, removing trailing zeros
-	str := strconv.FormatFloat(d.value, 'f', 3, 64)
-	str = strings.TrimRight(str, "0")
-	if str[len(str)-1] == '.' {
-		// If the last character is a dot, we need to add a zero
-		// to avoid an invalid format
-		str += "0"
+// AppendSFV appends the serialization of the DecimalBareItem to dst.
+func (d DecimalBareItem) AppendSFV(dst []byte) ([]byte, error) {
+	if d.exact {
+		return appendMilliDecimal(dst, d.milli)
 	}
-	buf.WriteString(str)
-	return buf.Bytes(), nil
+	return appendDecimal(dst, d.value)
+}
+
+// maxDecimalMilli is the largest number of thousandths that can be
+// serialized as a Decimal, which has at most 12 integer digits
+const maxDecimalMilli = 999_999_999_999_999
+
+// appendMilliDecimal serializes m thousandths as a Decimal. No rounding is
+// needed, as the value has exactly three fractional digits, of which
+// trailing zeros are removed, leaving at least one.
+func appendMilliDecimal(dst []byte, m int64) ([]byte, error) {
+	if m < -maxDecimalMilli || m > maxDecimalMilli {
+		return nil, fmt.Errorf("sfv: decimal %d/1000 has more than %d digits in its integer component", m, maxDecimalIntegerDigits)
+	}
+	if m < 0 {
+		dst = append(dst, tokens.Dash)
+		m = -m
+	}
+	dst = strconv.AppendInt(dst, m/1000, 10)
+	dst = append(dst, tokens.Period)
+
+	frac := m % 1000
+	dst = append(dst, byte('0'+frac/100))
+	if frac%100 != 0 {
+		dst = append(dst, byte('0'+frac/10%10))
+		if frac%10 != 0 {
+			dst = append(dst, byte('0'+frac%10))
+		}
+	}
+	return dst, nil
+}
+
+// maxDecimalFractionDigits is the number of fractional digits a Decimal
+// is serialized with, at most
+const maxDecimalFractionDigits = 3
+
+// appendDecimal implements the Decimal serialization algorithm from
+// RFC 9651 Section 4.1.5. The value is rounded to three fractional digits
+// using round-half-to-even, and trailing zeros are removed, leaving at
+// least one fractional digit. Values whose integer component has more than
+// 12 digits after rounding cannot be serialized.
+//
+// Rounding is done on the shortest decimal representation of f, the one
+// that a user would write in source code, rather than on its exact binary
+// value. Otherwise ties such as 1.0055 and 0.0005, whose binary values are
+// slightly below and slightly above the tie, would round by accident.
+func appendDecimal(dst []byte, f float64) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("sfv: cannot serialize %v as a decimal", f)
+	}
+
+	original := f
+	if f < 0 {
+		dst = append(dst, tokens.Dash)
+		f = -f
+	}
+
+	// carried is true if rounding up added a leading "1" to the digits
+	var carried bool
+
+	var buf [32]byte
+	digits := strconv.AppendFloat(buf[:0], f, 'f', -1, 64)
+	intPart, fracPart, _ := bytes.Cut(digits, []byte{tokens.Period})
+
+	if len(fracPart) > maxDecimalFractionDigits {
+		next := fracPart[maxDecimalFractionDigits]
+		rest := fracPart[maxDecimalFractionDigits+1:]
+		fracPart = fracPart[:maxDecimalFractionDigits]
+
+		tie := next == '5' && len(bytes.TrimRight(rest, "0")) == 0
+		odd := (fracPart[maxDecimalFractionDigits-1]-'0')%2 == 1
+		if next > '5' || (next == '5' && (!tie || odd)) {
+			// intPart and fracPart are contiguous in digits, apart from
+			// the period between them, so carry across both
+			intPart, fracPart, carried = roundUpDigits(intPart, fracPart)
+		}
+	}
+
+	if len(intPart) > maxDecimalIntegerDigits || (carried && len(intPart) == maxDecimalIntegerDigits) {
+		return nil, fmt.Errorf("sfv: decimal %v has more than %d digits in its integer component", original, maxDecimalIntegerDigits)
+	}
+	if carried {
+		dst = append(dst, '1')
+	}
+
+	dst = append(dst, intPart...)
+	dst = append(dst, tokens.Period)
+	fracPart = bytes.TrimRight(fracPart, "0")
+	if len(fracPart) == 0 {
+		// at least one fractional digit is required
+		return append(dst, '0'), nil
+	}
+	return append(dst, fracPart...), nil
+}
+
+// roundUpDigits adds one to the last digit of the number formed by the
+// digits in intPart followed by fracPart, in place. carry is true if the
+// number overflowed, in which case a leading "1" must be added.
+func roundUpDigits(intPart, fracPart []byte) ([]byte, []byte, bool) {
+	for _, part := range [][]byte{fracPart, intPart} {
+		for i := len(part) - 1; i >= 0; i-- {
+			if part[i] != '9' {
+				part[i]++
+				return intPart, fracPart, false
+			}
+			part[i] = '0'
+		}
+	}
+	return intPart, fracPart, true
+}
+
+// GetValue assigns the value of the DecimalBareItem to dst. In addition
+// to float64, dst may point to a float32, or to any other type whose
+// underlying type is a floating-point type.
+func (d DecimalBareItem) GetValue(dst any) error {
+	switch dst := dst.(type) {
+	case *float64:
+		*dst = d.value
+		return nil
+	case *float32:
+		*dst = float32(d.value)
+		return nil
+	}
+
+	if rv := reflect.ValueOf(dst); rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		switch elem := rv.Elem(); elem.Kind() {
+		case reflect.Float32, reflect.Float64:
+			if elem.OverflowFloat(d.value) {
+				return fmt.Errorf("sfv: decimal %v overflows %s", d.value, elem.Type())
+			}
+			elem.SetFloat(d.value)
+			return nil
+		default:
+		}
+	}
+	return blackmagic.AssignIfCompatible(dst, d.value)
 }
 
 // Type returns the type of the DecimalBareItem, useful when
 // you have a list of BareItems and need to know the type
 // of each item.
-func (d DecimalBareItem) Type() int {
+func (d DecimalBareItem) Type() Type {
 	return DecimalType
 }
 
+// GoString returns Go-like notation for the DecimalBareItem, which is
+// used by the %#v verb.
+func (d DecimalBareItem) GoString() string {
+	return "sfv.BareDecimal(" + strconv.FormatFloat(d.value, 'g', -1, 64) + ")"
+}
+
 // IntegerItem represents an integer value,
 // with optional parameters.
 //
@@ -102,7 +374,9 @@ var _ BareItem = (*IntegerBareItem)(nil)
 // Integer creates a new Integer (IntegerItem) with the
 // given int64 value. This function does NOT validate the value
 // to ensure it is a valid integer (Validation only happens
-// when the item is marshaled/parsed).
+// when the item is marshaled/parsed). For values that are not
+// known to be in range, use IntegerStrict instead, and convert
+// the result with ToItem.
 //
 // If you need a bare integer item, use BareInteger() instead.
 func Integer(i int64) *IntegerItem {
@@ -119,15 +393,44 @@ func (i *IntegerBareItem) toItem() *IntegerItem {
 // BareInteger creates a new IntegerBareItem with the given int64 value.
 // This function does NOT validate the value to ensure it is a
 // valid integer (Validation only happens when the item is
-// marshaled/parsed).
+// marshaled/parsed). For values that are not known to be in
+// range, use IntegerStrict instead, which fails right away.
 //
 // If you need a full integer item (with parameters), use Integer() instead.
 func BareInteger(i int64) *IntegerBareItem {
 	var v IntegerBareItem
-	_ = v.SetValue(i)
+	// not using SetValue, so that invalid values are reported when
+	// the item is marshaled, instead of being silently dropped
+	v.value = i
 	return &v
 }
 
+// IntegerStrict creates a new IntegerBareItem with the given value, like
+// BareInteger, but returns an error if i is outside of the range allowed
+// by RFC 9651, instead of when the item is marshaled.
+func IntegerStrict(i int64) (*IntegerBareItem, error) {
+	return newCheckedInteger(i)
+}
+
+// SetValue sets the value of the IntegerBareItem. It returns an error,
+// and leaves the item unchanged, if i is outside of the range allowed
+// by RFC 9651, which is -999,999,999,999,999 to 999,999,999,999,999.
+func (i *IntegerBareItem) SetValue(v int64) error {
+	if err := validateInteger(v); err != nil {
+		return err
+	}
+	i.value = v
+	return nil
+}
+
+// validateInteger checks that v can be serialized as an Integer
+func validateInteger(v int64) error {
+	if v > maxSFVInteger || v < -maxSFVInteger {
+		return fmt.Errorf("sfv: integer %d out of range (max %d decimal digits)", v, maxIntegerDigits)
+	}
+	return nil
+}
+
 // ToItem converts the IntegerBareItem to a full Item.
 func (i *IntegerBareItem) ToItem() Item {
 	return i.toItem()
@@ -135,14 +438,77 @@ func (i *IntegerBareItem) ToItem() Item {
 
 // MarshalSFV implements the Marshaler interface for IntegerBareItem.
 func (i IntegerBareItem) MarshalSFV() ([]byte, error) {
-	var buf bytes.Buffer
-	buf.WriteString(strconv.FormatInt(i.value, 10))
-	return buf.Bytes(), nil
+	return i.AppendSFV(nil)
+}
+
+// AppendSFV appends the serialization of the IntegerBareItem to dst.
+// It returns an error if the value is out of the range allowed for
+// Integers.
+func (i IntegerBareItem) AppendSFV(dst []byte) ([]byte, error) {
+	if err := validateInteger(i.value); err != nil {
+		return nil, err
+	}
+	return strconv.AppendInt(dst, i.value, 10), nil
+}
+
+// GetValue assigns the value of the IntegerBareItem to dst. In addition
+// to int64, dst may point to any other integer type, such as int, int32,
+// or uint64, or to a type whose underlying type is an integer type. An
+// error is returned if the value does not fit in the destination type.
+func (i IntegerBareItem) GetValue(dst any) error {
+	return assignInteger(dst, i.value)
+}
+
+// assignInteger assigns v to the integer pointed to by dst, failing if
+// it overflows. Other destinations are handled by blackmagic.
+func assignInteger(dst any, v int64) error {
+	switch dst := dst.(type) {
+	case *int64:
+		*dst = v
+		return nil
+	case *int:
+		if int64(int(v)) != v {
+			return fmt.Errorf("sfv: integer %d overflows int", v)
+		}
+		*dst = int(v)
+		return nil
+	}
+
+	if rv := reflect.ValueOf(dst); rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		switch elem := rv.Elem(); elem.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if elem.OverflowInt(v) {
+				return fmt.Errorf("sfv: integer %d overflows %s", v, elem.Type())
+			}
+			elem.SetInt(v)
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if v < 0 || elem.OverflowUint(uint64(v)) {
+				return fmt.Errorf("sfv: integer %d overflows %s", v, elem.Type())
+			}
+			elem.SetUint(uint64(v))
+			return nil
+		default:
+		}
+	}
+	return blackmagic.AssignIfCompatible(dst, v)
 }
 
 // Type returns the type of the IntegerBareItem, useful when
 // you have a list of BareItems and need to know the type
 // of each item.
-func (i IntegerBareItem) Type() int {
+func (i IntegerBareItem) Type() Type {
 	return IntegerType
 }
+
+// AsInt64 returns the value of the IntegerBareItem. The second return
+// value is always true.
+func (i IntegerBareItem) AsInt64() (int64, bool) {
+	return i.value, true
+}
+
+// GoString returns Go-like notation for the IntegerBareItem, which is
+// used by the %#v verb.
+func (i IntegerBareItem) GoString() string {
+	return "sfv.BareInteger(" + strconv.FormatInt(i.value, 10) + ")"
+}
@@ -0,0 +1,307 @@
+package sfv
+
+import "log/slog"
+
+// Option is the base interface for all options that can be passed to
+// the functions in this package. Each option carries an identifier,
+// which is used to tell options apart, and a value.
+type Option interface {
+	Ident() any
+	Value() any
+}
+
+type option struct {
+	ident any
+	value any
+}
+
+func newOption(ident, value any) *option {
+	return &option{ident: ident, value: value}
+}
+
+func (o *option) Ident() any {
+	return o.ident
+}
+
+func (o *option) Value() any {
+	return o.value
+}
+
+// ParseOption is an option that can be passed to the parsing functions
+// such as Parse, ParseItem, and ParseDictionary.
+type ParseOption interface {
+	Option
+	parseOption()
+}
+
+type parseOption struct {
+	Option
+}
+
+func (*parseOption) parseOption() {}
+
+type identStrictByteSequence struct{}
+
+// WithStrictByteSequence specifies whether byte sequences should be
+// decoded strictly. By default the parser follows the recommendation
+// in RFC 9651 Section 4.2.7 and tolerates base64 data that is missing
+// its "=" padding, or that carries non-zero pad bits. When strict mode
+// is enabled, such inputs are rejected.
+func WithStrictByteSequence(v bool) ParseOption {
+	return &parseOption{newOption(identStrictByteSequence{}, v)}
+}
+
+type identLenientDisplayString struct{}
+
+// WithLenientDisplayString specifies whether display strings should be
+// parsed leniently. By default the parser follows RFC 9651 Section 4.2.10,
+// and fails when a percent-encoded byte uses uppercase hex digits, or when
+// the percent-decoded content is not a valid UTF-8 sequence. When lenient
+// mode is enabled, uppercase hex digits are allowed, and the decoded bytes
+// are accepted as-is.
+func WithLenientDisplayString(v bool) ParseOption {
+	return &parseOption{newOption(identLenientDisplayString{}, v)}
+}
+
+type identEagerByteSequence struct{}
+
+// WithEagerByteSequence specifies whether byte sequences should be
+// base64-decoded while parsing. By default the parser only validates
+// the encoded data, and defers decoding until the value is first
+// requested, which saves work for large values such as signatures
+// that the caller may never look at.
+func WithEagerByteSequence(v bool) ParseOption {
+	return &parseOption{newOption(identEagerByteSequence{}, v)}
+}
+
+type identInternTable struct{}
+
+// WithInternTable specifies an InternTable to use for keys and tokens
+// found while parsing. The same table may be shared by any number of
+// concurrent parses. By default, no interning takes place.
+func WithInternTable(t *InternTable) ParseOption {
+	return &parseOption{newOption(identInternTable{}, t)}
+}
+
+type identErrorAggregation struct{}
+
+// WithErrorAggregation specifies whether the parser should keep going
+// after a member of a List or a Dictionary fails to parse. By default,
+// parsing stops at the first error.
+//
+// When enabled, each failing member is skipped, and the errors are
+// combined into a single error using errors.Join. In this case the
+// parsing functions return both the value built from the members that
+// were parsed successfully, and the combined error. Errors that are not
+// specific to a member, such as a malformed Item field, still stop
+// parsing immediately.
+func WithErrorAggregation(v bool) ParseOption {
+	return &parseOption{newOption(identErrorAggregation{}, v)}
+}
+
+type identRawText struct{}
+
+// WithRawText specifies whether the parser should record the exact input
+// text of each Item, Inner List, and Dictionary member it parses, as well
+// as of the whole List or Dictionary. The recorded text can be retrieved
+// with Item.Raw, InnerList.Raw, Dictionary.RawMember, List.Raw, and
+// Dictionary.Raw. This is useful for protocols such as HTTP Message
+// Signatures, which operate on the text as it was received rather than
+// on a re-serialization of it. By default, no text is recorded.
+func WithRawText(v bool) ParseOption {
+	return &parseOption{newOption(identRawText{}, v)}
+}
+
+type identSpans struct{}
+
+// WithSpans specifies whether the parser should record the location of
+// each Item, Inner List, Dictionary member, and Parameter it parses, as a
+// Span of byte offsets into the input. The locations can be retrieved with
+// Item.Span, InnerList.Span, Dictionary.MemberSpan, and Parameters.Span.
+// This is useful for tools that need to point back at the original input,
+// such as linters and error reporters. By default, no locations are recorded.
+func WithSpans(v bool) ParseOption {
+	return &parseOption{newOption(identSpans{}, v)}
+}
+
+type identObsFold struct{}
+
+// WithObsFold specifies whether the parser should accept field values that
+// were folded over multiple lines, as some old clients still do. When
+// enabled, each line break followed by whitespace (obs-fold, see RFC 9112
+// Section 5.2) is replaced with a single SP before parsing. In this case
+// the offsets reported by WithSpans refer to the unfolded input, and the
+// text reported by WithRawText is unfolded as well.
+//
+// By default, folded values are parsed as is, and usually fail to parse.
+func WithObsFold(v bool) ParseOption {
+	return &parseOption{newOption(identObsFold{}, v)}
+}
+
+// EncodeOption is an option that can be passed to NewEncoder and Marshal.
+type EncodeOption interface {
+	Option
+	encodeOption()
+}
+
+type encodeOption struct {
+	Option
+}
+
+func (*encodeOption) encodeOption() {}
+
+type identParameterSpacing struct{}
+
+// WithParameterSpacing specifies the spacing written after the semicolon
+// that precedes each parameter. The default is "", which produces the
+// serialization specified by RFC 9651, as in "item;key=value". Use " "
+// to produce "item; key=value" instead.
+func WithParameterSpacing(spacing string) EncodeOption {
+	return &encodeOption{newOption(identParameterSpacing{}, spacing)}
+}
+
+type identDisplayStringFallback struct{}
+
+// WithDisplayStringFallback specifies whether Strings that contain
+// non-ASCII characters should be serialized as Display Strings instead.
+// By default such Strings fail to serialize, as RFC 9651 only allows
+// printable ASCII characters in them. Strings made of ASCII characters
+// only are always serialized as Strings, and still fail to serialize if
+// they contain control characters.
+func WithDisplayStringFallback(v bool) EncodeOption {
+	return &encodeOption{newOption(identDisplayStringFallback{}, v)}
+}
+
+type identSortedDictionaryKeys struct{}
+
+// WithSortedDictionaryKeys specifies whether Dictionary members should be
+// written in lexicographic order of their keys, instead of in insertion
+// order. The order is only changed in the output; the Dictionary itself
+// is not modified. This is useful when the output must not depend on how
+// the Dictionary was built, such as for cache keys, signing contexts, or
+// logs that are compared across runs. Note that Go maps are always
+// serialized in sorted order, regardless of this option.
+func WithSortedDictionaryKeys(v bool) EncodeOption {
+	return &encodeOption{newOption(identSortedDictionaryKeys{}, v)}
+}
+
+type identFieldNaming struct{}
+
+// WithFieldNaming specifies how the names of struct fields are converted
+// to Dictionary keys when a struct is marshaled. Fields whose `sfv` tag
+// specifies a name always use that name instead. By default the field
+// name is lowercased, so that "MaxAge" becomes "maxage". Use SnakeCase or
+// KebabCase to produce "max_age" or "max-age", or pass a function of your
+// own. Keys that are not valid Dictionary keys cause marshaling to fail.
+func WithFieldNaming(fn func(string) string) EncodeOption {
+	return &encodeOption{newOption(identFieldNaming{}, fn)}
+}
+
+type identTextMarshalerAsToken struct{}
+
+// WithTextMarshalerAsToken specifies whether values that implement
+// encoding.TextMarshaler should be marshaled as Tokens instead of Strings.
+// By default, such values are marshaled as Strings holding their text.
+// Values that implement Marshaler are not affected, and neither is
+// time.Time, which is always marshaled as a Date.
+func WithTextMarshalerAsToken(v bool) EncodeOption {
+	return &encodeOption{newOption(identTextMarshalerAsToken{}, v)}
+}
+
+type identEmptyFieldError struct{}
+
+// WithEmptyFieldError specifies whether encoding an empty List or
+// Dictionary should fail with ErrEmptyField. RFC 9651 Section 4.1
+// specifies that fields without members must be omitted entirely, but by
+// default Marshal and Encoder.Encode produce an empty value, which is
+// indistinguishable from a value that is legitimately empty. Enabling
+// this option lets callers tell the two cases apart using errors.Is.
+//
+// Encoder.EncodeField, Encoder.EncodeFields, SetHeader, and AddHeader
+// always omit empty fields, unless this option is enabled, in which case
+// they fail as well.
+func WithEmptyFieldError(v bool) EncodeOption {
+	return &encodeOption{newOption(identEmptyFieldError{}, v)}
+}
+
+type identAllowNil struct{}
+
+// WithAllowNil specifies whether a nil value should be accepted by the
+// encoding functions. By default, encoding nil fails, as it is usually
+// the sign of a bug. When enabled, nil is treated as a field with no
+// members: Marshal returns no bytes, Encoder.Encode writes nothing,
+// Encoder.EncodeField omits the field, and SetHeader removes it. If
+// WithEmptyFieldError is enabled as well, nil fails with ErrEmptyField.
+func WithAllowNil(v bool) EncodeOption {
+	return &encodeOption{newOption(identAllowNil{}, v)}
+}
+
+// ParseEncodeOption is an option that can be passed to both the parsing
+// functions and the encoding functions.
+type ParseEncodeOption interface {
+	ParseOption
+	EncodeOption
+}
+
+type parseEncodeOption struct {
+	Option
+}
+
+func (*parseEncodeOption) parseOption()  {}
+func (*parseEncodeOption) encodeOption() {}
+
+type identLogger struct{}
+
+// WithLogger specifies a logger that receives debug messages describing
+// what the parser or the encoder is doing, such as the members skipped
+// by WithErrorAggregation, or how each Go value is converted by Marshal.
+// Messages are logged at slog.LevelDebug, so the logger's handler must be
+// enabled for that level. This is meant for debugging, and is not free:
+// do not enable it in hot paths. By default, nothing is logged.
+func WithLogger(l *slog.Logger) ParseEncodeOption {
+	return &parseEncodeOption{newOption(identLogger{}, l)}
+}
+
+// IntegerOverflowPolicy specifies what Marshal does with Go integers that
+// are out of the range allowed for Integers by RFC 9651, which is at most
+// 15 decimal digits. See WithIntegerOverflow.
+type IntegerOverflowPolicy int
+
+const (
+	// IntegerOverflowError fails marshaling. This is the default.
+	IntegerOverflowError IntegerOverflowPolicy = iota
+	// IntegerOverflowClamp replaces the value with the largest, or the
+	// smallest, Integer allowed, that is 999999999999999 or
+	// -999999999999999.
+	IntegerOverflowClamp
+	// IntegerOverflowString marshals the decimal representation of the
+	// value as a String, as in "1000000000000000".
+	IntegerOverflowString
+)
+
+type identIntegerOverflow struct{}
+
+// WithIntegerOverflow specifies how Go integers that do not fit in an
+// Integer are marshaled. By default marshaling fails, which causes the
+// whole field to be lost. Producers such as telemetry exporters may
+// prefer a degraded value instead. Note that such values do not fit in a
+// Decimal either, as Decimals are limited to 12 integer digits.
+//
+// The policy only applies to Go integers converted by Marshal and
+// Encoder. Integer items created explicitly, for example with
+// BareInteger, always fail to serialize if they are out of range.
+func WithIntegerOverflow(policy IntegerOverflowPolicy) EncodeOption {
+	return &encodeOption{newOption(identIntegerOverflow{}, policy)}
+}
+
+type identMaxFieldLength struct{}
+
+// WithMaxFieldLength specifies the maximum length in bytes of a single
+// field value written by Encoder.EncodeField, Encoder.EncodeFields,
+// SetHeader, and AddHeader. Lists and Dictionaries that are longer are
+// split between members over multiple field lines, which recipients
+// combine back into a single value. See MarshalSplit for details. By
+// default, or if n is 0, values are never split.
+func WithMaxFieldLength(n int) EncodeOption {
+	return &encodeOption{newOption(identMaxFieldLength{}, n)}
+}
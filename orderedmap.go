@@ -0,0 +1,154 @@
+package sfv
+
+import (
+	"iter"
+	"maps"
+	"slices"
+)
+
+// OrderedMap is a map that remembers the order in which its keys were
+// added, as needed by Dictionaries and Parameters, which are both built
+// on it. Replacing the value of an existing key keeps its position, as
+// specified by RFC 9651 for both. It is exported so that code that models
+// similar structures can reuse it.
+//
+// The zero value is an empty map ready to use. An OrderedMap is not safe
+// for concurrent use.
+type OrderedMap[K comparable, V any] struct {
+	keys   []K
+	values map[K]V
+}
+
+// NewOrderedMap creates a new empty OrderedMap.
+func NewOrderedMap[K comparable, V any]() *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{
+		keys:   make([]K, 0),
+		values: make(map[K]V),
+	}
+}
+
+// Len returns the number of entries in the map. Returns 0 if the map is
+// nil.
+func (m *OrderedMap[K, V]) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.keys)
+}
+
+// Get returns the value for key. The second return value is false if
+// there is no such key.
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	if m == nil {
+		var zero V
+		return zero, false
+	}
+	v, ok := m.values[key]
+	return v, ok
+}
+
+// Has reports whether there is an entry for key.
+func (m *OrderedMap[K, V]) Has(key K) bool {
+	_, ok := m.Get(key)
+	return ok
+}
+
+// At returns the key and the value of the entry at position i. The third
+// return value is false if i is out of range.
+func (m *OrderedMap[K, V]) At(i int) (K, V, bool) {
+	if m == nil || i < 0 || i >= len(m.keys) {
+		var key K
+		var value V
+		return key, value, false
+	}
+	key := m.keys[i]
+	return key, m.values[key], true
+}
+
+// Set adds an entry for key at the end of the map, or replaces the value
+// of the existing entry, keeping its position.
+func (m *OrderedMap[K, V]) Set(key K, value V) {
+	if m.values == nil {
+		m.values = make(map[K]V)
+	}
+	if _, exists := m.values[key]; !exists {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Delete removes the entry for key, keeping the order of the remaining
+// entries. It returns false if there was no such entry.
+func (m *OrderedMap[K, V]) Delete(key K) bool {
+	if m == nil {
+		return false
+	}
+	if _, exists := m.values[key]; !exists {
+		return false
+	}
+	delete(m.values, key)
+	if i := slices.Index(m.keys, key); i >= 0 {
+		m.keys = slices.Delete(m.keys, i, i+1)
+	}
+	return true
+}
+
+// Merge sets the entries of other in m, in order, as by Set.
+func (m *OrderedMap[K, V]) Merge(other *OrderedMap[K, V]) {
+	for key, value := range other.All() {
+		m.Set(key, value)
+	}
+}
+
+// SortKeysFunc reorders the entries so that their keys are sorted
+// according to cmp, as by slices.SortFunc.
+func (m *OrderedMap[K, V]) SortKeysFunc(cmp func(a, b K) int) {
+	if m != nil {
+		slices.SortFunc(m.keys, cmp)
+	}
+}
+
+// Keys returns a copy of the keys, in order.
+func (m *OrderedMap[K, V]) Keys() []K {
+	if m == nil {
+		return nil
+	}
+	return slices.Clone(m.keys)
+}
+
+// All returns an iterator over the keys and values, in order.
+func (m *OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if m == nil {
+			return
+		}
+		for _, key := range m.keys {
+			if !yield(key, m.values[key]) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the values, in order.
+func (m *OrderedMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, v := range m.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Clone returns a copy of the map. The values themselves are not copied.
+// Cloning nil returns nil.
+func (m *OrderedMap[K, V]) Clone() *OrderedMap[K, V] {
+	if m == nil {
+		return nil
+	}
+	return &OrderedMap[K, V]{
+		keys:   slices.Clone(m.keys),
+		values: maps.Clone(m.values),
+	}
+}
@@ -0,0 +1,112 @@
+package sfv_test
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedMap(t *testing.T) {
+	t.Run("zero value", func(t *testing.T) {
+		var m sfv.OrderedMap[string, int]
+		require.Equal(t, 0, m.Len())
+		_, ok := m.Get("a")
+		require.False(t, ok)
+
+		m.Set("a", 1)
+		require.Equal(t, 1, m.Len())
+		v, ok := m.Get("a")
+		require.True(t, ok)
+		require.Equal(t, 1, v)
+	})
+	t.Run("nil", func(t *testing.T) {
+		var m *sfv.OrderedMap[string, int]
+		require.Equal(t, 0, m.Len())
+		require.False(t, m.Has("a"))
+		require.False(t, m.Delete("a"))
+		require.Nil(t, m.Keys())
+		require.Nil(t, m.Clone())
+		require.Empty(t, maps.Collect(m.All()))
+	})
+	t.Run("set keeps position", func(t *testing.T) {
+		m := sfv.NewOrderedMap[string, int]()
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+		m.Set("a", 10)
+		require.Equal(t, []string{"a", "b", "c"}, m.Keys())
+		require.Equal(t, []int{10, 2, 3}, slices.Collect(m.Values()))
+
+		key, value, ok := m.At(0)
+		require.True(t, ok)
+		require.Equal(t, "a", key)
+		require.Equal(t, 10, value)
+		_, _, ok = m.At(3)
+		require.False(t, ok)
+		_, _, ok = m.At(-1)
+		require.False(t, ok)
+	})
+	t.Run("delete", func(t *testing.T) {
+		m := sfv.NewOrderedMap[string, int]()
+		m.Set("a", 1)
+		m.Set("b", 2)
+		m.Set("c", 3)
+		require.True(t, m.Delete("b"))
+		require.False(t, m.Delete("b"))
+		require.Equal(t, []string{"a", "c"}, m.Keys())
+		require.False(t, m.Has("b"))
+	})
+	t.Run("merge", func(t *testing.T) {
+		m := sfv.NewOrderedMap[string, int]()
+		m.Set("a", 1)
+		m.Set("b", 2)
+		other := sfv.NewOrderedMap[string, int]()
+		other.Set("c", 3)
+		other.Set("a", 10)
+		m.Merge(other)
+		require.Equal(t, []string{"a", "b", "c"}, m.Keys())
+		v, _ := m.Get("a")
+		require.Equal(t, 10, v)
+
+		m.Merge(nil)
+		require.Equal(t, 3, m.Len())
+	})
+	t.Run("sort, keys, and clone are independent", func(t *testing.T) {
+		m := sfv.NewOrderedMap[string, int]()
+		m.Set("b", 2)
+		m.Set("a", 1)
+
+		keys := m.Keys()
+		keys[0] = "x"
+		c := m.Clone()
+		c.Set("c", 3)
+		m.SortKeysFunc(strings.Compare)
+
+		require.Equal(t, []string{"a", "b"}, m.Keys())
+		require.Equal(t, []string{"b", "a", "c"}, c.Keys())
+	})
+}
+
+func TestOrderedMapConsistency(t *testing.T) {
+	// Dictionaries and Parameters replace values in place, and delete
+	// without disturbing the order of the remaining entries
+	dict, err := sfv.ParseDictionaryString(`a=1, b=2, c=3`)
+	require.NoError(t, err)
+	require.NoError(t, dict.Set("a", sfv.Integer(10)))
+	require.Equal(t, `a=10, b=2, c=3`, marshalString(t, dict))
+
+	params := sfv.NewParameters()
+	require.NoError(t, params.Set("a", 1))
+	require.NoError(t, params.Set("b", 2))
+	require.NoError(t, params.Set("a", 10))
+	deleted, err := params.Delete("b")
+	require.NoError(t, err)
+	require.True(t, deleted)
+	require.NoError(t, params.Set("b", 3))
+	require.Equal(t, []string{"a", "b"}, params.Keys())
+	require.Equal(t, `;a=10;b=3`, marshalString(t, params))
+}
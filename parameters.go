@@ -1,18 +1,30 @@
 package sfv
 
 import (
-	"bytes"
 	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/lestrrat-go/blackmagic"
 )
 
+// Parameters holds the parameters attached to an Item or an Inner List,
+// in order. Use the accessor methods such as Set, Lookup, Delete, and All
+// to work with them, which keep track of the order of the parameters.
 type Parameters struct {
-	keys []string
+	// entries maps the keys of the parameters to their values, which are
+	// bare items, in order
+	entries OrderedMap[string, BareItem]
 
-	// Values are a map of parameters to their values, where values are
-	// bare items
-	Values map[string]BareItem
+	// spans records the location of each parameter in the input, if
+	// the parameters were parsed with the WithSpans option enabled
+	spans map[string]Span
+
+	frozen bool
 }
 
 // NewParameters creates a new empty Parameters object. Parameters
@@ -20,8 +32,7 @@ type Parameters struct {
 // and InnerLists in Structured Field Values.
 func NewParameters() *Parameters {
 	return &Parameters{
-		keys:   make([]string, 0),
-		Values: make(map[string]BareItem),
+		entries: *NewOrderedMap[string, BareItem](),
 	}
 }
 
@@ -31,25 +42,84 @@ func (p *Parameters) Len() int {
 	if p == nil {
 		return 0
 	}
-	// Use Values map length if keys slice is empty but Values has data
-	if len(p.keys) == 0 && len(p.Values) > 0 {
-		return len(p.Values)
-	}
-	return len(p.keys)
+	return p.entries.Len()
 }
 
 // Keys returns a copy of the parameter keys in the order they were added.
 // The returned slice is safe to modify without affecting the original Parameters.
 func (p *Parameters) Keys() []string {
-	ret := make([]string, len(p.keys))
-	copy(ret, p.keys)
-	return ret
+	if p == nil {
+		return nil
+	}
+	return p.entries.Keys()
+}
+
+// SortedKeys returns a copy of the parameter keys in lexicographic order.
+// Unlike SortKeys, it does not change the order of the parameters.
+func (p *Parameters) SortedKeys() []string {
+	keys := p.Keys()
+	slices.Sort(keys)
+	return keys
+}
+
+// SortKeys reorders the parameters so that their keys are in
+// lexicographic order, which is then the order in which they are
+// serialized. This is useful when the output must not depend on the
+// order in which the parameters were added, such as for cache keys.
+// Note that RFC 9651 gives meaning to the order of parameters, and that
+// protocols such as HTTP Message Signatures require it to be preserved.
+// It returns ErrFrozen if the parameters are frozen.
+func (p *Parameters) SortKeys() error {
+	if p == nil {
+		return nil
+	}
+	if p.frozen {
+		return ErrFrozen
+	}
+	p.entries.SortKeysFunc(strings.Compare)
+	return nil
+}
+
+// Has reports whether there is a parameter with the given key.
+func (p *Parameters) Has(key string) bool {
+	_, ok := p.Lookup(key)
+	return ok
+}
+
+// Lookup returns the value of the parameter with the given key. The
+// second return value is false if there is no such parameter. Unlike Get,
+// it returns the BareItem itself, so that its type can be inspected.
+func (p *Parameters) Lookup(key string) (BareItem, bool) {
+	if p == nil {
+		return nil, false
+	}
+	return p.entries.Get(key)
+}
+
+// At returns the key and the value of the parameter at position i, in
+// the order in which the parameters are serialized. The third return
+// value is false if i is out of range.
+func (p *Parameters) At(i int) (string, BareItem, bool) {
+	if p == nil {
+		return "", nil, false
+	}
+	return p.entries.At(i)
+}
+
+// All returns an iterator over the keys and values of the parameters, in
+// the order they were added. The parameters must not be modified during
+// the iteration.
+func (p *Parameters) All() iter.Seq2[string, BareItem] {
+	if p == nil {
+		return func(func(string, BareItem) bool) {}
+	}
+	return p.entries.All()
 }
 
 // Get retrieves the value of a parameter by key and assigns it to dst.
 // Returns an error if the parameter is not found or if assignment fails.
 func (p *Parameters) Get(key string, dst any) error {
-	value, exists := p.Values[key]
+	value, exists := p.Lookup(key)
 	if !exists {
 		return fmt.Errorf("parameter %q not found", key)
 	}
@@ -57,52 +127,101 @@ func (p *Parameters) Get(key string, dst any) error {
 }
 
 // Set adds or updates a parameter with the given key and value.
-// The value must be a BareItem. Returns an error if the Parameters
-// object is nil or if the value is nil.
-func (p *Parameters) Set(key string, value BareItem) error {
+// The value may be a BareItem, which is stored as is, or a Go value, which
+// is converted in the same way as by BareItemFrom, so that
+//
+//	params.Set("q", 0.5)
+//
+// is equivalent to params.Set("q", sfv.BareDecimal(0.5)). Use a BareItem
+// explicitly for types that BareItemFrom does not produce, such as Tokens
+// and Byte Sequences. Returns an error if the Parameters object is nil, or
+// if the value is nil or cannot be converted.
+func (p *Parameters) Set(key string, value any) error {
 	if p == nil {
 		return fmt.Errorf("cannot set parameter on nil Parameters")
 	}
+	if p.frozen {
+		return ErrFrozen
+	}
 
 	if value == nil {
 		return fmt.Errorf("value cannot be nil")
 	}
 
-	if _, exists := p.Values[key]; !exists {
-		p.keys = append(p.keys, key)
+	bi, err := bareItemFrom(value, bareItemStringMode)
+	if err != nil {
+		return fmt.Errorf("invalid value for parameter %q: %w", key, err)
 	}
-	p.Values[key] = value
+
+	p.entries.Set(key, bi)
+	delete(p.spans, key)
 	return nil
 }
 
+// Delete removes the parameter with the given key, keeping the order of
+// the remaining parameters. It returns false if there was no such
+// parameter. It returns ErrFrozen if the parameters are frozen, whether
+// or not there is such a parameter.
+func (p *Parameters) Delete(key string) (bool, error) {
+	if p == nil {
+		return false, nil
+	}
+	if p.frozen {
+		return false, ErrFrozen
+	}
+	if !p.entries.Delete(key) {
+		return false, nil
+	}
+	delete(p.spans, key)
+	return true, nil
+}
+
+// Span returns the location in the input of the parameter with the given
+// key, starting at the key and ending after the value. The second return
+// value is false unless the parameters were parsed with the WithSpans
+// option enabled, or if the parameter has been replaced since.
+func (p *Parameters) Span(key string) (Span, bool) {
+	if p == nil {
+		return Span{}, false
+	}
+	span, ok := p.spans[key]
+	return span, ok
+}
+
 // MarshalSFV implements the Marshaler interface for Parameters.
 // It encodes the parameters in the SFV format as semicolon-separated
-// key-value pairs with proper spacing.
+// key-value pairs, without any space after the semicolons, as specified
+// by RFC 9651 Section 4.1.1.2.
 func (p *Parameters) MarshalSFV() ([]byte, error) {
 	if p == nil || p.Len() == 0 {
 		return []byte{}, nil
 	}
+	return p.appendSFV(nil, &defaultEncodeConfig)
+}
 
-	var buf bytes.Buffer
-	// Ensure keys slice is populated from Values map if needed
-	if len(p.keys) == 0 && len(p.Values) > 0 {
-		for key := range p.Values {
-			p.keys = append(p.keys, key)
-		}
-	}
+// AppendSFV appends the serialization of the parameters to dst.
+func (p *Parameters) AppendSFV(dst []byte) ([]byte, error) {
+	return p.appendSFV(dst, &defaultEncodeConfig)
+}
 
-	for _, key := range p.keys {
-		buf.WriteByte(';')
-		buf.WriteByte(' ') // Always add space after semicolon for consistency
-		buf.WriteString(key)
+// WriteSFV writes the serialization of the parameters to w, and returns
+// the number of bytes written.
+func (p *Parameters) WriteSFV(w io.Writer) (int, error) {
+	return writeSFV(w, p)
+}
 
-		value, exists := p.Values[key]
-		if !exists {
-			continue
-		}
+func (p *Parameters) appendSFV(dst []byte, cfg *encodeConfig) ([]byte, error) {
+	if p == nil || p.Len() == 0 {
+		return dst, nil
+	}
+
+	for key, value := range p.entries.All() {
+		dst = append(dst, ';')
+		dst = append(dst, cfg.parameterSpacing...)
+		dst = append(dst, key...)
 
 		// Only add '=' if the value is not Boolean true
-		if value.Type() == BooleanType {
+		if value.IsBoolean() {
 			var boolVal bool
 			if err := value.GetValue(&boolVal); err != nil {
 				return nil, fmt.Errorf("error getting boolean value for parameter %q: %w", key, err)
@@ -113,13 +232,66 @@ func (p *Parameters) MarshalSFV() ([]byte, error) {
 			}
 		}
 
-		buf.WriteByte('=')
-		marshaledParam, err := value.MarshalSFV()
+		dst = append(dst, '=')
+		var err error
+		dst, err = cfg.appendBareItem(dst, value)
 		if err != nil {
 			return nil, fmt.Errorf("error marshaling parameter value %q: %w", key, err)
 		}
-		buf.Write(marshaledParam)
 	}
 
-	return buf.Bytes(), nil
+	return dst, nil
+}
+
+// Freeze makes the parameters read-only: Set, SortKeys, and Delete fail
+// with ErrFrozen from then on. Frozen parameters can be shared between
+// goroutines. There is no way to unfreeze them, but
+// Clone returns a copy that is not frozen.
+func (p *Parameters) Freeze() {
+	if p != nil {
+		p.frozen = true
+	}
+}
+
+// Frozen reports whether Freeze was called on the parameters.
+func (p *Parameters) Frozen() bool {
+	return p != nil && p.frozen
+}
+
+// Clone returns a deep copy of the parameters. Modifying the copy does
+// not affect the original, and vice versa. Cloning nil returns nil.
+func (p *Parameters) Clone() *Parameters {
+	if p == nil {
+		return nil
+	}
+	c := &Parameters{
+		entries: *NewOrderedMap[string, BareItem](),
+		spans:   maps.Clone(p.spans),
+	}
+	for key, value := range p.entries.All() {
+		c.entries.Set(key, cloneBareItem(value))
+	}
+	return c
+}
+
+// GoString returns Go-like notation for the parameters, such as
+// &sfv.Parameters{"a": sfv.BareInteger(1)}, which is used by the %#v
+// verb. The parameters are listed in order. It is meant to make test
+// failures readable, and is not guaranteed to be valid Go code.
+func (p *Parameters) GoString() string {
+	if p == nil {
+		return "(*sfv.Parameters)(nil)"
+	}
+	var sb strings.Builder
+	sb.WriteString("&sfv.Parameters{")
+	for i, key := range p.entries.keys {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(strconv.Quote(key))
+		sb.WriteString(": ")
+		fmt.Fprintf(&sb, "%#v", p.entries.values[key])
+	}
+	sb.WriteByte('}')
+	return sb.String()
 }
@@ -0,0 +1,177 @@
+package sfv_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParametersDelete(t *testing.T) {
+	item, err := sfv.ParseItemString(`sig;a=1;internal=?1;b=2`)
+	require.NoError(t, err)
+
+	params := item.Parameters()
+	deleted, err := params.Delete("internal")
+	require.NoError(t, err)
+	require.True(t, deleted)
+	deleted, err = params.Delete("internal")
+	require.NoError(t, err)
+	require.False(t, deleted)
+	deleted, err = params.Delete("missing")
+	require.NoError(t, err)
+	require.False(t, deleted)
+	require.Equal(t, []string{"a", "b"}, params.Keys())
+	require.Equal(t, 2, params.Len())
+	require.Equal(t, `sig;a=1;b=2`, marshalString(t, item))
+
+	require.NoError(t, params.Set("internal", sfv.True()))
+	require.Equal(t, `sig;a=1;b=2;internal`, marshalString(t, item), "re-added parameters go last")
+
+	deleted, err = (*sfv.Parameters)(nil).Delete("a")
+	require.NoError(t, err)
+	require.False(t, deleted)
+}
+
+func TestParametersLookup(t *testing.T) {
+	item, err := sfv.ParseItemString(`a;q=0.5;final`)
+	require.NoError(t, err)
+	params := item.Parameters()
+
+	require.True(t, params.Has("q"))
+	require.True(t, params.Has("final"))
+	require.False(t, params.Has("missing"))
+
+	v, ok := params.Lookup("q")
+	require.True(t, ok)
+	require.Equal(t, sfv.DecimalType, v.Type())
+
+	v, ok = params.Lookup("final")
+	require.True(t, ok)
+	require.Equal(t, sfv.True(), v)
+
+	v, ok = params.Lookup("missing")
+	require.False(t, ok)
+	require.Nil(t, v)
+
+	var nilParams *sfv.Parameters
+	require.False(t, nilParams.Has("a"))
+}
+
+func TestParametersAll(t *testing.T) {
+	item, err := sfv.ParseItemString(`a;z=1;a="x";m`)
+	require.NoError(t, err)
+
+	var keys []string
+	var types []sfv.Type
+	for key, value := range item.Parameters().All() {
+		keys = append(keys, key)
+		types = append(types, value.Type())
+	}
+	require.Equal(t, []string{"z", "a", "m"}, keys)
+	require.Equal(t, []sfv.Type{sfv.IntegerType, sfv.StringType, sfv.BooleanType}, types)
+
+	for key := range item.Parameters().All() {
+		require.Equal(t, "z", key)
+		break
+	}
+	for range (*sfv.Parameters)(nil).All() {
+		t.Fatal("nil parameters should be empty")
+	}
+}
+
+func TestParametersZeroValue(t *testing.T) {
+	var params sfv.Parameters
+	require.Zero(t, params.Len())
+	require.NoError(t, params.Set("b", sfv.BareInteger(1)))
+	require.NoError(t, params.Set("a", sfv.True()))
+	require.Equal(t, []string{"b", "a"}, params.Keys())
+	require.Equal(t, `;b=1;a`, marshalString(t, &params))
+
+	var nilParams *sfv.Parameters
+	require.Nil(t, nilParams.Keys())
+	var v sfv.BareItem
+	require.Error(t, nilParams.Get("a", &v))
+}
+
+func TestParametersStableOrder(t *testing.T) {
+	keys := []string{"z", "keyid", "alg", "created", "nonce", "expires", "tag", "b", "a"}
+
+	params := sfv.NewParameters()
+	for i, key := range keys {
+		require.NoError(t, params.Set(key, sfv.BareInteger(int64(i))))
+	}
+	expected := `;z=0;keyid=1;alg=2;created=3;nonce=4;expires=5;tag=6;b=7;a=8`
+
+	parsed, err := sfv.ParseItemString(`x` + expected)
+	require.NoError(t, err)
+
+	// map iteration order is randomized, so repeat enough times for an
+	// order that depends on it to show up
+	for range 50 {
+		require.Equal(t, expected, marshalString(t, params))
+		require.Equal(t, expected, marshalString(t, parsed.Parameters()))
+		require.Equal(t, expected, marshalString(t, params.Clone()))
+	}
+}
+
+func TestParametersSetGoValues(t *testing.T) {
+	params := sfv.NewParameters()
+	require.NoError(t, params.Set("n", 1))
+	require.NoError(t, params.Set("q", 0.5))
+	require.NoError(t, params.Set("s", "x"))
+	require.NoError(t, params.Set("f", true))
+	require.NoError(t, params.Set("t", sfv.BareToken("tok")))
+
+	require.Error(t, params.Set("bad", []int{1}))
+	require.Error(t, params.Set("big", int64(1e15)))
+	require.Error(t, params.Set("nil", nil))
+	require.False(t, params.Has("bad"))
+
+	item := sfv.BareToken("a").ToItem().With(params)
+	require.Equal(t, `a;n=1;q=0.5;s="x";f;t=tok`, marshalString(t, item))
+}
+
+func TestParametersSortKeys(t *testing.T) {
+	item, err := sfv.ParseItemString(`a;z=1;b=2;m`)
+	require.NoError(t, err)
+	params := item.Parameters()
+
+	require.Equal(t, []string{"b", "m", "z"}, params.SortedKeys())
+	require.Equal(t, []string{"z", "b", "m"}, params.Keys(), "SortedKeys should not reorder the parameters")
+
+	require.NoError(t, params.SortKeys())
+	require.Equal(t, []string{"b", "m", "z"}, params.Keys())
+	require.Equal(t, `a;b=2;m;z=1`, marshalString(t, item))
+
+	var nilParams *sfv.Parameters
+	require.Nil(t, nilParams.SortedKeys())
+	require.NoError(t, nilParams.SortKeys())
+}
+
+func TestParametersAt(t *testing.T) {
+	item, err := sfv.ParseItemString(`a;z=1;b`)
+	require.NoError(t, err)
+	params := item.Parameters()
+
+	key, v, ok := params.At(0)
+	require.True(t, ok)
+	require.Equal(t, "z", key)
+	require.Equal(t, sfv.IntegerType, v.Type())
+
+	key, v, ok = params.At(1)
+	require.True(t, ok)
+	require.Equal(t, "b", key)
+	require.Equal(t, sfv.True(), v)
+
+	for _, i := range []int{-1, 2} {
+		key, v, ok = params.At(i)
+		require.False(t, ok)
+		require.Empty(t, key)
+		require.Nil(t, v)
+	}
+
+	var nilParams *sfv.Parameters
+	_, _, ok = nilParams.At(0)
+	require.False(t, ok)
+}
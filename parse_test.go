@@ -1,8 +1,11 @@
 package sfv_test
 
 import (
+	"bytes"
+	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/lestrrat-go/sfv"
 	"github.com/stretchr/testify/require"
@@ -12,12 +15,12 @@ func TestParseIntegerList(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected []any
-		types    []int
+		types    []sfv.Type
 	}{
-		{"123", []any{int64(123)}, []int{sfv.IntegerType}},
-		{"123, 456", []any{int64(123), int64(456)}, []int{sfv.IntegerType, sfv.IntegerType}},
-		{"-999", []any{int64(-999)}, []int{sfv.IntegerType}},
-		{"0", []any{int64(0)}, []int{sfv.IntegerType}},
+		{"123", []any{int64(123)}, []sfv.Type{sfv.IntegerType}},
+		{"123, 456", []any{int64(123), int64(456)}, []sfv.Type{sfv.IntegerType, sfv.IntegerType}},
+		{"-999", []any{int64(-999)}, []sfv.Type{sfv.IntegerType}},
+		{"0", []any{int64(0)}, []sfv.Type{sfv.IntegerType}},
 	}
 
 	for _, test := range tests {
@@ -57,12 +60,12 @@ func TestParseDecimalList(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected []any
-		types    []int
+		types    []sfv.Type
 	}{
-		{"123.456", []any{123.456}, []int{sfv.DecimalType}},
-		{"123.456, 789.123", []any{123.456, 789.123}, []int{sfv.DecimalType, sfv.DecimalType}},
-		{"-123.456", []any{-123.456}, []int{sfv.DecimalType}},
-		{"0.0", []any{0.0}, []int{sfv.DecimalType}},
+		{"123.456", []any{123.456}, []sfv.Type{sfv.DecimalType}},
+		{"123.456, 789.123", []any{123.456, 789.123}, []sfv.Type{sfv.DecimalType, sfv.DecimalType}},
+		{"-123.456", []any{-123.456}, []sfv.Type{sfv.DecimalType}},
+		{"0.0", []any{0.0}, []sfv.Type{sfv.DecimalType}},
 	}
 
 	for _, test := range tests {
@@ -102,12 +105,12 @@ func TestParseStringList(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected []any
-		types    []int
+		types    []sfv.Type
 	}{
-		{`"hello"`, []any{"hello"}, []int{sfv.StringType}},
-		{`"hello", "world"`, []any{"hello", "world"}, []int{sfv.StringType, sfv.StringType}},
-		{`"hello \"world\""`, []any{`hello "world"`}, []int{sfv.StringType}},
-		{`""`, []any{""}, []int{sfv.StringType}},
+		{`"hello"`, []any{"hello"}, []sfv.Type{sfv.StringType}},
+		{`"hello", "world"`, []any{"hello", "world"}, []sfv.Type{sfv.StringType, sfv.StringType}},
+		{`"hello \"world\""`, []any{`hello "world"`}, []sfv.Type{sfv.StringType}},
+		{`""`, []any{""}, []sfv.Type{sfv.StringType}},
 	}
 
 	for _, test := range tests {
@@ -147,12 +150,12 @@ func TestParseTokenList(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected []any
-		types    []int
+		types    []sfv.Type
 	}{
-		{"foo", []any{"foo"}, []int{sfv.TokenType}},
-		{"foo, bar", []any{"foo", "bar"}, []int{sfv.TokenType, sfv.TokenType}},
-		{"*", []any{"*"}, []int{sfv.TokenType}},
-		{"foo123", []any{"foo123"}, []int{sfv.TokenType}},
+		{"foo", []any{"foo"}, []sfv.Type{sfv.TokenType}},
+		{"foo, bar", []any{"foo", "bar"}, []sfv.Type{sfv.TokenType, sfv.TokenType}},
+		{"*", []any{"*"}, []sfv.Type{sfv.TokenType}},
+		{"foo123", []any{"foo123"}, []sfv.Type{sfv.TokenType}},
 	}
 
 	for _, test := range tests {
@@ -192,11 +195,11 @@ func TestParseByteSequenceList(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected []any
-		types    []int
+		types    []sfv.Type
 	}{
-		{":aGVsbG8=:", []any{[]byte("hello")}, []int{sfv.ByteSequenceType}},
-		{":aGVsbG8=:, :d29ybGQ=:", []any{[]byte("hello"), []byte("world")}, []int{sfv.ByteSequenceType, sfv.ByteSequenceType}},
-		{"::", []any{[]byte{}}, []int{sfv.ByteSequenceType}},
+		{":aGVsbG8=:", []any{[]byte("hello")}, []sfv.Type{sfv.ByteSequenceType}},
+		{":aGVsbG8=:, :d29ybGQ=:", []any{[]byte("hello"), []byte("world")}, []sfv.Type{sfv.ByteSequenceType, sfv.ByteSequenceType}},
+		{"::", []any{[]byte{}}, []sfv.Type{sfv.ByteSequenceType}},
 	}
 
 	for _, test := range tests {
@@ -236,11 +239,11 @@ func TestParseBooleanList(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected []any
-		types    []int
+		types    []sfv.Type
 	}{
-		{"?1", []any{true}, []int{sfv.BooleanType}},
-		{"?0", []any{false}, []int{sfv.BooleanType}},
-		{"?1, ?0", []any{true, false}, []int{sfv.BooleanType, sfv.BooleanType}},
+		{"?1", []any{true}, []sfv.Type{sfv.BooleanType}},
+		{"?0", []any{false}, []sfv.Type{sfv.BooleanType}},
+		{"?1, ?0", []any{true, false}, []sfv.Type{sfv.BooleanType, sfv.BooleanType}},
 	}
 
 	for _, test := range tests {
@@ -280,11 +283,11 @@ func TestParseDateList(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected []any
-		types    []int
+		types    []sfv.Type
 	}{
-		{"@1659578233", []any{int64(1659578233)}, []int{sfv.DateType}},
-		{"@0", []any{int64(0)}, []int{sfv.DateType}},
-		{"@1659578233, @1659578234", []any{int64(1659578233), int64(1659578234)}, []int{sfv.DateType, sfv.DateType}},
+		{"@1659578233", []any{int64(1659578233)}, []sfv.Type{sfv.DateType}},
+		{"@0", []any{int64(0)}, []sfv.Type{sfv.DateType}},
+		{"@1659578233, @1659578234", []any{int64(1659578233), int64(1659578234)}, []sfv.Type{sfv.DateType, sfv.DateType}},
 	}
 
 	for _, test := range tests {
@@ -324,11 +327,11 @@ func TestParseDisplayStringList(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected []any
-		types    []int
+		types    []sfv.Type
 	}{
-		{`%"hello"`, []any{"hello"}, []int{sfv.DisplayStringType}},
-		{`%"hello", %"world"`, []any{"hello", "world"}, []int{sfv.DisplayStringType, sfv.DisplayStringType}},
-		{`%"This is intended for display to %c3%bcsers."`, []any{"This is intended for display to üsers."}, []int{sfv.DisplayStringType}},
+		{`%"hello"`, []any{"hello"}, []sfv.Type{sfv.DisplayStringType}},
+		{`%"hello", %"world"`, []any{"hello", "world"}, []sfv.Type{sfv.DisplayStringType, sfv.DisplayStringType}},
+		{`%"This is intended for display to %c3%bcsers."`, []any{"This is intended for display to üsers."}, []sfv.Type{sfv.DisplayStringType}},
 	}
 
 	for _, test := range tests {
@@ -367,11 +370,11 @@ func TestParseDisplayStringList(t *testing.T) {
 func TestParseMixedList(t *testing.T) {
 	tests := []struct {
 		input         string
-		expectedTypes []int
+		expectedTypes []sfv.Type
 		expectedLen   int
 	}{
-		{`123, "hello", foo, :aGVsbG8=:, ?1, @1659578233`, []int{sfv.IntegerType, sfv.StringType, sfv.TokenType, sfv.ByteSequenceType, sfv.BooleanType, sfv.DateType}, 6},
-		{`123.456, "world"`, []int{sfv.DecimalType, sfv.StringType}, 2},
+		{`123, "hello", foo, :aGVsbG8=:, ?1, @1659578233`, []sfv.Type{sfv.IntegerType, sfv.StringType, sfv.TokenType, sfv.ByteSequenceType, sfv.BooleanType, sfv.DateType}, 6},
+		{`123.456, "world"`, []sfv.Type{sfv.DecimalType, sfv.StringType}, 2},
 	}
 
 	for _, test := range tests {
@@ -446,3 +449,808 @@ func TestParseInnerList(t *testing.T) {
 		})
 	}
 }
+
+func TestParseDictionaryMemberParameters(t *testing.T) {
+	dict, err := sfv.ParseDictionary([]byte(`key=1;a=2, b=(1;c=3 2);d=4;e, f;g=5, h`))
+	require.NoError(t, err)
+	require.Equal(t, []string{"key", "b", "f", "h"}, dict.Keys())
+
+	paramValue := func(t *testing.T, params *sfv.Parameters, name string) any {
+		t.Helper()
+		var bi sfv.BareItem
+		require.NoError(t, params.Get(name, &bi), "parameter %q should exist", name)
+		var v any
+		require.NoError(t, bi.GetValue(&v))
+		return v
+	}
+
+	t.Run("item", func(t *testing.T) {
+		var item sfv.Item
+		require.NoError(t, dict.GetValue("key", &item))
+		require.Equal(t, 1, item.Parameters().Len())
+		require.Equal(t, int64(2), paramValue(t, item.Parameters(), "a"))
+	})
+	t.Run("inner list", func(t *testing.T) {
+		var list *sfv.InnerList
+		require.NoError(t, dict.GetValue("b", &list))
+		require.Equal(t, 2, list.Parameters().Len())
+		require.Equal(t, int64(4), paramValue(t, list.Parameters(), "d"))
+		require.Equal(t, true, paramValue(t, list.Parameters(), "e"))
+
+		first, ok := list.Get(0)
+		require.True(t, ok)
+		require.Equal(t, 1, first.Parameters().Len())
+		require.Equal(t, int64(3), paramValue(t, first.Parameters(), "c"))
+
+		second, ok := list.Get(1)
+		require.True(t, ok)
+		require.Equal(t, 0, second.Parameters().Len())
+	})
+	t.Run("bare key with parameters", func(t *testing.T) {
+		var item sfv.Item
+		require.NoError(t, dict.GetValue("f", &item))
+		require.Equal(t, sfv.BooleanType, item.Type())
+		require.Equal(t, 1, item.Parameters().Len())
+		require.Equal(t, int64(5), paramValue(t, item.Parameters(), "g"))
+	})
+	t.Run("bare key", func(t *testing.T) {
+		var v sfv.BareItem
+		require.NoError(t, dict.GetValue("h", &v))
+		require.Equal(t, sfv.BooleanType, v.Type())
+	})
+	t.Run("parameters after member parameters", func(t *testing.T) {
+		_, err := sfv.ParseDictionary([]byte(`a=1;b=2 ;c=3`))
+		require.Error(t, err)
+	})
+}
+
+func TestParseDictionaryDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		input    string
+		keys     []string
+		expected map[string]int64
+		output   string
+	}{
+		{"a=1, a=2", []string{"a"}, map[string]int64{"a": 2}, "a=2"},
+		{"a=1, b=2, a=3", []string{"a", "b"}, map[string]int64{"a": 3, "b": 2}, "a=3, b=2"},
+		{"a=1, b=2, b=3, a=4, c=5", []string{"a", "b", "c"}, map[string]int64{"a": 4, "b": 3, "c": 5}, "a=4, b=3, c=5"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			dict, err := sfv.ParseDictionary([]byte(test.input))
+			require.NoError(t, err, "ParseDictionary(%q) failed", test.input)
+			require.Equal(t, test.keys, dict.Keys(), "keys should keep the position of the first occurrence")
+
+			for key, expected := range test.expected {
+				var item sfv.Item
+				require.NoError(t, dict.GetValue(key, &item), "GetValue(%q) failed", key)
+				var actual int64
+				require.NoError(t, item.GetValue(&actual), "item.GetValue failed for key %q", key)
+				require.Equal(t, expected, actual, "last occurrence of %q should win", key)
+			}
+
+			marshaled, err := sfv.Marshal(dict)
+			require.NoError(t, err, "Marshal(%q) failed", test.input)
+			require.Equal(t, test.output, string(marshaled))
+		})
+	}
+}
+
+func TestParseByteSequenceStrictness(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []byte
+		strict   bool
+	}{
+		{name: "padded", input: ":aGVsbG8=:", expected: []byte("hello"), strict: true},
+		{name: "unpadded", input: ":aGVsbG8:", expected: []byte("hello"), strict: false},
+		{name: "non-zero pad bits", input: ":aGVsbG9=:", expected: []byte("hello"), strict: false},
+		{name: "empty", input: "::", expected: []byte{}, strict: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			item, err := sfv.ParseItem([]byte(test.input))
+			require.NoError(t, err, "tolerant ParseItem(%q) should succeed", test.input)
+
+			var actual []byte
+			require.NoError(t, item.GetValue(&actual))
+			require.Equal(t, test.expected, actual)
+
+			_, err = sfv.ParseItem([]byte(test.input), sfv.WithStrictByteSequence(true))
+			if test.strict {
+				require.NoError(t, err, "strict ParseItem(%q) should succeed", test.input)
+			} else {
+				require.Error(t, err, "strict ParseItem(%q) should fail", test.input)
+			}
+		})
+	}
+}
+
+func TestParseByteSequencePadding(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		msg   string
+	}{
+		{name: "padding in the middle", input: ":ab=cd:", msg: "trailing padding"},
+		{name: "padding followed by data", input: ":aGVsbG8=a:", msg: "trailing padding"},
+		{name: "too much padding", input: ":aGVsbA===:", msg: "too many padding"},
+		{name: "only padding", input: ":====:", msg: "too many padding"},
+		{name: "too little padding", input: ":aGVsbA=:", msg: "multiple of 4"},
+		{name: "unneeded padding", input: ":aGVsbG8==:", msg: "multiple of 4"},
+		{name: "impossible length", input: ":aGVsb:", msg: "length"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, strict := range []bool{false, true} {
+				_, err := sfv.ParseItem([]byte(test.input), sfv.WithStrictByteSequence(strict))
+				require.Error(t, err, "ParseItem(%q) should fail (strict=%t)", test.input, strict)
+				require.Contains(t, err.Error(), test.msg)
+			}
+		})
+	}
+}
+
+func TestParseDisplayStringUTF8(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "lone continuation byte", input: `%"%bc"`},
+		{name: "truncated sequence", input: `%"abc%c3"`},
+		{name: "overlong encoding", input: `%"%c0%af"`},
+		{name: "uppercase hex", input: `%"f%C3%BC"`},
+		{name: "mixed case hex", input: `%"f%c3%Bc"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := sfv.ParseItem([]byte(test.input))
+			require.Error(t, err, "ParseItem(%q) should fail on invalid UTF-8", test.input)
+
+			item, err := sfv.ParseItem([]byte(test.input), sfv.WithLenientDisplayString(true))
+			require.NoError(t, err, "lenient ParseItem(%q) should succeed", test.input)
+			require.Equal(t, sfv.DisplayStringType, item.Type())
+		})
+	}
+}
+
+func TestParseBareItem(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		tests := []struct {
+			input    string
+			typ      sfv.Type
+			expected any
+		}{
+			{"42", sfv.IntegerType, int64(42)},
+			{"4.5", sfv.DecimalType, 4.5},
+			{`"hello"`, sfv.StringType, "hello"},
+			{"foo/bar", sfv.TokenType, "foo/bar"},
+			{":aGVsbG8=:", sfv.ByteSequenceType, []byte("hello")},
+			{"?0", sfv.BooleanType, false},
+			{"@1659578233", sfv.DateType, int64(1659578233)},
+			{`%"f%c3%bc"`, sfv.DisplayStringType, "fü"},
+			{" 42 ", sfv.IntegerType, int64(42)},
+		}
+
+		for _, test := range tests {
+			t.Run(test.input, func(t *testing.T) {
+				bi, err := sfv.ParseBareItem([]byte(test.input))
+				require.NoError(t, err, "ParseBareItem(%q) failed", test.input)
+				require.Equal(t, test.typ, bi.Type())
+
+				var actual any
+				require.NoError(t, bi.GetValue(&actual))
+				require.Equal(t, test.expected, actual)
+			})
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, input := range []string{"", "42;a=1", "42, 43", "foo bar", "(1 2)"} {
+			t.Run(input, func(t *testing.T) {
+				_, err := sfv.ParseBareItem([]byte(input))
+				require.Error(t, err, "ParseBareItem(%q) should fail", input)
+			})
+		}
+	})
+}
+
+func TestParseInnerListFunction(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		tests := []struct {
+			input    string
+			length   int
+			params   []string
+			expected string
+		}{
+			{"()", 0, nil, "()"},
+			{`("@method" "@authority")`, 2, nil, `("@method" "@authority")`},
+			{`("@method";req "content-digest");created=1618884473;keyid="test-key"`, 2, []string{"created", "keyid"}, `("@method";req "content-digest");created=1618884473;keyid="test-key"`},
+		}
+
+		for _, test := range tests {
+			t.Run(test.input, func(t *testing.T) {
+				il, err := sfv.ParseInnerList([]byte(test.input))
+				require.NoError(t, err, "ParseInnerList(%q) failed", test.input)
+				require.Equal(t, test.length, il.Len())
+				if test.params != nil {
+					require.Equal(t, test.params, il.Parameters().Keys())
+				}
+
+				marshaled, err := sfv.Marshal(il)
+				require.NoError(t, err)
+				require.Equal(t, test.expected, string(marshaled))
+			})
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, input := range []string{"", "1", "(1 2", "(1 2), (3)", "(1 2) x", "(1 2)a=1"} {
+			t.Run(input, func(t *testing.T) {
+				_, err := sfv.ParseInnerList([]byte(input))
+				require.Error(t, err, "ParseInnerList(%q) should fail", input)
+			})
+		}
+	})
+}
+
+func TestParseByteSequenceLazyDecoding(t *testing.T) {
+	const input = `:aGVsbG8gd29ybGQ=:;a=:Zm9v:`
+	for _, eager := range []bool{false, true} {
+		t.Run(fmt.Sprintf("eager=%t", eager), func(t *testing.T) {
+			data := []byte(input)
+			item, err := sfv.ParseItem(data, sfv.WithEagerByteSequence(eager))
+			require.NoError(t, err, "ParseItem failed")
+
+			// The parsed value must not depend on the input buffer
+			for i := range data {
+				data[i] = 'X'
+			}
+
+			var actual []byte
+			require.NoError(t, item.GetValue(&actual))
+			require.Equal(t, []byte("hello world"), actual)
+
+			var param sfv.BareItem
+			require.NoError(t, item.Parameters().Get("a", &param))
+			var paramValue []byte
+			require.NoError(t, param.GetValue(&paramValue))
+			require.Equal(t, []byte("foo"), paramValue)
+
+			marshaled, err := sfv.Marshal(item)
+			require.NoError(t, err)
+			require.Equal(t, `:aGVsbG8gd29ybGQ=:;a=:Zm9v:`, string(marshaled))
+		})
+	}
+}
+
+func TestParseStringEscapes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"plain"`, "plain"},
+		{`"\"leading"`, `"leading`},
+		{`"trailing\\"`, `trailing\`},
+		{`"mid\"dle\\esc\"apes"`, `mid"dle\esc"apes`},
+		{`"\\\\"`, `\\`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			item, err := sfv.ParseItem([]byte(test.input))
+			require.NoError(t, err, "ParseItem(%q) failed", test.input)
+
+			var actual string
+			require.NoError(t, item.GetValue(&actual))
+			require.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestParseTrivialItems(t *testing.T) {
+	// Passing any option bypasses the fast path, which lets us compare
+	// its results against the general parser
+	slow := sfv.WithEagerByteSequence(false)
+
+	inputs := []string{
+		"foo", "*", "foo/bar:baz", "42", "-42", "0", "999999999999999",
+		`"hello world"`, `""`, "?0", "?1",
+		// shapes that must fall back to the general parser
+		"-", "1000000000000000", "4.5", `"a\"b"`, `"unterminated`, "?2", "foo;a=1", " foo", "foo ", "foo bar",
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			fast, fastErr := sfv.ParseItem([]byte(input))
+			general, generalErr := sfv.ParseItem([]byte(input), slow)
+			if generalErr != nil {
+				require.Error(t, fastErr, "ParseItem(%q) should fail", input)
+				return
+			}
+			require.NoError(t, fastErr, "ParseItem(%q) failed", input)
+			require.Equal(t, general.Type(), fast.Type())
+
+			fastBytes, err := sfv.Marshal(fast)
+			require.NoError(t, err)
+			generalBytes, err := sfv.Marshal(general)
+			require.NoError(t, err)
+			require.Equal(t, string(generalBytes), string(fastBytes))
+
+			list, err := sfv.Parse([]byte(input))
+			require.NoError(t, err)
+			require.IsType(t, &sfv.List{}, list)
+		})
+	}
+}
+
+func TestParseErrorAggregation(t *testing.T) {
+	t.Run("list", func(t *testing.T) {
+		input := `a, "bad, string\q", (1 ?7), c, ?2, d e, f,`
+		_, err := sfv.Parse([]byte(input))
+		require.Error(t, err, "should fail without aggregation")
+
+		v, err := sfv.Parse([]byte(input), sfv.WithErrorAggregation(true))
+		require.Error(t, err)
+		require.NotNil(t, v, "successfully parsed members should be returned")
+
+		marshaled, merr := sfv.Marshal(v)
+		require.NoError(t, merr)
+		require.Equal(t, `a, c, d, f`, string(marshaled))
+
+		// one error for each bad member, and one for the trailing comma
+		joined, ok := err.(interface{ Unwrap() []error })
+		require.True(t, ok, "error should be a joined error")
+		require.Len(t, joined.Unwrap(), 5, err.Error())
+	})
+	t.Run("dictionary", func(t *testing.T) {
+		input := `a=1, B=2, c=(1 ?7), d=?9, e`
+		dict, err := sfv.ParseDictionary([]byte(input), sfv.WithErrorAggregation(true))
+		require.Error(t, err)
+		require.NotNil(t, dict)
+		require.Equal(t, []string{"a", "e"}, dict.Keys())
+
+		joined, ok := err.(interface{ Unwrap() []error })
+		require.True(t, ok, "error should be a joined error")
+		require.Len(t, joined.Unwrap(), 3, err.Error())
+	})
+	t.Run("list func", func(t *testing.T) {
+		var count int
+		err := sfv.ParseListFunc([]byte(`a, ?9, b`), func(any, *sfv.Parameters) error {
+			count++
+			return nil
+		}, sfv.WithErrorAggregation(true))
+		require.Error(t, err)
+		require.Equal(t, 2, count)
+	})
+	t.Run("no errors", func(t *testing.T) {
+		v, err := sfv.Parse([]byte(`a, b`), sfv.WithErrorAggregation(true))
+		require.NoError(t, err)
+		require.Equal(t, 2, v.(*sfv.List).Len())
+	})
+}
+
+func TestParseRawText(t *testing.T) {
+	t.Run("list", func(t *testing.T) {
+		input := []byte(`abc;q=0.50 ,  ( "x"  y;z );n=1,:AQID:`)
+		v, err := sfv.Parse(input, sfv.WithRawText(true))
+		require.NoError(t, err)
+		list := v.(*sfv.List)
+
+		first, _ := list.Get(0)
+		require.Equal(t, `abc;q=0.50`, string(first.(sfv.Item).Raw()))
+
+		second, _ := list.Get(1)
+		inner := second.(*sfv.InnerList)
+		require.Equal(t, `( "x"  y;z );n=1`, string(inner.Raw()))
+		innerItem, _ := inner.Get(1)
+		require.Equal(t, `y;z`, string(innerItem.Raw()))
+
+		third, _ := list.Get(2)
+		require.Equal(t, `:AQID:`, string(third.(sfv.Item).Raw()))
+		require.Equal(t, string(input), string(list.Raw()))
+		require.Equal(t, string(list.Raw()), string(list.Clone().Raw()))
+
+		// the raw text must not be affected by changes to the input
+		copy(input, bytes.Repeat([]byte{'X'}, len(input)))
+		require.Equal(t, `abc;q=0.50`, string(first.(sfv.Item).Raw()))
+	})
+	t.Run("dictionary", func(t *testing.T) {
+		dict, err := sfv.ParseDictionary([]byte(`sig1=("@method" "@path");created=1618884473, b, c=?0;x`), sfv.WithRawText(true))
+		require.NoError(t, err)
+		require.Equal(t, `sig1=("@method" "@path");created=1618884473`, string(dict.RawMember("sig1")))
+		require.Equal(t, `b`, string(dict.RawMember("b")))
+		require.Equal(t, `c=?0;x`, string(dict.RawMember("c")))
+		require.Nil(t, dict.RawMember("nonexistent"))
+
+		var inner *sfv.InnerList
+		require.NoError(t, dict.GetValue("sig1", &inner))
+		require.Equal(t, `("@method" "@path");created=1618884473`, string(inner.Raw()))
+
+		require.Equal(t, `sig1=("@method" "@path");created=1618884473, b, c=?0;x`, string(dict.Raw()))
+
+		require.NoError(t, dict.Set("c", sfv.True()))
+		require.Nil(t, dict.RawMember("c"), "raw text should be dropped when the member is replaced")
+		require.Nil(t, dict.Raw(), "raw text should be dropped when a member is replaced")
+	})
+	t.Run("whole field", func(t *testing.T) {
+		// the received text is kept even where a re-serialization would
+		// differ, as in the spacing or the decimal formatting
+		v, err := sfv.Parse([]byte(`  1.50 ,a;x=1.0  `), sfv.WithRawText(true))
+		require.NoError(t, err)
+		list := v.(*sfv.List)
+		require.Equal(t, `1.50 ,a;x=1.0`, string(list.Raw()))
+		require.Equal(t, `1.5, a;x=1.0`, marshalString(t, list))
+
+		require.NoError(t, list.Add(sfv.True()))
+		require.Nil(t, list.Raw(), "raw text should be dropped when the list is modified")
+
+		dict, err := sfv.ParseDictionary([]byte(`a=1,b=2 `), sfv.WithRawText(true))
+		require.NoError(t, err)
+		require.Equal(t, `a=1,b=2`, string(dict.Raw()))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		item, err := sfv.ParseItem([]byte(`foo;bar`))
+		require.NoError(t, err)
+		require.Nil(t, item.Raw())
+
+		dict, err := sfv.ParseDictionary([]byte(`a=1`))
+		require.NoError(t, err)
+		require.Nil(t, dict.RawMember("a"))
+	})
+}
+
+func TestParseStringVariants(t *testing.T) {
+	v, err := sfv.ParseString(`a, "b", :AQID:`)
+	require.NoError(t, err)
+	require.Equal(t, 3, v.(*sfv.List).Len())
+
+	dict, err := sfv.ParseDictionaryString(`a=1, b="two"`)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b"}, dict.Keys())
+
+	item, err := sfv.ParseItemString(`"hello";x=:AQID:`)
+	require.NoError(t, err)
+	var s string
+	require.NoError(t, item.GetValue(&s))
+	require.Equal(t, "hello", s)
+
+	var param sfv.BareItem
+	require.NoError(t, item.Parameters().Get("x", &param))
+	var b []byte
+	require.NoError(t, param.GetValue(&b))
+	require.Equal(t, []byte{1, 2, 3}, b)
+
+	_, err = sfv.ParseItemString(`"unterminated`)
+	require.Error(t, err)
+}
+
+func TestParseInner(t *testing.T) {
+	dict, err := sfv.ParseDictionary([]byte(`meta="a=1, b=(x y)", bad="a, ?x", num=1`))
+	require.NoError(t, err)
+
+	var item sfv.Item
+	require.NoError(t, dict.GetValue("meta", &item))
+	v, err := item.(*sfv.StringItem).ParseInner(sfv.DictionaryField)
+	require.NoError(t, err)
+	inner, ok := v.(*sfv.Dictionary)
+	require.True(t, ok, "expected *sfv.Dictionary, got %T", v)
+	require.Equal(t, []string{"a", "b"}, inner.Keys())
+
+	require.NoError(t, dict.GetValue("bad", &item))
+	_, err = item.(*sfv.StringItem).ParseInner(sfv.ListField)
+	require.Error(t, err)
+	var perr *sfv.ParseError
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, 4, perr.Offset, "offset should point at the offending byte in the string content")
+
+	require.NoError(t, dict.GetValue("num", &item))
+	_, err = item.(*sfv.IntegerItem).ParseInner(sfv.ItemField)
+	require.Error(t, err, "non-string items cannot be parsed")
+
+	v, err = sfv.BareString(`?1;x`).ParseInner(sfv.ItemField)
+	require.NoError(t, err)
+	require.Equal(t, sfv.BooleanType, v.(sfv.Item).Type())
+}
+
+func TestParseSpans(t *testing.T) {
+	input := `sig=("@method" "@path");created=1618884473;alg=x, ok, ttl=30; stale=?1`
+	dict, err := sfv.ParseDictionary([]byte(input), sfv.WithSpans(true))
+	require.NoError(t, err)
+
+	spanText := func(span sfv.Span, ok bool) string {
+		require.True(t, ok, "span should be recorded")
+		return input[span.Start:span.End]
+	}
+
+	require.Equal(t, `sig=("@method" "@path");created=1618884473;alg=x`, spanText(dict.MemberSpan("sig")))
+	require.Equal(t, `ok`, spanText(dict.MemberSpan("ok")))
+
+	var inner *sfv.InnerList
+	require.NoError(t, dict.GetValue("sig", &inner))
+	require.Equal(t, `("@method" "@path");created=1618884473;alg=x`, spanText(inner.Span()))
+	require.Equal(t, `created=1618884473`, spanText(inner.Parameters().Span("created")))
+	require.Equal(t, `alg=x`, spanText(inner.Parameters().Span("alg")))
+
+	second, ok := inner.Get(1)
+	require.True(t, ok)
+	require.Equal(t, `"@path"`, spanText(second.Span()))
+
+	var item sfv.Item
+	require.NoError(t, dict.GetValue("ttl", &item))
+	require.Equal(t, `30; stale=?1`, spanText(item.Span()))
+	require.Equal(t, `stale=?1`, spanText(item.Parameters().Span("stale")))
+
+	require.NoError(t, item.Parameters().Set("stale", sfv.False()))
+	_, ok = item.Parameters().Span("stale")
+	require.False(t, ok, "span should be dropped when the parameter is replaced")
+
+	t.Run("disabled", func(t *testing.T) {
+		dict, err := sfv.ParseDictionary([]byte(input))
+		require.NoError(t, err)
+		_, ok := dict.MemberSpan("sig")
+		require.False(t, ok)
+
+		var item sfv.Item
+		require.NoError(t, dict.GetValue("ttl", &item))
+		_, ok = item.Span()
+		require.False(t, ok)
+		_, ok = item.Parameters().Span("stale")
+		require.False(t, ok)
+	})
+}
+
+func TestParseObsFold(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expected       string
+		failsByDefault bool
+	}{
+		{name: "between members", input: "a=1,\r\n b=2", expected: `a=1, b=2`},
+		{name: "after equals", input: "a=\r\n\t 1", expected: `a=1`},
+		{name: "inside string", input: "a=\"foo\r\n  bar\"", expected: `a="foo bar"`, failsByDefault: true},
+		{name: "bare LF", input: "a=\"foo\n\tbar\"", expected: `a="foo bar"`, failsByDefault: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.failsByDefault {
+				_, err := sfv.ParseDictionary([]byte(test.input))
+				require.Error(t, err, "folded input should fail by default")
+			}
+
+			dict, err := sfv.ParseDictionary([]byte(test.input), sfv.WithObsFold(true))
+			require.NoError(t, err)
+
+			marshaled, err := sfv.Marshal(dict)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, string(marshaled))
+		})
+	}
+
+	t.Run("line break without whitespace", func(t *testing.T) {
+		_, err := sfv.ParseDictionary([]byte("a=\"foo\r\nbar\""), sfv.WithObsFold(true))
+		require.Error(t, err)
+	})
+}
+
+func TestTypeString(t *testing.T) {
+	tests := []struct {
+		typ      sfv.Type
+		expected string
+	}{
+		{sfv.InvalidType, "invalid"},
+		{sfv.IntegerType, "integer"},
+		{sfv.DecimalType, "decimal"},
+		{sfv.StringType, "string"},
+		{sfv.TokenType, "token"},
+		{sfv.ByteSequenceType, "byte sequence"},
+		{sfv.BooleanType, "boolean"},
+		{sfv.DateType, "date"},
+		{sfv.DisplayStringType, "display string"},
+		{sfv.Type(42), "Type(42)"},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.expected, test.typ.String())
+	}
+
+	item, err := sfv.ParseItemString(`foo`)
+	require.NoError(t, err)
+	require.Equal(t, "token", item.Type().String())
+}
+
+func TestItemTypePredicates(t *testing.T) {
+	predicates := func(item sfv.CoreItem) []bool {
+		return []bool{
+			item.IsInteger(),
+			item.IsDecimal(),
+			item.IsString(),
+			item.IsToken(),
+			item.IsByteSequence(),
+			item.IsBoolean(),
+			item.IsDate(),
+			item.IsDisplayString(),
+		}
+	}
+
+	inputs := []string{`1`, `1.5`, `"s"`, `t`, `:AQID:`, `?1`, `@1`, `%"d"`}
+	for i, input := range inputs {
+		item, err := sfv.ParseItemString(input + `;p=1`)
+		require.NoError(t, err)
+
+		expected := make([]bool, len(inputs))
+		expected[i] = true
+		require.Equal(t, expected, predicates(item), "item %s", input)
+
+		bare, err := sfv.ParseBareItem([]byte(input))
+		require.NoError(t, err)
+		require.Equal(t, expected, predicates(bare), "bare item %s", input)
+	}
+}
+
+func TestItemTypedAccessors(t *testing.T) {
+	for _, input := range []string{`"s"`, `s`, `%"s"`} {
+		item, err := sfv.ParseItemString(input + `;p`)
+		require.NoError(t, err)
+		s, ok := item.AsString()
+		require.True(t, ok, "%s", input)
+		require.Equal(t, "s", s)
+		_, ok = item.AsInt64()
+		require.False(t, ok)
+	}
+
+	item, err := sfv.ParseItemString(`42`)
+	require.NoError(t, err)
+	n, ok := item.AsInt64()
+	require.True(t, ok)
+	require.Equal(t, int64(42), n)
+	_, ok = item.AsString()
+	require.False(t, ok)
+
+	item, err = sfv.ParseItemString(`:AQID:`)
+	require.NoError(t, err)
+	b, ok := item.AsBytes()
+	require.True(t, ok)
+	require.Equal(t, []byte{1, 2, 3}, b)
+	_, ok = item.AsBool()
+	require.False(t, ok)
+
+	v, ok := sfv.False().AsBool()
+	require.True(t, ok)
+	require.False(t, v)
+	v, ok = sfv.True().ToItem().AsBool()
+	require.True(t, ok)
+	require.True(t, v)
+
+	for _, input := range []string{`@42`, `4.2`} {
+		bare, err := sfv.ParseBareItem([]byte(input))
+		require.NoError(t, err)
+		_, ok := bare.AsInt64()
+		require.False(t, ok, "%s is not an integer", input)
+		_, ok = bare.AsString()
+		require.False(t, ok)
+		_, ok = bare.AsBytes()
+		require.False(t, ok)
+		_, ok = bare.AsBool()
+		require.False(t, ok)
+	}
+}
+
+func TestGetValueNumericConversions(t *testing.T) {
+	type count uint16
+
+	item, err := sfv.ParseItemString(`300;p=-1`)
+	require.NoError(t, err)
+
+	var i int
+	require.NoError(t, item.GetValue(&i))
+	require.Equal(t, 300, i)
+
+	var i32 int32
+	require.NoError(t, item.GetValue(&i32))
+	require.Equal(t, int32(300), i32)
+
+	var u64 uint64
+	require.NoError(t, item.GetValue(&u64))
+	require.Equal(t, uint64(300), u64)
+
+	var c count
+	require.NoError(t, item.GetValue(&c))
+	require.Equal(t, count(300), c)
+
+	var i8 int8
+	require.Error(t, item.GetValue(&i8), "300 overflows int8")
+	require.Zero(t, i8)
+
+	var u8 uint8
+	require.Error(t, item.GetValue(&u8), "300 overflows uint8")
+
+	neg, ok := item.Parameters().Lookup("p")
+	require.True(t, ok)
+	require.Error(t, neg.GetValue(&u64), "negative values do not fit in unsigned types")
+	require.NoError(t, neg.GetValue(&i8))
+	require.Equal(t, int8(-1), i8)
+
+	var s string
+	require.Error(t, item.GetValue(&s))
+
+	dec, err := sfv.ParseItemString(`1.25`)
+	require.NoError(t, err)
+	var f32 float32
+	require.NoError(t, dec.GetValue(&f32))
+	require.InDelta(t, float32(1.25), f32, 0)
+	var f64 float64
+	require.NoError(t, dec.GetValue(&f64))
+	require.InDelta(t, 1.25, f64, 0)
+	var v any
+	require.NoError(t, dec.GetValue(&v))
+	require.Equal(t, 1.25, v)
+	require.Error(t, dec.GetValue(&i))
+}
+
+func TestGetValueDate(t *testing.T) {
+	item, err := sfv.ParseItemString(`@1659578233;p=@0`)
+	require.NoError(t, err)
+
+	var tm time.Time
+	require.NoError(t, item.GetValue(&tm))
+	require.True(t, tm.Equal(time.Date(2022, time.August, 4, 1, 57, 13, 0, time.UTC)))
+	require.Equal(t, time.UTC, tm.Location())
+
+	var ts int64
+	require.NoError(t, item.GetValue(&ts))
+	require.Equal(t, int64(1659578233), ts)
+
+	dict, err := sfv.ParseDictionaryString(`created=@1659578233`)
+	require.NoError(t, err)
+	member, err := dict.GetItem("created")
+	require.NoError(t, err)
+	var created time.Time
+	require.NoError(t, member.GetValue(&created))
+	require.Equal(t, int64(1659578233), created.Unix())
+
+	// other types cannot be assigned to a time.Time
+	num, err := sfv.ParseItemString(`1659578233`)
+	require.NoError(t, err)
+	require.Error(t, num.GetValue(&tm))
+}
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		name     string
+		parse    func() (any, error)
+		expected sfv.FieldType
+	}{
+		{"list", func() (any, error) { return sfv.ParseString(`a, b`) }, sfv.ListField},
+		{"dictionary", func() (any, error) { return sfv.ParseString(`a=1, b`) }, sfv.DictionaryField},
+		{"item", func() (any, error) { return sfv.ParseItemString(`a;x=1`) }, sfv.ItemField},
+		{"bare item", func() (any, error) { return sfv.ParseBareItem([]byte(`?1`)) }, sfv.ItemField},
+		{"inner list", func() (any, error) { return sfv.ParseInnerList([]byte(`(a b)`)) }, sfv.UnknownField},
+		{"inner field", func() (any, error) { return sfv.BareString(`a=1`).ParseInner(sfv.UnknownField) }, sfv.DictionaryField},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := test.parse()
+			require.NoError(t, err)
+			require.Equal(t, test.expected, sfv.KindOf(v))
+		})
+	}
+
+	require.Equal(t, sfv.UnknownField, sfv.KindOf(nil))
+	require.Equal(t, sfv.UnknownField, sfv.KindOf((*sfv.Dictionary)(nil)))
+	require.Equal(t, sfv.UnknownField, sfv.KindOf(42))
+	require.Equal(t, sfv.ListField, sfv.KindOf(sfv.List{}))
+
+	item, err := sfv.ParseItemString(`a`)
+	require.NoError(t, err)
+	require.Equal(t, sfv.ItemField, item.Kind())
+	require.Equal(t, sfv.ListField, (&sfv.List{}).Kind())
+	require.Equal(t, sfv.DictionaryField, sfv.NewDictionary().Kind())
+
+	require.Equal(t, "dictionary", sfv.DictionaryField.String())
+	require.Equal(t, "FieldType(42)", sfv.FieldType(42).String())
+}
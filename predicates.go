@@ -0,0 +1,86 @@
+package sfv
+
+// The Is* methods of items report whether the item is of the given type.
+// They are shorthands for comparing the result of Type with one of the
+// type constants, as in item.Type() == IntegerType.
+
+func (fi *FullItem[BT, UT]) IsInteger() bool       { return fi.Type() == IntegerType }
+func (fi *FullItem[BT, UT]) IsDecimal() bool       { return fi.Type() == DecimalType }
+func (fi *FullItem[BT, UT]) IsString() bool        { return fi.Type() == StringType }
+func (fi *FullItem[BT, UT]) IsToken() bool         { return fi.Type() == TokenType }
+func (fi *FullItem[BT, UT]) IsByteSequence() bool  { return fi.Type() == ByteSequenceType }
+func (fi *FullItem[BT, UT]) IsBoolean() bool       { return fi.Type() == BooleanType }
+func (fi *FullItem[BT, UT]) IsDate() bool          { return fi.Type() == DateType }
+func (fi *FullItem[BT, UT]) IsDisplayString() bool { return fi.Type() == DisplayStringType }
+
+func (i IntegerBareItem) IsInteger() bool       { return i.Type() == IntegerType }
+func (i IntegerBareItem) IsDecimal() bool       { return i.Type() == DecimalType }
+func (i IntegerBareItem) IsString() bool        { return i.Type() == StringType }
+func (i IntegerBareItem) IsToken() bool         { return i.Type() == TokenType }
+func (i IntegerBareItem) IsByteSequence() bool  { return i.Type() == ByteSequenceType }
+func (i IntegerBareItem) IsBoolean() bool       { return i.Type() == BooleanType }
+func (i IntegerBareItem) IsDate() bool          { return i.Type() == DateType }
+func (i IntegerBareItem) IsDisplayString() bool { return i.Type() == DisplayStringType }
+
+func (d DecimalBareItem) IsInteger() bool       { return d.Type() == IntegerType }
+func (d DecimalBareItem) IsDecimal() bool       { return d.Type() == DecimalType }
+func (d DecimalBareItem) IsString() bool        { return d.Type() == StringType }
+func (d DecimalBareItem) IsToken() bool         { return d.Type() == TokenType }
+func (d DecimalBareItem) IsByteSequence() bool  { return d.Type() == ByteSequenceType }
+func (d DecimalBareItem) IsBoolean() bool       { return d.Type() == BooleanType }
+func (d DecimalBareItem) IsDate() bool          { return d.Type() == DateType }
+func (d DecimalBareItem) IsDisplayString() bool { return d.Type() == DisplayStringType }
+
+func (s StringBareItem) IsInteger() bool       { return s.Type() == IntegerType }
+func (s StringBareItem) IsDecimal() bool       { return s.Type() == DecimalType }
+func (s StringBareItem) IsString() bool        { return s.Type() == StringType }
+func (s StringBareItem) IsToken() bool         { return s.Type() == TokenType }
+func (s StringBareItem) IsByteSequence() bool  { return s.Type() == ByteSequenceType }
+func (s StringBareItem) IsBoolean() bool       { return s.Type() == BooleanType }
+func (s StringBareItem) IsDate() bool          { return s.Type() == DateType }
+func (s StringBareItem) IsDisplayString() bool { return s.Type() == DisplayStringType }
+
+func (t TokenBareItem) IsInteger() bool       { return t.Type() == IntegerType }
+func (t TokenBareItem) IsDecimal() bool       { return t.Type() == DecimalType }
+func (t TokenBareItem) IsString() bool        { return t.Type() == StringType }
+func (t TokenBareItem) IsToken() bool         { return t.Type() == TokenType }
+func (t TokenBareItem) IsByteSequence() bool  { return t.Type() == ByteSequenceType }
+func (t TokenBareItem) IsBoolean() bool       { return t.Type() == BooleanType }
+func (t TokenBareItem) IsDate() bool          { return t.Type() == DateType }
+func (t TokenBareItem) IsDisplayString() bool { return t.Type() == DisplayStringType }
+
+func (b ByteSequenceBareItem) IsInteger() bool       { return b.Type() == IntegerType }
+func (b ByteSequenceBareItem) IsDecimal() bool       { return b.Type() == DecimalType }
+func (b ByteSequenceBareItem) IsString() bool        { return b.Type() == StringType }
+func (b ByteSequenceBareItem) IsToken() bool         { return b.Type() == TokenType }
+func (b ByteSequenceBareItem) IsByteSequence() bool  { return b.Type() == ByteSequenceType }
+func (b ByteSequenceBareItem) IsBoolean() bool       { return b.Type() == BooleanType }
+func (b ByteSequenceBareItem) IsDate() bool          { return b.Type() == DateType }
+func (b ByteSequenceBareItem) IsDisplayString() bool { return b.Type() == DisplayStringType }
+
+func (b BooleanBareItem) IsInteger() bool       { return b.Type() == IntegerType }
+func (b BooleanBareItem) IsDecimal() bool       { return b.Type() == DecimalType }
+func (b BooleanBareItem) IsString() bool        { return b.Type() == StringType }
+func (b BooleanBareItem) IsToken() bool         { return b.Type() == TokenType }
+func (b BooleanBareItem) IsByteSequence() bool  { return b.Type() == ByteSequenceType }
+func (b BooleanBareItem) IsBoolean() bool       { return b.Type() == BooleanType }
+func (b BooleanBareItem) IsDate() bool          { return b.Type() == DateType }
+func (b BooleanBareItem) IsDisplayString() bool { return b.Type() == DisplayStringType }
+
+func (d DateBareItem) IsInteger() bool       { return d.Type() == IntegerType }
+func (d DateBareItem) IsDecimal() bool       { return d.Type() == DecimalType }
+func (d DateBareItem) IsString() bool        { return d.Type() == StringType }
+func (d DateBareItem) IsToken() bool         { return d.Type() == TokenType }
+func (d DateBareItem) IsByteSequence() bool  { return d.Type() == ByteSequenceType }
+func (d DateBareItem) IsBoolean() bool       { return d.Type() == BooleanType }
+func (d DateBareItem) IsDate() bool          { return d.Type() == DateType }
+func (d DateBareItem) IsDisplayString() bool { return d.Type() == DisplayStringType }
+
+func (d DisplayStringBareItem) IsInteger() bool       { return d.Type() == IntegerType }
+func (d DisplayStringBareItem) IsDecimal() bool       { return d.Type() == DecimalType }
+func (d DisplayStringBareItem) IsString() bool        { return d.Type() == StringType }
+func (d DisplayStringBareItem) IsToken() bool         { return d.Type() == TokenType }
+func (d DisplayStringBareItem) IsByteSequence() bool  { return d.Type() == ByteSequenceType }
+func (d DisplayStringBareItem) IsBoolean() bool       { return d.Type() == BooleanType }
+func (d DisplayStringBareItem) IsDate() bool          { return d.Type() == DateType }
+func (d DisplayStringBareItem) IsDisplayString() bool { return d.Type() == DisplayStringType }
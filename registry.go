@@ -0,0 +1,56 @@
+package sfv
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// MarshalFunc converts a Go value to an SFV value. See RegisterMarshaler.
+type MarshalFunc func(any) (Value, error)
+
+// marshalFuncs maps reflect.Type to MarshalFunc
+var marshalFuncs sync.Map
+
+// RegisterMarshaler registers fn as the function used to convert values
+// of type typ when they are marshaled using Marshal or an Encoder. This
+// allows applications to teach the converter about types they do not own,
+// such as UUIDs or netip.Addr, without wrapping every value in a type that
+// implements Marshaler. fn is called with values of type typ, and takes
+// precedence over any other conversion, including encoding.TextMarshaler.
+// Values that implement Marshaler themselves are not affected.
+//
+// When typ is not a pointer type, fn is also used for pointers to values
+// of type typ. Passing a nil fn removes the function registered for typ.
+// The registry is global, and is usually populated from init functions.
+func RegisterMarshaler(typ reflect.Type, fn MarshalFunc) {
+	if fn == nil {
+		marshalFuncs.Delete(typ)
+		return
+	}
+	marshalFuncs.Store(typ, fn)
+}
+
+// lookupMarshaler calls the MarshalFunc registered for the type of rv, or
+// the type of the values rv points to. The second return value is false
+// if no function is registered.
+func lookupMarshaler(rv reflect.Value) (Value, bool, error) {
+	for {
+		if fn, ok := marshalFuncs.Load(rv.Type()); ok {
+			//nolint:forcetypeassert
+			v, err := fn.(MarshalFunc)(rv.Interface())
+			if err != nil {
+				return nil, true, fmt.Errorf("failed to marshal %s: %w", rv.Type(), err)
+			}
+			if v == nil {
+				return nil, true, fmt.Errorf("marshal function for %s returned a nil value", rv.Type())
+			}
+			return v, true, nil
+		}
+
+		if rv.Kind() != reflect.Ptr || rv.IsNil() {
+			return nil, false, nil
+		}
+		rv = rv.Elem()
+	}
+}
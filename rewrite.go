@@ -0,0 +1,288 @@
+package sfv
+
+import "fmt"
+
+// RewriteRule is a single change applied by Rewrite. Use RenameKey,
+// DropParameter, and ReplaceValue to create them.
+type RewriteRule interface {
+	rewrite(e *rewriteEntry) error
+}
+
+// rewriteEntry is a Dictionary member, a List or Inner List member, or a
+// parameter, as seen by the rules
+type rewriteEntry struct {
+	path  Path     // location of the entry in the input
+	param bool     // true for parameters
+	key   string   // key of Dictionary members and parameters
+	value BareItem // value of items and parameters, nil for Inner Lists
+	drop  bool
+}
+
+type renameKeyRule struct {
+	from string
+	to   string
+}
+
+// RenameKey returns a rule that renames the Dictionary members and the
+// parameters called from to to. If there already is a member or a
+// parameter called to, the renamed one replaces it, keeping the position
+// of the first of the two. It is an error for Rewrite if several members
+// or parameters of the same value are renamed to the same key.
+func RenameKey(from, to string) RewriteRule {
+	return &renameKeyRule{from: from, to: to}
+}
+
+func (r *renameKeyRule) rewrite(e *rewriteEntry) error {
+	if e.key != r.from {
+		return nil
+	}
+	if !isValidKey(r.to) {
+		return fmt.Errorf("cannot rename %q: invalid key %q", r.from, r.to)
+	}
+	e.key = r.to
+	return nil
+}
+
+type dropParameterRule struct {
+	key string
+}
+
+// DropParameter returns a rule that removes the parameters called key,
+// wherever they appear.
+func DropParameter(key string) RewriteRule {
+	return &dropParameterRule{key: key}
+}
+
+func (r *dropParameterRule) rewrite(e *rewriteEntry) error {
+	if e.param && e.key == r.key {
+		e.drop = true
+	}
+	return nil
+}
+
+type replaceValueRule struct {
+	match       func(path Path, value BareItem) bool
+	replacement BareItem
+}
+
+// ReplaceValue returns a rule that replaces the values of the Items and
+// parameters for which match returns true with a copy of replacement.
+// Item parameters are kept. match is called with the path of the value in
+// the input, as reported by Walk.
+func ReplaceValue(match func(path Path, value BareItem) bool, replacement BareItem) RewriteRule {
+	return &replaceValueRule{match: match, replacement: replacement}
+}
+
+func (r *replaceValueRule) rewrite(e *rewriteEntry) error {
+	if e.value == nil || !r.match(e.path, e.value) {
+		return nil
+	}
+	if r.replacement == nil {
+		return fmt.Errorf("cannot replace %q: replacement is nil", e.path)
+	}
+	e.value = r.replacement
+	return nil
+}
+
+// Rewrite returns a copy of v with rules applied to each Dictionary
+// member, List and Inner List member, and parameter, in the order given.
+// v can be a List, a Dictionary, an Inner List, an Item, or a BareItem.
+// v itself is not modified, and does not share any value with the
+// result, which is not frozen even if v is. Text and locations recorded
+// by WithRawText and WithSpans are not carried over.
+//
+// It is an error if a rule cannot be applied, or if the result is not of
+// type T anymore, such as when ReplaceValue changes the type of a
+// *sfv.IntegerItem.
+func Rewrite[T any](v T, rules ...RewriteRule) (T, error) {
+	var zero T
+	rw := rewriter{rules: rules}
+	var out any
+	var err error
+	switch v := any(v).(type) {
+	case *Dictionary:
+		out, err = rw.dictionary(nil, v)
+	case *List:
+		out, err = rw.list(nil, v)
+	case *InnerList:
+		out, err = rw.innerList(nil, v)
+	case CoreItem:
+		out, err = rw.item(nil, v)
+	default:
+		return zero, fmt.Errorf("cannot rewrite value of type %T", v)
+	}
+	if err != nil {
+		return zero, err
+	}
+	ret, ok := out.(T)
+	if !ok {
+		return zero, fmt.Errorf("rewritten value is of type %T, not %T", out, v)
+	}
+	return ret, nil
+}
+
+type rewriter struct {
+	rules []RewriteRule
+}
+
+func (rw *rewriter) apply(e *rewriteEntry) error {
+	for _, rule := range rw.rules {
+		if err := rule.rewrite(e); err != nil {
+			return err
+		}
+		if e.drop {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (rw *rewriter) dictionary(path Path, d *Dictionary) (*Dictionary, error) {
+	c := NewDictionary()
+	var renamed map[string]bool
+	for key, member := range d.All() {
+		mpath := path.append(PathElement{Kind: MemberKeyElement, Key: key})
+		member, newKey, err := rw.member(mpath, key, member)
+		if err != nil {
+			return nil, err
+		}
+		if err := setRewritten(&c.members, &renamed, mpath, key, newKey, member); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// setRewritten sets the entry originally called key under newKey in m.
+// Renamed entries replace the entries that already had their new key,
+// wherever they appear in the input, so renamed tracks the keys of the
+// entries that were renamed.
+func setRewritten[V any](m *OrderedMap[string, V], renamed *map[string]bool, path Path, key, newKey string, value V) error {
+	if newKey == key {
+		if (*renamed)[key] {
+			// a renamed entry already took this key
+			return nil
+		}
+		m.Set(key, value)
+		return nil
+	}
+	if (*renamed)[newKey] {
+		return fmt.Errorf("%s: cannot rename %q: another key was already renamed to %q", path, key, newKey)
+	}
+	if *renamed == nil {
+		*renamed = make(map[string]bool)
+	}
+	(*renamed)[newKey] = true
+	m.Set(newKey, value)
+	return nil
+}
+
+func (rw *rewriter) list(path Path, l *List) (*List, error) {
+	c := &List{values: make([]any, 0, l.Len())}
+	for i, member := range l.All() {
+		member, _, err := rw.member(path.append(PathElement{Kind: MemberIndexElement, Index: i}), "", member)
+		if err != nil {
+			return nil, err
+		}
+		c.values = append(c.values, member)
+	}
+	return c, nil
+}
+
+// member rewrites a Dictionary or List member, and returns it along with
+// its new key
+func (rw *rewriter) member(path Path, key string, member any) (any, string, error) {
+	e := rewriteEntry{path: path, key: key}
+	if _, ok := member.(*InnerList); !ok {
+		cv, _ := member.(CoreItem)
+		bare, ok := bareOf(cv).(BareItem)
+		if !ok {
+			return nil, "", fmt.Errorf("%s: unsupported member type %T", path, member)
+		}
+		e.value = bare
+	}
+	if err := rw.apply(&e); err != nil {
+		return nil, "", fmt.Errorf("%s: %w", path, err)
+	}
+
+	switch member := member.(type) {
+	case *InnerList:
+		il, err := rw.innerList(path, member)
+		return il, e.key, err
+	case Item:
+		item, err := rw.itemWithValue(path, e.value, member.Parameters())
+		return item, e.key, err
+	default:
+		// bare items in Dictionaries stay bare
+		return cloneBareItem(e.value), e.key, nil
+	}
+}
+
+func (rw *rewriter) innerList(path Path, il *InnerList) (*InnerList, error) {
+	c := NewInnerList()
+	for i, item := range il.All() {
+		ipath := path.append(PathElement{Kind: MemberIndexElement, Index: i})
+		bare, ok := bareOf(item).(BareItem)
+		if !ok {
+			return nil, fmt.Errorf("%s: unsupported item type %T", ipath, item)
+		}
+		e := rewriteEntry{path: ipath, value: bare}
+		if err := rw.apply(&e); err != nil {
+			return nil, fmt.Errorf("%s: %w", ipath, err)
+		}
+		rewritten, err := rw.itemWithValue(ipath, e.value, item.Parameters())
+		if err != nil {
+			return nil, err
+		}
+		c.values = append(c.values, rewritten)
+	}
+	params, err := rw.parameters(path, il.Parameters())
+	if err != nil {
+		return nil, err
+	}
+	c.params = params
+	return c, nil
+}
+
+// item rewrites a top-level Item or BareItem
+func (rw *rewriter) item(path Path, v CoreItem) (any, error) {
+	bare, ok := bareOf(v).(BareItem)
+	if !ok {
+		return nil, fmt.Errorf("unsupported item type %T", v)
+	}
+	e := rewriteEntry{path: path, value: bare}
+	if err := rw.apply(&e); err != nil {
+		return nil, err
+	}
+	if item, ok := v.(Item); ok {
+		return rw.itemWithValue(path, e.value, item.Parameters())
+	}
+	return cloneBareItem(e.value), nil
+}
+
+func (rw *rewriter) itemWithValue(path Path, value BareItem, params *Parameters) (Item, error) {
+	rewritten, err := rw.parameters(path, params)
+	if err != nil {
+		return nil, err
+	}
+	return cloneBareItem(value).ToItem().With(rewritten), nil
+}
+
+func (rw *rewriter) parameters(path Path, params *Parameters) (*Parameters, error) {
+	c := NewParameters()
+	var renamed map[string]bool
+	for key, value := range params.All() {
+		ppath := path.append(PathElement{Kind: ParameterElement, Key: key})
+		e := rewriteEntry{path: ppath, param: true, key: key, value: value}
+		if err := rw.apply(&e); err != nil {
+			return nil, fmt.Errorf("%s: %w", ppath, err)
+		}
+		if e.drop {
+			continue
+		}
+		if err := setRewritten(&c.entries, &renamed, ppath, key, e.key, cloneBareItem(e.value)); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
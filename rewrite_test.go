@@ -0,0 +1,118 @@
+package sfv_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewrite(t *testing.T) {
+	t.Run("dictionary", func(t *testing.T) {
+		const input = `sig1=("@method" "@path";req);created=1618884473;keyid="k", b=?0;keyid="k"`
+		dict, err := sfv.ParseDictionaryString(input, sfv.WithRawText(true))
+		require.NoError(t, err)
+		dict.Freeze()
+
+		out, err := sfv.Rewrite(dict,
+			sfv.RenameKey("sig1", "sig"),
+			sfv.DropParameter("keyid"),
+			sfv.RenameKey("req", "request"),
+		)
+		require.NoError(t, err)
+		require.Equal(t, `sig=("@method" "@path";request);created=1618884473, b=?0`, marshalString(t, out))
+		require.False(t, out.Frozen())
+		require.Nil(t, out.Raw())
+
+		require.Equal(t, input, marshalString(t, dict), "the input should not be modified")
+	})
+	t.Run("replace value", func(t *testing.T) {
+		v, err := sfv.ParseString(`"secret";token="abc", public, ("x" y);token="def"`)
+		require.NoError(t, err)
+		list := v.(*sfv.List)
+
+		redact := sfv.ReplaceValue(func(path sfv.Path, value sfv.BareItem) bool {
+			return (path.IsParameter() && path[len(path)-1].Key == "token") || value.IsString()
+		}, sfv.BareToken("redacted"))
+		out, err := sfv.Rewrite(list, redact)
+		require.NoError(t, err)
+		require.Equal(t, `redacted;token=redacted, public, (redacted y);token=redacted`, marshalString(t, out))
+		require.Equal(t, `"secret";token="abc", public, ("x" y);token="def"`, marshalString(t, list))
+
+		// the result does not share values with the input
+		item, _ := out.Get(1)
+		require.NoError(t, item.(sfv.Item).Parameters().Set("p", 1))
+		require.Equal(t, `public`, marshalString(t, mustGet(t, list, 1)))
+	})
+	t.Run("rename onto an existing key", func(t *testing.T) {
+		// the renamed entry wins, whether it comes before or after the
+		// existing one, and takes the position of the first of the two
+		item, err := sfv.ParseItemString(`a;x=1;y=2;z=3`)
+		require.NoError(t, err)
+		out, err := sfv.Rewrite(item, sfv.RenameKey("z", "x"))
+		require.NoError(t, err)
+		require.Equal(t, `a;x=3;y=2`, marshalString(t, out))
+
+		item, err = sfv.ParseItemString(`a;x=1;y=2;z=3`)
+		require.NoError(t, err)
+		out, err = sfv.Rewrite(item, sfv.RenameKey("x", "z"))
+		require.NoError(t, err)
+		require.Equal(t, `a;z=1;y=2`, marshalString(t, out))
+
+		dict, err := sfv.ParseDictionaryString(`a=1;x=2, b=(c d);x=3`)
+		require.NoError(t, err)
+		renamed, err := sfv.Rewrite(dict, sfv.RenameKey("a", "b"))
+		require.NoError(t, err)
+		require.Equal(t, `b=1;x=2`, marshalString(t, renamed))
+
+		renamed, err = sfv.Rewrite(dict, sfv.RenameKey("b", "a"))
+		require.NoError(t, err)
+		require.Equal(t, `a=(c d);x=3`, marshalString(t, renamed))
+
+		// swapping keys loses nothing
+		renamed, err = sfv.Rewrite(dict, sfv.RenameKey("a", "tmp"), sfv.RenameKey("b", "a"), sfv.RenameKey("tmp", "b"))
+		require.NoError(t, err)
+		require.Equal(t, `b=1;x=2, a=(c d);x=3`, marshalString(t, renamed))
+	})
+	t.Run("several keys renamed to the same key", func(t *testing.T) {
+		dict, err := sfv.ParseDictionaryString(`a=1, b=2, c=3`)
+		require.NoError(t, err)
+		_, err = sfv.Rewrite(dict, sfv.RenameKey("a", "c"), sfv.RenameKey("b", "c"))
+		require.Error(t, err)
+
+		item, err := sfv.ParseItemString(`a;x=1;y=2`)
+		require.NoError(t, err)
+		_, err = sfv.Rewrite(item, sfv.RenameKey("x", "z"), sfv.RenameKey("y", "z"))
+		require.Error(t, err)
+	})
+	t.Run("errors", func(t *testing.T) {
+		dict, err := sfv.ParseDictionaryString(`a=1;x`)
+		require.NoError(t, err)
+		_, err = sfv.Rewrite(dict, sfv.RenameKey("x", "Bad"))
+		require.Error(t, err)
+		_, err = sfv.Rewrite(dict, sfv.ReplaceValue(func(sfv.Path, sfv.BareItem) bool { return true }, nil))
+		require.Error(t, err)
+
+		item, err := sfv.ParseItemString(`1`)
+		require.NoError(t, err)
+		integer, ok := item.(*sfv.IntegerItem)
+		require.True(t, ok)
+		_, err = sfv.Rewrite(integer, sfv.ReplaceValue(func(sfv.Path, sfv.BareItem) bool { return true }, sfv.BareToken("a")))
+		require.Error(t, err, "the result is not an *IntegerItem anymore")
+		out, err := sfv.Rewrite(item, sfv.ReplaceValue(func(sfv.Path, sfv.BareItem) bool { return true }, sfv.BareToken("a")))
+		require.NoError(t, err)
+		require.Equal(t, `a`, marshalString(t, out))
+
+		_, err = sfv.Rewrite(42)
+		require.Error(t, err)
+	})
+}
+
+func mustGet(t *testing.T, l *sfv.List, i int) sfv.Marshaler {
+	t.Helper()
+	v, ok := l.Get(i)
+	require.True(t, ok)
+	m, ok := v.(sfv.Marshaler)
+	require.True(t, ok)
+	return m
+}
@@ -8,6 +8,13 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// canonicalSerialization returns the serialization of the RFC examples
+// used in this file, which put a space after the semicolons that precede
+// parameters. RFC 9651 Section 4.1 serializes parameters without it.
+func canonicalSerialization(s string) string {
+	return strings.ReplaceAll(s, "; ", ";")
+}
+
 // TestRFC9651Examples tests all examples from RFC 9651
 func TestRFC9651Examples(t *testing.T) {
 	tests := []struct {
@@ -249,7 +256,7 @@ func TestRFC9651Examples(t *testing.T) {
 			// Roundtrip test: marshal should produce the same serialization
 			marshaled, err := sfv.Marshal(result)
 			require.NoError(t, err, "Marshal(%q) failed", test.input)
-			require.Equal(t, test.input, string(marshaled), "Marshal result should match original input")
+			require.Equal(t, canonicalSerialization(test.input), string(marshaled), "Marshal result should match original input")
 		})
 	}
 }
@@ -259,7 +266,7 @@ func TestRFC9651SpecificExamples(t *testing.T) {
 	tests := []struct {
 		name           string
 		input          string
-		expectedType   int
+		expectedType   sfv.Type
 		expectedValue  any
 		expectedParams map[string]any
 	}{
@@ -365,7 +372,7 @@ func TestRFC9651SpecificExamples(t *testing.T) {
 			// Roundtrip test: marshal should produce the same serialization
 			marshaled, err := sfv.Marshal(result)
 			require.NoError(t, err, "Marshal(%q) failed", test.input)
-			require.Equal(t, test.input, string(marshaled), "Marshal result should match original input")
+			require.Equal(t, canonicalSerialization(test.input), string(marshaled), "Marshal result should match original input")
 		})
 	}
 }
@@ -431,7 +438,7 @@ func TestRFC9651InnerLists(t *testing.T) {
 			// Roundtrip test: marshal should produce the same serialization
 			marshaled, err := sfv.Marshal(result)
 			require.NoError(t, err, "Marshal(%q) failed", test.input)
-			require.Equal(t, test.input, string(marshaled), "Marshal result should match original input")
+			require.Equal(t, canonicalSerialization(test.input), string(marshaled), "Marshal result should match original input")
 		})
 	}
 }
@@ -482,7 +489,7 @@ func TestRFC9651Parameters(t *testing.T) {
 			// Roundtrip test: marshal should produce the same serialization
 			marshaled, err := sfv.Marshal(result)
 			require.NoError(t, err, "Marshal(%q) failed", test.input)
-			require.Equal(t, test.input, string(marshaled), "Marshal result should match original input")
+			require.Equal(t, canonicalSerialization(test.input), string(marshaled), "Marshal result should match original input")
 		})
 	}
 }
@@ -1,11 +1,16 @@
 package sfv
 
 import (
+	"bytes"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"log/slog"
 	"strconv"
-	"strings"
+	"sync"
 	"unicode"
+	"unicode/utf8"
+	"unsafe"
 
 	"github.com/lestrrat-go/sfv/internal/tokens"
 )
@@ -24,49 +29,174 @@ const (
 	maxSFVInteger    = 999999999999999
 )
 
+// RFC 9651 Section 3.3.2: Decimals have at most 12 digits in the
+// integer component
+const maxDecimalIntegerDigits = 12
+
 const (
 	parseModeDefault = 0
 
 	parseModeList = iota // parseModeDefault == parseModelist
 	parseModeDictionary
 	parseModeItem
+	parseModeBareItem
+	parseModeInnerList
 )
 
+// FieldType specifies the top-level type of a structured field. Since
+// RFC 9651 leaves it to each field definition to specify whether it is
+// a List, a Dictionary, or an Item, callers that know which field they
+// are dealing with should say so explicitly.
+type FieldType int
+
+const (
+	// UnknownField asks the parser to guess the type of the field,
+	// in the same way Parse does: the input is parsed as a Dictionary
+	// if it looks like one, and as a List otherwise.
+	UnknownField FieldType = iota
+	ListField
+	DictionaryField
+	ItemField
+)
+
+// String returns the name of the field type in lowercase, such as
+// "dictionary".
+func (ft FieldType) String() string {
+	switch ft {
+	case UnknownField:
+		return "unknown"
+	case ListField:
+		return "list"
+	case DictionaryField:
+		return "dictionary"
+	case ItemField:
+		return "item"
+	default:
+		return "FieldType(" + strconv.Itoa(int(ft)) + ")"
+	}
+}
+
+func (ft FieldType) parseMode() (int, error) {
+	switch ft {
+	case UnknownField:
+		return parseModeDefault, nil
+	case ListField:
+		return parseModeList, nil
+	case DictionaryField:
+		return parseModeDictionary, nil
+	case ItemField:
+		return parseModeItem, nil
+	default:
+		return 0, fmt.Errorf("sfv: unknown field type %d", ft)
+	}
+}
+
 type parseContext struct {
 	idx   int // current index in the data
 	size  int // size of the data
 	mode  int
 	data  []byte
 	value any // the parsed value, if any
+
+	strictByteSequence   bool
+	lenientDisplayString bool
+	eagerByteSequence    bool
+	internTable          *InternTable
+	aggregateErrors      bool
+	recordRaw            bool
+	recordSpans          bool
+	obsFold              bool
+	logger               *slog.Logger
+
+	// errors collects member-level errors when aggregateErrors is enabled
+	errors []error
+
+	// scratch is a reusable buffer for accumulating bytes while parsing
+	// individual bare items. It is retained across parses via parseContextPool
+	scratch []byte
 }
 
-func Parse(data []byte) (any, error) {
-	return parse(data, parseModeDefault)
+// maxPooledScratchSize is the largest scratch buffer that is returned to
+// the pool. Larger buffers are dropped so that a single huge field does not
+// pin memory forever.
+const maxPooledScratchSize = 64 * 1024
+
+var parseContextPool = sync.Pool{
+	New: func() any {
+		return &parseContext{scratch: make([]byte, 0, 64)}
+	},
+}
+
+func getParseContext() *parseContext {
+	return parseContextPool.Get().(*parseContext) //nolint:forcetypeassert
+}
+
+func releaseParseContext(pctx *parseContext) {
+	scratch := pctx.scratch[:0]
+	if cap(scratch) > maxPooledScratchSize {
+		scratch = nil
+	}
+	*pctx = parseContext{scratch: scratch}
+	parseContextPool.Put(pctx)
+}
+
+func Parse(data []byte, options ...ParseOption) (any, error) {
+	if len(options) == 0 {
+		if item, ok := parseTrivialItem(data); ok {
+			return &List{values: []any{item}}, nil
+		}
+	}
+	return parse(data, parseModeDefault, options)
 }
 
-func parse(data []byte, mode int) (any, error) {
-	var pctx parseContext
-	pctx.init(data, mode)
+func parse(data []byte, mode int, options []ParseOption) (any, error) {
+	v, _, err := parseWithOffset(data, mode, options)
+	return v, err
+}
+
+// parseWithOffset is the same as parse, but also reports the offset
+// at which parsing stopped, so that callers can point at the location
+// of an error
+func parseWithOffset(data []byte, mode int, options []ParseOption) (any, int, error) {
+	pctx := getParseContext()
+	defer releaseParseContext(pctx)
+
+	pctx.init(data, mode, options)
+	if pctx.logger != nil {
+		debug(pctx.logger, "sfv: parsing field", slog.String("type", parseModeName(mode)), slog.Int("length", pctx.size))
+	}
 	if err := pctx.do(); err != nil {
-		return nil, err
+		if pctx.logger != nil {
+			debug(pctx.logger, "sfv: failed to parse field", slog.Int("offset", pctx.idx), slog.Any("error", err))
+		}
+		// pctx.value is only set here if error aggregation is enabled
+		return pctx.value, pctx.idx, pctx.positionalError(err)
 	}
-	return pctx.value, nil
+	if pctx.logger != nil {
+		debug(pctx.logger, "sfv: parsed field", slog.String("result", fmt.Sprintf("%T", pctx.value)))
+	}
+	return pctx.value, pctx.idx, nil
 }
 
-func ParseDictionary(data []byte) (*Dictionary, error) {
-	v, err := parse(data, parseModeDictionary)
-	if err != nil {
+func ParseDictionary(data []byte, options ...ParseOption) (*Dictionary, error) {
+	v, err := parse(data, parseModeDictionary, options)
+	if v == nil {
 		return nil, err
 	}
 	dict, ok := v.(*Dictionary)
 	if !ok {
 		return nil, fmt.Errorf("expected *Dictionary, got %T", v)
 	}
-	return dict, nil
+	return dict, err
 }
 
-func ParseItem(data []byte) (Item, error) {
-	v, err := parse(data, parseModeItem)
+func ParseItem(data []byte, options ...ParseOption) (Item, error) {
+	if len(options) == 0 {
+		if item, ok := parseTrivialItem(data); ok {
+			return item, nil
+		}
+	}
+	v, err := parse(data, parseModeItem, options)
 	if err != nil {
 		return nil, err
 	}
@@ -77,11 +207,142 @@ func ParseItem(data []byte) (Item, error) {
 	return item, nil
 }
 
-func (pctx *parseContext) init(data []byte, mode int) {
+// ParseBareItem parses data as a single bare item, that is, an item
+// without any parameters. This is useful for protocols that carry bare
+// items in places other than a structured field, such as when re-parsing
+// parameter values. Any input left after the bare item is an error.
+func ParseBareItem(data []byte, options ...ParseOption) (BareItem, error) {
+	v, err := parse(data, parseModeBareItem, options)
+	if err != nil {
+		return nil, err
+	}
+	item, ok := v.(BareItem)
+	if !ok {
+		return nil, fmt.Errorf("expected BareItem, got %T", v)
+	}
+	return item, nil
+}
+
+// ParseInnerList parses data as a single inner list, including any
+// parameters attached to it. This is useful when a single member of a
+// list or dictionary has been extracted by other means, such as one
+// member of a Signature-Input field. Any input left after the inner list
+// is an error.
+func ParseInnerList(data []byte, options ...ParseOption) (*InnerList, error) {
+	v, err := parse(data, parseModeInnerList, options)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := v.(*InnerList)
+	if !ok {
+		return nil, fmt.Errorf("expected *InnerList, got %T", v)
+	}
+	return list, nil
+}
+
+// ParseString is the same as Parse, but takes a string, such as a value
+// taken from an http.Header, as input without copying it to a []byte first.
+func ParseString(s string, options ...ParseOption) (any, error) {
+	return Parse(stringBytes(s), options...)
+}
+
+// ParseDictionaryString is the same as ParseDictionary, but takes a string
+// as input without copying it to a []byte first.
+func ParseDictionaryString(s string, options ...ParseOption) (*Dictionary, error) {
+	return ParseDictionary(stringBytes(s), options...)
+}
+
+// ParseItemString is the same as ParseItem, but takes a string as input
+// without copying it to a []byte first.
+func ParseItemString(s string, options ...ParseOption) (Item, error) {
+	return ParseItem(stringBytes(s), options...)
+}
+
+// stringBytes returns the bytes of s without copying them. This is only
+// safe because the parser never writes to its input, and never keeps
+// references to it in the values it returns: everything that outlives
+// the parse is copied out of the input first.
+func stringBytes(s string) []byte {
+	return unsafe.Slice(unsafe.StringData(s), len(s))
+}
+
+func (pctx *parseContext) init(data []byte, mode int, options []ParseOption) {
 	pctx.data = data
 	pctx.size = len(data)
 	pctx.idx = 0
 	pctx.mode = mode
+
+	for _, option := range options {
+		switch option.Ident() {
+		case identStrictByteSequence{}:
+			pctx.strictByteSequence = option.Value().(bool) //nolint:forcetypeassert
+		case identEagerByteSequence{}:
+			pctx.eagerByteSequence = option.Value().(bool) //nolint:forcetypeassert
+		case identErrorAggregation{}:
+			pctx.aggregateErrors = option.Value().(bool) //nolint:forcetypeassert
+		case identInternTable{}:
+			pctx.internTable = option.Value().(*InternTable) //nolint:forcetypeassert
+		case identLenientDisplayString{}:
+			pctx.lenientDisplayString = option.Value().(bool) //nolint:forcetypeassert
+		case identRawText{}:
+			pctx.recordRaw = option.Value().(bool) //nolint:forcetypeassert
+		case identSpans{}:
+			pctx.recordSpans = option.Value().(bool) //nolint:forcetypeassert
+		case identObsFold{}:
+			pctx.obsFold = option.Value().(bool) //nolint:forcetypeassert
+		case identLogger{}:
+			pctx.logger = option.Value().(*slog.Logger) //nolint:forcetypeassert
+		}
+	}
+
+	switch {
+	case pctx.obsFold && bytes.IndexByte(data, tokens.LF) >= 0:
+		// unfolding creates a private copy of the input
+		pctx.data = unfold(data)
+		pctx.size = len(pctx.data)
+	case pctx.recordRaw:
+		// Raw text is handed out as slices of the input, so work on a
+		// private copy that the caller cannot modify afterwards
+		pctx.data = bytes.Clone(data)
+	}
+}
+
+// unfold replaces each obs-fold sequence (a line break followed by at
+// least one SP or HTAB, RFC 9112 Section 5.2) in data with a single SP.
+// Line breaks may be either CRLF or a bare LF. Line breaks that are not
+// followed by whitespace are left alone, and will fail parsing.
+func unfold(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		var n int // length of the line break starting at i, if any
+		switch {
+		case c == tokens.CR && i+1 < len(data) && data[i+1] == tokens.LF:
+			n = 2
+		case c == tokens.LF:
+			n = 1
+		}
+
+		if n > 0 && i+n < len(data) && (data[i+n] == tokens.Space || data[i+n] == tokens.Tab) {
+			i += n
+			for i+1 < len(data) && (data[i+1] == tokens.Space || data[i+1] == tokens.Tab) {
+				i++
+			}
+			out = append(out, tokens.Space)
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// makeString creates a string from b, going through the intern table
+// if one has been configured
+func (pctx *parseContext) makeString(b []byte) string {
+	if pctx.internTable != nil {
+		return pctx.internTable.Intern(b)
+	}
+	return string(b)
 }
 
 func (pctx *parseContext) eof() bool {
@@ -148,6 +409,7 @@ func (pctx *parseContext) do() error {
 	pctx.stripWhitespace()
 
 	// Check if this looks like a dictionary or a list
+	start := pctx.idx
 	var output any
 	var err error
 
@@ -167,6 +429,16 @@ func (pctx *parseContext) do() error {
 		if err != nil {
 			return fmt.Errorf("sfv: failed to parse item: %w", err)
 		}
+	case parseModeBareItem:
+		output, err = pctx.parseBareItem()
+		if err != nil {
+			return fmt.Errorf("sfv: failed to parse bare item: %w", err)
+		}
+	case parseModeInnerList:
+		output, err = pctx.parseInnerList()
+		if err != nil {
+			return fmt.Errorf("sfv: failed to parse inner list: %w", err)
+		}
 
 	default:
 		if pctx.isDictionary() {
@@ -184,6 +456,8 @@ func (pctx *parseContext) do() error {
 		}
 	}
 
+	pctx.recordFieldRaw(output, start)
+
 	// 6. Discard any leading SP characters from input_string.
 	pctx.stripWhitespace()
 
@@ -194,45 +468,147 @@ func (pctx *parseContext) do() error {
 
 	// 8. Otherwise, return output.
 	pctx.value = output
+	if len(pctx.errors) > 0 {
+		return errors.Join(pctx.errors...)
+	}
+	return nil
+}
+
+// positionalError wraps err in a ParseError pointing at the current
+// offset. Errors combined by WithErrorAggregation are returned as is, as
+// each of them is a ParseError already.
+func (pctx *parseContext) positionalError(err error) error {
+	if _, ok := err.(interface{ Unwrap() []error }); ok {
+		return err
+	}
+	return &ParseError{Offset: pctx.idx, Err: err}
+}
+
+// fail reports an error that makes it impossible to continue parsing
+// the current List or Dictionary. If error aggregation is enabled, the
+// error is recorded and nil is returned, so that the members parsed
+// so far are kept. Otherwise err is returned as is.
+func (pctx *parseContext) fail(err error) error {
+	if !pctx.aggregateErrors {
+		return err
+	}
+	pctx.errors = append(pctx.errors, &ParseError{Offset: pctx.idx, Err: err})
+	if pctx.logger != nil {
+		debug(pctx.logger, "sfv: giving up on the rest of the field", slog.Int("offset", pctx.idx), slog.Any("error", err))
+	}
 	return nil
 }
 
+// recoverMember is called when parsing the List or Dictionary member that
+// starts at offset start has failed. If error aggregation is enabled, the
+// error is recorded, the whole member is skipped, and true is returned.
+// Otherwise it returns false, and the caller should fail parsing.
+func (pctx *parseContext) recoverMember(start int, err error) bool {
+	if !pctx.aggregateErrors {
+		return false
+	}
+	pctx.errors = append(pctx.errors, &ParseError{Offset: pctx.idx, Err: err})
+	if pctx.logger != nil {
+		debug(pctx.logger, "sfv: skipping member", slog.Int("offset", start), slog.Any("error", err))
+	}
+	pctx.idx = start
+	pctx.skipMember()
+	return true
+}
+
+// skipMember advances up to the comma that ends the current List or
+// Dictionary member, or to the end of input. Commas inside strings and
+// inner lists are not considered to end the member.
+func (pctx *parseContext) skipMember() {
+	depth := 0
+	for !pctx.eof() {
+		switch pctx.current() {
+		case tokens.DoubleQuote:
+			pctx.advance()
+			for !pctx.eof() && pctx.current() != tokens.DoubleQuote {
+				if pctx.current() == tokens.Backslash {
+					pctx.advance()
+				}
+				pctx.advance()
+			}
+		case tokens.OpenParen:
+			depth++
+		case tokens.CloseParen:
+			if depth > 0 {
+				depth--
+			}
+		case tokens.Comma:
+			if depth == 0 {
+				return
+			}
+		}
+		pctx.advance()
+	}
+}
+
 // parseList implements the List parsing algorithm from RFC 9651 Section 4.2.1
 func (pctx *parseContext) parseList() (*List, error) {
 	var members []any
+	err := pctx.parseListMembers(func(member any) error {
+		members = append(members, member)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &List{values: members}, nil
+}
 
+// parseListMembers runs the List parsing algorithm, but instead of
+// collecting members into a List, it calls fn for each member as soon as
+// it has been parsed. If fn returns an error, parsing stops, and the error
+// is returned as is.
+func (pctx *parseContext) parseListMembers(fn func(member any) error) error {
 	for !pctx.eof() {
 		// Parse an Item or Inner List - check first character to determine which
 		var item any
 		var err error
 
+		start := pctx.idx
 		if pctx.current() == tokens.OpenParen {
 			// Parse Inner List
 			item, err = pctx.parseInnerList()
 			if err != nil {
-				return nil, fmt.Errorf("sfv: parse list: expected inner list: %w", err)
+				err = fmt.Errorf("sfv: parse list: expected inner list: %w", err)
 			}
 		} else {
 			// Parse Item
 			item, err = pctx.parseItem()
 			if err != nil {
-				return nil, fmt.Errorf("sfv: parse list: expected item: %w", err)
+				err = fmt.Errorf("sfv: parse list: expected item: %w", err)
 			}
 		}
 
-		members = append(members, item)
+		if err != nil {
+			if !pctx.recoverMember(start, err) {
+				return err
+			}
+		} else if err := fn(item); err != nil {
+			return err
+		}
 
 		// Discard any leading OWS characters (optional whitespace)
 		pctx.stripWhitespace()
 
 		// If input is empty, return the list
 		if pctx.eof() {
-			return &List{values: members}, nil
+			return nil
 		}
 
 		// Consume comma; if not comma, fail parsing
 		if pctx.current() != tokens.Comma {
-			return nil, fmt.Errorf("sfv: parse list: expected comma, got '%c'", pctx.current())
+			err := fmt.Errorf("sfv: parse list: expected comma, got '%c'", pctx.current())
+			if !pctx.recoverMember(pctx.idx, err) {
+				return err
+			}
+			if pctx.eof() {
+				return nil
+			}
 		}
 		pctx.advance() // consume comma
 
@@ -241,69 +617,37 @@ func (pctx *parseContext) parseList() (*List, error) {
 
 		// If input is empty after comma, there is a trailing comma; fail parsing
 		if pctx.eof() {
-			return nil, fmt.Errorf("sfv: parse list: trailing comma")
+			return pctx.fail(fmt.Errorf("sfv: parse list: trailing comma"))
 		}
 	}
 
 	// No structured data has been found; return empty list
-	return &List{values: members}, nil
+	return nil
 }
 
 // parseDictionary implements the Dictionary parsing algorithm from RFC 9651 Section 4.2.2
 func (pctx *parseContext) parseDictionary() (*Dictionary, error) {
 	dict := NewDictionary()
 	for !pctx.eof() {
-		// Parse the key (must be a token)
-		key, err := pctx.parseKey()
+		start := pctx.idx
+		key, value, err := pctx.parseDictionaryMember()
 		if err != nil {
-			return nil, fmt.Errorf("sfv: parse dictionary: %w", err)
-		}
-
-		var value any
-
-		// Check for '=' to see if there's a value
-		if !pctx.eof() && pctx.current() == '=' {
-			pctx.advance() // consume '='
-
-			// Parse the value (Item or Inner List)
-			if pctx.current() == tokens.OpenParen {
-				// Parse Inner List
-				value, err = pctx.parseInnerList()
-				if err != nil {
-					return nil, fmt.Errorf("sfv: parse dictionary value: %w", err)
-				}
-			} else {
-				// Parse Item
-				value, err = pctx.parseItem()
-				if err != nil {
-					return nil, fmt.Errorf("sfv: parse dictionary value: %w", err)
-				}
+			if !pctx.recoverMember(start, err) {
+				return nil, err
 			}
 		} else {
-			// No value specified, create a boolean Item with true value
-			value = True()
-		}
-
-		// Parse parameters for the dictionary member
-		params, err := pctx.parseParameters()
-		if err != nil {
-			return nil, fmt.Errorf("sfv: parse dictionary parameters: %w", err)
-		}
-
-		// If the value has parameters, ensure it's an Item
-		if params.Len() > 0 {
-			switch v := value.(type) {
-			case Item:
-				v.With(params)
-			case BareItem:
-				// Convert BareItem to Item when parameters are present
-				value = v.ToItem().With(params)
+			// If dictionary already contains a key this_key (comparing character
+			// for character), overwrite its value with member. Otherwise, append
+			// key this_key with value member to dictionary.
+			dict.members.Set(key, value)
+			if pctx.recordRaw {
+				dict.setRawMember(key, pctx.data[start:pctx.idx:pctx.idx])
+			}
+			if pctx.recordSpans {
+				dict.setMemberSpan(key, Span{Start: start, End: pctx.idx})
 			}
 		}
 
-		dict.keys = append(dict.keys, key)
-		dict.values[key] = value
-
 		// Discard any leading OWS characters
 		pctx.stripWhitespace()
 
@@ -314,7 +658,13 @@ func (pctx *parseContext) parseDictionary() (*Dictionary, error) {
 
 		// Consume comma; if not comma, fail parsing
 		if pctx.current() != tokens.Comma {
-			return nil, fmt.Errorf("sfv: parse dictionary: expected comma, got '%c'", pctx.current())
+			err := fmt.Errorf("sfv: parse dictionary: expected comma, got '%c'", pctx.current())
+			if !pctx.recoverMember(pctx.idx, err) {
+				return nil, err
+			}
+			if pctx.eof() {
+				return dict, nil
+			}
 		}
 		pctx.advance() // consume comma
 
@@ -323,18 +673,61 @@ func (pctx *parseContext) parseDictionary() (*Dictionary, error) {
 
 		// If input is empty after comma, there is a trailing comma; fail parsing
 		if pctx.eof() {
-			return nil, fmt.Errorf("sfv: parse dictionary: trailing comma")
+			if err := pctx.fail(fmt.Errorf("sfv: parse dictionary: trailing comma")); err != nil {
+				return nil, err
+			}
+			return dict, nil
 		}
 	}
 
 	return dict, nil
 }
 
+// parseDictionaryMember parses a single key and its value from a Dictionary.
+// Parameters are parsed exactly once, by whichever step parses the value
+// they belong to: parseItem and parseInnerList consume the parameters that
+// follow an Item or an Inner List, and a member without a value gets the
+// parameters that follow its key.
+func (pctx *parseContext) parseDictionaryMember() (string, any, error) {
+	// Parse the key (must be a token)
+	key, err := pctx.parseKey()
+	if err != nil {
+		return "", nil, fmt.Errorf("sfv: parse dictionary: %w", err)
+	}
+
+	// Without '=', the member value is Boolean true, and anything that
+	// follows the key are its parameters
+	if pctx.eof() || pctx.current() != tokens.Equals {
+		params, err := pctx.parseParameters()
+		if err != nil {
+			return "", nil, fmt.Errorf("sfv: parse dictionary parameters: %w", err)
+		}
+		if params.Len() > 0 {
+			return key, TrueItem().With(params), nil
+		}
+		return key, True(), nil
+	}
+	pctx.advance() // consume '='
+
+	// Parse the value (Item or Inner List), along with its parameters
+	var value any
+	if pctx.current() == tokens.OpenParen {
+		value, err = pctx.parseInnerList()
+	} else {
+		value, err = pctx.parseItem()
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("sfv: parse dictionary value: %w", err)
+	}
+	return key, value, nil
+}
+
 func (pctx *parseContext) parseInnerList() (*InnerList, error) {
 	pctx.stripWhitespace()
 	if pctx.current() != tokens.OpenParen {
 		return nil, fmt.Errorf(`sfv: parse inner list: expected '%c', got '%c'`, tokens.OpenParen, pctx.current())
 	}
+	start := pctx.idx
 	pctx.advance() // consume opening parenthesis
 
 	var list InnerList
@@ -347,10 +740,8 @@ func (pctx *parseContext) parseInnerList() (*InnerList, error) {
 			if err != nil {
 				return nil, fmt.Errorf("sfv: parse inner list: %w", err)
 			}
-
-			if params.Len() > 0 {
-				list.params = params
-			}
+			list.params = params
+			pctx.recordSource(&list, start)
 			return &list, nil
 		}
 
@@ -385,7 +776,8 @@ func (pctx *parseContext) parseKey() (string, error) {
 	}
 
 	// 2. Let output_string be an empty string.
-	var sb strings.Builder
+	// (output_string is the slice of input consumed by the loop below)
+	start := pctx.idx
 
 	// 3. While input_string is not empty:
 	for !pctx.eof() {
@@ -397,18 +789,12 @@ func (pctx *parseContext) parseKey() (string, error) {
 		}
 
 		// 3.2. Let char be the result of consuming the first character of input_string.
-		pctx.advance()
-
 		// 3.3. Append char to output_string.
-		sb.WriteByte(c)
+		pctx.advance()
 	}
 
 	// 4. Return output_string.
-	result := sb.String()
-	if result == "" {
-		return "", fmt.Errorf("sfv: empty key")
-	}
-	return result, nil
+	return pctx.makeString(pctx.data[start:pctx.idx]), nil
 }
 
 func isLowerAlpha(c byte) bool {
@@ -419,6 +805,7 @@ func (pctx *parseContext) parseParameters() (*Parameters, error) {
 	// RFC 9651 Section 4.2.3.2: Parsing Parameters
 	var keys []string
 	var values map[string]BareItem
+	var spans map[string]Span
 
 	for !pctx.eof() {
 		// 1. If the first character of input_string is not ";", exit the loop.
@@ -433,6 +820,7 @@ func (pctx *parseContext) parseParameters() (*Parameters, error) {
 		pctx.stripWhitespace()
 
 		// 4. Let param_key be the result of running Parsing a Key with input_string.
+		paramStart := pctx.idx
 		paramKey, err := pctx.parseKey()
 		if err != nil {
 			return nil, fmt.Errorf("sfv: failed to parse parameter key: %w", err)
@@ -467,21 +855,32 @@ func (pctx *parseContext) parseParameters() (*Parameters, error) {
 			keys = append(keys, paramKey)
 		}
 		values[paramKey] = paramValue
+
+		if pctx.recordSpans {
+			if spans == nil {
+				spans = make(map[string]Span)
+			}
+			spans[paramKey] = Span{Start: paramStart, End: pctx.idx}
+		}
 	}
 
 	// Only create Parameters object if we actually have parameters
 	if len(keys) == 0 {
-		return &Parameters{Values: make(map[string]BareItem)}, nil
+		return NewParameters(), nil
 	}
 
 	return &Parameters{
-		keys:   keys,
-		Values: values,
+		entries: OrderedMap[string, BareItem]{keys: keys, values: values},
+		spans:   spans,
 	}, nil
 }
 
+// Type identifies the type of an Item, as returned by the Type method of
+// Items and BareItems.
+type Type int
+
 const (
-	InvalidType = iota
+	InvalidType Type = iota
 	IntegerType
 	DecimalType
 	StringType
@@ -492,7 +891,37 @@ const (
 	DisplayStringType
 )
 
+// String returns the name of the type as used by RFC 9651, in lowercase,
+// such as "integer" or "byte sequence".
+func (t Type) String() string {
+	switch t {
+	case InvalidType:
+		return "invalid"
+	case IntegerType:
+		return "integer"
+	case DecimalType:
+		return "decimal"
+	case StringType:
+		return "string"
+	case TokenType:
+		return "token"
+	case ByteSequenceType:
+		return "byte sequence"
+	case BooleanType:
+		return "boolean"
+	case DateType:
+		return "date"
+	case DisplayStringType:
+		return "display string"
+	default:
+		return "Type(" + strconv.Itoa(int(t)) + ")"
+	}
+}
+
 func (pctx *parseContext) parseItem() (Item, error) {
+	pctx.stripWhitespace()
+	start := pctx.idx
+
 	bareItem, err := pctx.parseBareItem()
 	if err != nil {
 		return nil, fmt.Errorf("sfv: failed to parse bare item: %w", err)
@@ -503,7 +932,9 @@ func (pctx *parseContext) parseItem() (Item, error) {
 		return nil, fmt.Errorf("sfv: failed to parse parameters: %w", err)
 	}
 
-	return bareItem.ToItem().With(params), nil
+	item := bareItem.ToItem().With(params)
+	pctx.recordSource(item, start)
+	return item, nil
 }
 
 func isDigit(c byte) bool {
@@ -514,6 +945,21 @@ func isAlpha(c byte) bool {
 	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
 }
 
+// hexValue returns the value of the hex digit c. Uppercase digits are
+// only accepted if allowUpper is true
+func hexValue(c byte, allowUpper bool) (byte, bool) {
+	switch {
+	case isDigit(c):
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case allowUpper && c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	default:
+		return 0, false
+	}
+}
+
 func (pctx *parseContext) parseBareItem() (BareItem, error) {
 	pctx.stripWhitespace()
 	switch c := pctx.current(); {
@@ -577,12 +1023,12 @@ func (pctx *parseContext) parseDecimal() (BareItem, error) {
 		return nil, fmt.Errorf(`sfv: failed to parse numeric value: expected digit`)
 	}
 
-	var sb strings.Builder
+	start := pctx.idx
 LOOP:
 	for !pctx.eof() {
 		c := pctx.current()
 
-		if sb.Len() == 0 && !isDigit(c) {
+		if pctx.idx == start && !isDigit(c) {
 			return nil, fmt.Errorf(`sfv: failed to parse numeric value: expected digit at the start`)
 		}
 
@@ -595,8 +1041,8 @@ LOOP:
 			}
 
 			// 12 digits of precision is all we can do
-			if sb.Len() > 12 {
-				return nil, fmt.Errorf(`sfv: failed to parse numeric value: too many (%d) digits for decimal number`, sb.Len())
+			if pctx.idx-start > 12 {
+				return nil, fmt.Errorf(`sfv: failed to parse numeric value: too many (%d) digits for decimal number`, pctx.idx-start)
 			}
 			decimal = true
 		case !isDigit(c):
@@ -606,35 +1052,31 @@ LOOP:
 		}
 
 		pctx.advance()
-		sb.WriteByte(c)
 	}
+	digits := pctx.data[start:pctx.idx]
 
 	if decimal {
-		if sb.Len() > 16 {
-			return nil, fmt.Errorf(`sfv: failed to parse numeric value: too many (%d) digits for decimal number`, sb.Len())
+		if len(digits) > 16 {
+			return nil, fmt.Errorf(`sfv: failed to parse numeric value: too many (%d) digits for decimal number`, len(digits))
 		}
 
-		s := sb.String()
-		if s[sb.Len()-1] == tokens.Period {
+		if digits[len(digits)-1] == tokens.Period {
 			return nil, fmt.Errorf(`sfv: failed to parse numeric value: expected digit after decimal point`)
 		}
-		i := strings.IndexByte(s, tokens.Period)
-		if sb.Len()-i > 4 { // decimal point + max 3 fractional digits
-			return nil, fmt.Errorf(`sfv: failed to parse numeric value: too many (%d) digits after decimal point`, sb.Len()-i-1)
+		i := bytes.IndexByte(digits, tokens.Period)
+		if len(digits)-i > 4 { // decimal point + max 3 fractional digits
+			return nil, fmt.Errorf(`sfv: failed to parse numeric value: too many (%d) digits after decimal point`, len(digits)-i-1)
 		}
 
-		v, err := strconv.ParseFloat(sb.String(), 64)
-		if err != nil {
-			return nil, fmt.Errorf(`sfv: failed to parse numeric value as float: %w`, err)
-		}
-		return BareDecimal(v * float64(sign)), nil
+		// the value is kept exactly, so that it is serialized back as is
+		return BareDecimalFromMilli(int64(sign) * milliFromDigits(digits[:i], digits[i+1:])), nil
 	}
 
-	if sb.Len() > maxIntegerDigits {
-		return nil, fmt.Errorf(`sfv: failed to parse numeric value: too many (%d) digits for integer number`, sb.Len())
+	if len(digits) > maxIntegerDigits {
+		return nil, fmt.Errorf(`sfv: failed to parse numeric value: too many (%d) digits for integer number`, len(digits))
 	}
 
-	v, err := strconv.Atoi(sb.String())
+	v, err := strconv.Atoi(string(digits))
 	if err != nil {
 		return nil, fmt.Errorf(`sfv: failed to parse numeric value as integer: %w`, err)
 	}
@@ -648,7 +1090,12 @@ func (pctx *parseContext) parseString() (BareItem, error) {
 	}
 	pctx.advance() // consume opening quote
 
-	var sb strings.Builder
+	// Most strings do not contain any escape sequences, in which case
+	// the value is simply the slice of input between the quotes. Only
+	// when an escape is found do we need to copy the unescaped bytes
+	start := pctx.idx
+	var buf []byte
+	escaped := false
 	for !pctx.eof() {
 		c := pctx.current()
 		pctx.advance()
@@ -661,14 +1108,22 @@ func (pctx *parseContext) parseString() (BareItem, error) {
 			if next != tokens.DoubleQuote && next != tokens.Backslash {
 				return nil, fmt.Errorf("sfv: invalid escape sequence \\%c", next)
 			}
+			if !escaped {
+				buf = append(pctx.scratch[:0], pctx.data[start:pctx.idx-1]...)
+				escaped = true
+			}
 			pctx.advance()
-			sb.WriteByte(next)
+			buf = append(buf, next)
 		} else if c == tokens.DoubleQuote {
-			return BareString(sb.String()), nil
+			if !escaped {
+				return BareString(string(pctx.data[start : pctx.idx-1])), nil
+			}
+			pctx.scratch = buf
+			return BareString(string(buf)), nil
 		} else if c <= 0x1f || c >= 0x7f {
 			return nil, fmt.Errorf("sfv: invalid character in string: %c", c)
-		} else {
-			sb.WriteByte(c)
+		} else if escaped {
+			buf = append(buf, c)
 		}
 	}
 	return nil, fmt.Errorf("sfv: unexpected end of input, expected closing quote")
@@ -683,40 +1138,12 @@ func (pctx *parseContext) parseToken() (*TokenBareItem, error) {
 		return nil, fmt.Errorf("sfv: token must start with alpha or asterisk")
 	}
 
-	var sb strings.Builder
-OUTER:
-	for !pctx.eof() {
-		c := pctx.current()
-
-		switch {
-		case isAlpha(c):
-		case isDigit(c):
-		default:
-			switch c {
-			case tokens.Ampersand, tokens.Asterisk,
-				tokens.Backtick, tokens.Caret,
-				tokens.Colon, tokens.Dash,
-				tokens.Dollar, tokens.Exclamation,
-				tokens.Hash, tokens.Percent,
-				tokens.Period, tokens.Pipe,
-				tokens.Plus, tokens.SingleQuote,
-				tokens.Slash, tokens.Tilde,
-				tokens.Underscore:
-			default:
-				break OUTER
-			}
-		}
-		sb.WriteByte(c)
+	start := pctx.idx
+	for !pctx.eof() && isTokenChar(pctx.current()) {
 		pctx.advance()
 	}
 
-	if sb.Len() == 0 {
-		return nil, fmt.Errorf("sfv: empty token")
-	}
-
-	stok := sb.String()
-
-	return BareToken(stok), nil
+	return BareToken(pctx.makeString(pctx.data[start:pctx.idx])), nil
 }
 
 // parseByteSequence parses a byte sequence according to RFC 9651 Section 4.2.7
@@ -726,34 +1153,111 @@ func (pctx *parseContext) parseByteSequence() (*ByteSequenceBareItem, error) {
 	}
 	pctx.advance() // consume opening colon
 
-	var sb strings.Builder
+	start := pctx.idx
 	foundClosingColon := false
 	for !pctx.eof() {
 		c := pctx.current()
 		if c == tokens.Colon {
-			pctx.advance() // consume closing colon
 			foundClosingColon = true
 			break
 		}
 		// Valid base64 characters
-		if isAlpha(c) || isDigit(c) || c == tokens.Plus || c == tokens.Slash || c == tokens.Equals {
-			sb.WriteByte(c)
-			pctx.advance()
-		} else {
+		if !isAlpha(c) && !isDigit(c) && c != tokens.Plus && c != tokens.Slash && c != tokens.Equals {
 			return nil, fmt.Errorf("sfv: invalid character in byte sequence: %c", c)
 		}
+		pctx.advance()
 	}
 
 	if !foundClosingColon {
 		return nil, fmt.Errorf("sfv: expected closing colon in byte sequence")
 	}
+	encoded := pctx.data[start:pctx.idx]
+	pctx.advance() // consume closing colon
 
-	// Decode base64
-	decoded, err := base64.StdEncoding.DecodeString(sb.String())
+	payload, err := validateBase64(encoded, pctx.strictByteSequence)
 	if err != nil {
 		return nil, fmt.Errorf("sfv: failed to decode base64: %w", err)
 	}
-	return BareByteSequence(decoded), nil
+
+	if pctx.eagerByteSequence {
+		return BareByteSequence(decodeBase64(payload)), nil
+	}
+
+	// Defer decoding until the value is actually requested. The payload
+	// must be copied, as the caller is free to reuse the input buffer
+	return &ByteSequenceBareItem{
+		lazy: &lazyByteSequence{encoded: bytes.Clone(payload)},
+	}, nil
+}
+
+// validateBase64 checks that src is a well-formed base64 payload of a byte
+// sequence, and returns the payload with any "=" padding removed. Once a
+// payload has passed validation, decoding it cannot fail.
+//
+// RFC 9651 Section 4.2.7 states that parsers SHOULD NOT fail when "="
+// padding is not present, nor when non-zero pad bits are present. This
+// is the default behavior. When strict is true, the payload must be
+// properly padded and must not contain non-zero pad bits.
+func validateBase64(src []byte, strict bool) ([]byte, error) {
+	payload := src
+	if i := bytes.IndexByte(src, tokens.Equals); i >= 0 {
+		payload = src[:i]
+		switch padding := src[i:]; {
+		case len(bytes.TrimLeft(padding, "=")) > 0:
+			return nil, fmt.Errorf("sfv: '=' may only appear as trailing padding in base64 data")
+		case len(padding) > 2:
+			return nil, fmt.Errorf("sfv: too many padding characters in base64 data")
+		case len(src)%4 != 0:
+			// With the checks above, this also guarantees that the
+			// amount of padding matches the length of the payload
+			return nil, fmt.Errorf("sfv: padded base64 data must be a multiple of 4 characters")
+		}
+	}
+
+	if len(payload)%4 == 1 {
+		return nil, fmt.Errorf("sfv: invalid base64 data length")
+	}
+
+	if strict && len(src)%4 != 0 {
+		return nil, fmt.Errorf("sfv: base64 data must be padded to a multiple of 4 characters in strict mode")
+	}
+
+	if strict && len(payload)%4 != 0 {
+		// The bits of the last character that do not contribute to
+		// the decoded data must be zero
+		mask := byte(0x0f)
+		if len(payload)%4 == 3 {
+			mask = 0x03
+		}
+		if base64Value(payload[len(payload)-1])&mask != 0 {
+			return nil, fmt.Errorf("sfv: non-zero pad bits in base64 data in strict mode")
+		}
+	}
+	return payload, nil
+}
+
+// base64Value returns the 6-bit value of a character in the standard
+// base64 alphabet. The character must already be known to be valid.
+func base64Value(c byte) byte {
+	switch {
+	case c >= 'A' && c <= 'Z':
+		return c - 'A'
+	case c >= 'a' && c <= 'z':
+		return c - 'a' + 26
+	case c >= '0' && c <= '9':
+		return c - '0' + 52
+	case c == tokens.Plus:
+		return 62
+	default:
+		return 63
+	}
+}
+
+// decodeBase64 decodes a payload that has already passed validateBase64
+func decodeBase64(payload []byte) []byte {
+	dst := make([]byte, base64.RawStdEncoding.DecodedLen(len(payload)))
+	n, _ := base64.RawStdEncoding.Decode(dst, payload)
+	return dst[:n]
 }
 
 // parseBoolean parses a boolean according to RFC 9651 Section 4.2.8
@@ -768,12 +1272,12 @@ func (pctx *parseContext) parseBoolean() (BooleanBareItem, error) {
 	}
 
 	c := pctx.current()
-	pctx.advance()
-
 	switch c {
 	case tokens.One:
+		pctx.advance()
 		return True(), nil
 	case tokens.Zero:
+		pctx.advance()
 		return False(), nil
 	default:
 		return False(), fmt.Errorf("sfv: invalid boolean value, expected '0' or '1', got %c", c)
@@ -819,7 +1323,8 @@ func (pctx *parseContext) parseDisplayString() (*DisplayStringBareItem, error) {
 	}
 	pctx.advance() // consume quote
 
-	var byteArray []byte
+	byteArray := pctx.scratch[:0]
+	defer func() { pctx.scratch = byteArray }()
 	for !pctx.eof() {
 		c := pctx.current()
 		pctx.advance()
@@ -841,16 +1346,19 @@ func (pctx *parseContext) parseDisplayString() (*DisplayStringBareItem, error) {
 			hex2 := pctx.current()
 			pctx.advance()
 
-			// Decode hex - ParseUint will validate the hex characters for us
-			hexStr := string([]byte{hex1, hex2})
-			val, err := strconv.ParseUint(hexStr, 16, 8)
-			if err != nil {
-				return nil, fmt.Errorf("sfv: invalid hex sequence %%%c%c in display string: %w", hex1, hex2, err)
+			// RFC 9651 Section 4.2.10 only allows lowercase hex digits
+			hi, ok1 := hexValue(hex1, pctx.lenientDisplayString)
+			lo, ok2 := hexValue(hex2, pctx.lenientDisplayString)
+			if !ok1 || !ok2 {
+				return nil, fmt.Errorf("sfv: invalid hex sequence %%%c%c in display string", hex1, hex2)
 			}
-			byteArray = append(byteArray, byte(val))
+			byteArray = append(byteArray, hi<<4|lo)
 		} else if c == tokens.DoubleQuote {
 			// End of display string
-			// Decode as UTF-8
+			// Decode as UTF-8; if decoding fails, fail parsing
+			if !pctx.lenientDisplayString && !utf8.Valid(byteArray) {
+				return nil, fmt.Errorf("sfv: invalid UTF-8 sequence in display string")
+			}
 			return BareDisplayString(string(byteArray)), nil
 		} else {
 			// Regular ASCII character
@@ -0,0 +1,431 @@
+// Package sfvjson converts between Structured Field Values and the JSON
+// representation used by the httpwg/structured-field-tests project, which
+// is the de facto interchange format for tools that work with Structured
+// Fields. This makes it possible to run the community test suite against
+// this module, and to inspect values with ordinary JSON tools.
+//
+// In this representation, an Item is an array holding its bare item and
+// its parameters, as in [1, [["a", true]]]. Parameters are an array of
+// [key, bare item] pairs. An Inner List is an array holding an array of
+// Items and its parameters. A List is an array of Items and Inner Lists,
+// and a Dictionary is an array of [key, member] pairs. Integers,
+// Decimals, Strings, and Booleans are represented by the corresponding
+// JSON values, while the other bare item types are represented by
+// objects such as {"__type": "token", "value": "foo"}. Byte Sequences
+// are encoded using base32.
+package sfvjson
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lestrrat-go/sfv"
+)
+
+// Marshal returns the JSON representation of v, which must be an Item, a
+// BareItem, an *InnerList, a *List, or a *Dictionary.
+func Marshal(v any) ([]byte, error) {
+	tree, err := toJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(tree)
+}
+
+func toJSON(v any) (any, error) {
+	switch v := v.(type) {
+	case sfv.Item:
+		return itemToJSON(v)
+	case sfv.BareItem:
+		return bareItemToJSON(v)
+	case *sfv.InnerList:
+		return innerListToJSON(v)
+	case *sfv.List:
+		return listToJSON(v)
+	case *sfv.Dictionary:
+		return dictionaryToJSON(v)
+	default:
+		return nil, fmt.Errorf("sfvjson: unsupported type %T", v)
+	}
+}
+
+func listToJSON(l *sfv.List) (any, error) {
+	members := make([]any, 0, l.Len())
+	for i := range l.Len() {
+		member, _ := l.Get(i)
+		jv, err := memberToJSON(member)
+		if err != nil {
+			return nil, fmt.Errorf("sfvjson: failed to convert list member %d: %w", i, err)
+		}
+		members = append(members, jv)
+	}
+	return members, nil
+}
+
+func dictionaryToJSON(d *sfv.Dictionary) (any, error) {
+	members := make([]any, 0, len(d.Keys()))
+	for _, key := range d.Keys() {
+		var member any
+		if err := d.GetValue(key, &member); err != nil {
+			return nil, fmt.Errorf("sfvjson: failed to get dictionary member %q: %w", key, err)
+		}
+		jv, err := memberToJSON(member)
+		if err != nil {
+			return nil, fmt.Errorf("sfvjson: failed to convert dictionary member %q: %w", key, err)
+		}
+		members = append(members, []any{key, jv})
+	}
+	return members, nil
+}
+
+func memberToJSON(member any) (any, error) {
+	switch member := member.(type) {
+	case sfv.Item:
+		return itemToJSON(member)
+	case sfv.BareItem:
+		return itemToJSON(member.ToItem())
+	case *sfv.InnerList:
+		return innerListToJSON(member)
+	default:
+		return nil, fmt.Errorf("unsupported member type %T", member)
+	}
+}
+
+func innerListToJSON(il *sfv.InnerList) (any, error) {
+	items := make([]any, 0, il.Len())
+	for i := range il.Len() {
+		item, _ := il.Get(i)
+		jv, err := itemToJSON(item)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert inner list item %d: %w", i, err)
+		}
+		items = append(items, jv)
+	}
+	params, err := parametersToJSON(il.Parameters())
+	if err != nil {
+		return nil, err
+	}
+	return []any{items, params}, nil
+}
+
+func itemToJSON(item sfv.Item) (any, error) {
+	bare, err := bareItemToJSON(item)
+	if err != nil {
+		return nil, err
+	}
+	params, err := parametersToJSON(item.Parameters())
+	if err != nil {
+		return nil, err
+	}
+	return []any{bare, params}, nil
+}
+
+func parametersToJSON(params *sfv.Parameters) (any, error) {
+	pairs := make([]any, 0, params.Len())
+	if params == nil {
+		return pairs, nil
+	}
+	for _, key := range params.Keys() {
+		var bi sfv.BareItem
+		if err := params.Get(key, &bi); err != nil {
+			return nil, fmt.Errorf("failed to get parameter %q: %w", key, err)
+		}
+		jv, err := bareItemToJSON(bi)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert parameter %q: %w", key, err)
+		}
+		pairs = append(pairs, []any{key, jv})
+	}
+	return pairs, nil
+}
+
+// typed is the representation of the bare item types that do not have
+// a JSON counterpart
+type typed struct {
+	Type  string `json:"__type"`
+	Value any    `json:"value"`
+}
+
+// bareItemToJSON converts the bare item held by v, which may be an Item
+// or a BareItem
+func bareItemToJSON(v sfv.CoreItem) (any, error) {
+	switch v.Type() {
+	case sfv.IntegerType:
+		var i int64
+		if err := v.GetValue(&i); err != nil {
+			return nil, err
+		}
+		return json.Number(strconv.FormatInt(i, 10)), nil
+	case sfv.DecimalType:
+		var f float64
+		if err := v.GetValue(&f); err != nil {
+			return nil, err
+		}
+		s := strconv.FormatFloat(f, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			// keep decimals distinguishable from integers
+			s += ".0"
+		}
+		return json.Number(s), nil
+	case sfv.StringType:
+		var s string
+		if err := v.GetValue(&s); err != nil {
+			return nil, err
+		}
+		return s, nil
+	case sfv.BooleanType:
+		var b bool
+		if err := v.GetValue(&b); err != nil {
+			return nil, err
+		}
+		return b, nil
+	case sfv.TokenType:
+		var s string
+		if err := v.GetValue(&s); err != nil {
+			return nil, err
+		}
+		return typed{Type: "token", Value: s}, nil
+	case sfv.DisplayStringType:
+		var s string
+		if err := v.GetValue(&s); err != nil {
+			return nil, err
+		}
+		return typed{Type: "displaystring", Value: s}, nil
+	case sfv.ByteSequenceType:
+		var b []byte
+		if err := v.GetValue(&b); err != nil {
+			return nil, err
+		}
+		return typed{Type: "binary", Value: base32.StdEncoding.EncodeToString(b)}, nil
+	case sfv.DateType:
+		var i int64
+		if err := v.GetValue(&i); err != nil {
+			return nil, err
+		}
+		return typed{Type: "date", Value: json.Number(strconv.FormatInt(i, 10))}, nil
+	default:
+		return nil, fmt.Errorf("unsupported bare item type %s", v.Type())
+	}
+}
+
+// Unmarshal converts the JSON representation in data to an SFV value of
+// the given field type. The field type must be specified, because Lists
+// and Dictionaries cannot be told apart in the JSON representation. The
+// result is an sfv.Item, a *sfv.List, or a *sfv.Dictionary, depending on
+// ft.
+func Unmarshal(data []byte, ft sfv.FieldType) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return nil, fmt.Errorf("sfvjson: failed to decode JSON: %w", err)
+	}
+
+	var v any
+	var err error
+	switch ft {
+	case sfv.ItemField:
+		v, err = itemFromJSON(tree)
+	case sfv.ListField:
+		v, err = listFromJSON(tree)
+	case sfv.DictionaryField:
+		v, err = dictionaryFromJSON(tree)
+	default:
+		return nil, fmt.Errorf("sfvjson: field type must be an Item, a List, or a Dictionary")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sfvjson: %w", err)
+	}
+	return v, nil
+}
+
+func listFromJSON(tree any) (*sfv.List, error) {
+	members, ok := tree.([]any)
+	if !ok {
+		return nil, fmt.Errorf("list must be an array, got %T", tree)
+	}
+
+	var l sfv.List
+	for i, jv := range members {
+		member, err := memberFromJSON(jv)
+		if err != nil {
+			return nil, fmt.Errorf("invalid list member %d: %w", i, err)
+		}
+		if err := l.Add(member); err != nil {
+			return nil, err
+		}
+	}
+	return &l, nil
+}
+
+func dictionaryFromJSON(tree any) (*sfv.Dictionary, error) {
+	members, ok := tree.([]any)
+	if !ok {
+		return nil, fmt.Errorf("dictionary must be an array, got %T", tree)
+	}
+
+	d := sfv.NewDictionary()
+	for i, jv := range members {
+		key, value, err := pairFromJSON(jv)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dictionary member %d: %w", i, err)
+		}
+		member, err := memberFromJSON(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dictionary member %q: %w", key, err)
+		}
+		if err := d.Set(key, member); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+func memberFromJSON(tree any) (any, error) {
+	arr, ok := tree.([]any)
+	if !ok || len(arr) != 2 {
+		return nil, fmt.Errorf("member must be an array of two elements")
+	}
+	if _, isList := arr[0].([]any); isList {
+		return innerListFromJSON(arr)
+	}
+	return itemFromJSON(tree)
+}
+
+func innerListFromJSON(arr []any) (*sfv.InnerList, error) {
+	items, _ := arr[0].([]any)
+	il := sfv.NewInnerList()
+	for i, jv := range items {
+		item, err := itemFromJSON(jv)
+		if err != nil {
+			return nil, fmt.Errorf("invalid inner list item %d: %w", i, err)
+		}
+		if err := il.Add(item); err != nil {
+			return nil, err
+		}
+	}
+	if err := parametersFromJSON(il.Parameters(), arr[1]); err != nil {
+		return nil, err
+	}
+	return il, nil
+}
+
+func itemFromJSON(tree any) (sfv.Item, error) {
+	arr, ok := tree.([]any)
+	if !ok || len(arr) != 2 {
+		return nil, fmt.Errorf("item must be an array of two elements")
+	}
+	bare, err := bareItemFromJSON(arr[0])
+	if err != nil {
+		return nil, err
+	}
+	params := sfv.NewParameters()
+	if err := parametersFromJSON(params, arr[1]); err != nil {
+		return nil, err
+	}
+	return bare.ToItem().With(params), nil
+}
+
+func parametersFromJSON(params *sfv.Parameters, tree any) error {
+	pairs, ok := tree.([]any)
+	if !ok {
+		return fmt.Errorf("parameters must be an array, got %T", tree)
+	}
+	for i, jv := range pairs {
+		key, value, err := pairFromJSON(jv)
+		if err != nil {
+			return fmt.Errorf("invalid parameter %d: %w", i, err)
+		}
+		bare, err := bareItemFromJSON(value)
+		if err != nil {
+			return fmt.Errorf("invalid parameter %q: %w", key, err)
+		}
+		if err := params.Set(key, bare); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func pairFromJSON(tree any) (string, any, error) {
+	pair, ok := tree.([]any)
+	if !ok || len(pair) != 2 {
+		return "", nil, fmt.Errorf("expected a [key, value] pair")
+	}
+	key, ok := pair[0].(string)
+	if !ok {
+		return "", nil, fmt.Errorf("key must be a string, got %T", pair[0])
+	}
+	return key, pair[1], nil
+}
+
+func bareItemFromJSON(tree any) (sfv.BareItem, error) {
+	switch v := tree.(type) {
+	case json.Number:
+		s := v.String()
+		if strings.ContainsAny(s, ".eE") {
+			f, err := v.Float64()
+			if err != nil {
+				return nil, fmt.Errorf("invalid decimal %s: %w", s, err)
+			}
+			return sfv.BareDecimal(f), nil
+		}
+		i, err := v.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %s: %w", s, err)
+		}
+		bi, err := sfv.IntegerStrict(i)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %s: %w", s, err)
+		}
+		return bi, nil
+	case string:
+		return sfv.BareString(v), nil
+	case bool:
+		return sfv.BareBoolean(v), nil
+	case map[string]any:
+		typ, _ := v["__type"].(string)
+		switch typ {
+		case "token":
+			s, ok := v["value"].(string)
+			if !ok {
+				return nil, fmt.Errorf("token value must be a string")
+			}
+			return sfv.BareToken(s), nil
+		case "displaystring":
+			s, ok := v["value"].(string)
+			if !ok {
+				return nil, fmt.Errorf("display string value must be a string")
+			}
+			return sfv.BareDisplayString(s), nil
+		case "binary":
+			s, ok := v["value"].(string)
+			if !ok {
+				return nil, fmt.Errorf("binary value must be a string")
+			}
+			b, err := base32.StdEncoding.DecodeString(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid base32 data: %w", err)
+			}
+			return sfv.BareByteSequence(b), nil
+		case "date":
+			n, ok := v["value"].(json.Number)
+			if !ok {
+				return nil, fmt.Errorf("date value must be a number")
+			}
+			i, err := n.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("invalid date %s: %w", n, err)
+			}
+			return sfv.BareDate(i), nil
+		default:
+			return nil, fmt.Errorf("unknown bare item type %q", typ)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported JSON value %T", tree)
+	}
+}
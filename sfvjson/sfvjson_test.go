@@ -0,0 +1,133 @@
+package sfvjson_test
+
+import (
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/lestrrat-go/sfv/sfvjson"
+	"github.com/stretchr/testify/require"
+)
+
+// The test cases use the format of the httpwg/structured-field-tests
+// project: raw is the input, expected is the JSON representation of the
+// parsed value, and canonical is the serialization, if it differs from raw
+func TestRoundTrip(t *testing.T) {
+	testcases := []struct {
+		name      string
+		raw       string
+		ft        sfv.FieldType
+		expected  string
+		canonical string
+	}{
+		{
+			name:     "integer with parameters",
+			raw:      `1;a;b=?0;c=2.5`,
+			ft:       sfv.ItemField,
+			expected: `[1, [["a", true], ["b", false], ["c", 2.5]]]`,
+		},
+		{
+			name:     "whole decimal",
+			raw:      `4.0`,
+			ft:       sfv.ItemField,
+			expected: `[4.0, []]`,
+		},
+		{
+			name:     "token",
+			raw:      `a_b-c.d3:f%00/*`,
+			ft:       sfv.ItemField,
+			expected: `[{"__type": "token", "value": "a_b-c.d3:f%00/*"}, []]`,
+		},
+		{
+			name:     "binary",
+			raw:      `:aGVsbG8=:`,
+			ft:       sfv.ItemField,
+			expected: `[{"__type": "binary", "value": "NBSWY3DP"}, []]`,
+		},
+		{
+			name:     "date",
+			raw:      `@1659578233`,
+			ft:       sfv.ItemField,
+			expected: `[{"__type": "date", "value": 1659578233}, []]`,
+		},
+		{
+			name:     "display string",
+			raw:      `%"f%c3%bc%c3%bc"`,
+			ft:       sfv.ItemField,
+			expected: `[{"__type": "displaystring", "value": "füü"}, []]`,
+		},
+		{
+			name:      "list with inner lists",
+			raw:       `("foo" "bar");lvl=5, ("baz"), (), "qux";x`,
+			ft:        sfv.ListField,
+			expected:  `[[[["foo", []], ["bar", []]], [["lvl", 5]]], [[["baz", []]], []], [[], []], ["qux", [["x", true]]]]`,
+			canonical: `("foo" "bar");lvl=5, ("baz"), (), "qux";x`,
+		},
+		{
+			name:     "dictionary",
+			raw:      `a=1, b, c=(1 2);p="x", d=?0`,
+			ft:       sfv.DictionaryField,
+			expected: `[["a", [1, []]], ["b", [true, []]], ["c", [[[1, []], [2, []]], [["p", "x"]]]], ["d", [false, []]]]`,
+		},
+		{
+			name:      "dictionary with parameters on a bare key",
+			raw:       `a;x=1,  b=?1;y`,
+			ft:        sfv.DictionaryField,
+			expected:  `[["a", [true, [["x", 1]]]], ["b", [true, [["y", true]]]]]`,
+			canonical: `a;x=1, b;y`,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			mode := map[sfv.FieldType]func() (any, error){
+				sfv.ItemField:       func() (any, error) { return sfv.ParseItemString(tc.raw) },
+				sfv.ListField:       func() (any, error) { return sfv.ParseString(tc.raw) },
+				sfv.DictionaryField: func() (any, error) { return sfv.ParseDictionaryString(tc.raw) },
+			}
+			parsed, err := mode[tc.ft]()
+			require.NoError(t, err)
+
+			got, err := sfvjson.Marshal(parsed)
+			require.NoError(t, err)
+			require.JSONEq(t, tc.expected, string(got))
+
+			v, err := sfvjson.Unmarshal([]byte(tc.expected), tc.ft)
+			require.NoError(t, err)
+
+			canonical := tc.canonical
+			if canonical == "" {
+				canonical = tc.raw
+			}
+			serialized, err := sfv.Marshal(v)
+			require.NoError(t, err)
+			require.Equal(t, canonical, string(serialized))
+		})
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	testcases := []struct {
+		name string
+		json string
+		ft   sfv.FieldType
+	}{
+		{"unknown field type", `[1, []]`, sfv.UnknownField},
+		{"invalid json", `[1, `, sfv.ItemField},
+		{"item is not a pair", `[1]`, sfv.ItemField},
+		{"unknown type", `[{"__type": "foo", "value": 1}, []]`, sfv.ItemField},
+		{"invalid base32", `[{"__type": "binary", "value": "!"}, []]`, sfv.ItemField},
+		{"integer out of range", `[1000000000000000, []]`, sfv.ItemField},
+		{"list is not an array", `{}`, sfv.ListField},
+		{"invalid dictionary key", `[[1, [1, []]]]`, sfv.DictionaryField},
+		{"invalid parameters", `[1, {}]`, sfv.ItemField},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := sfvjson.Unmarshal([]byte(tc.json), tc.ft)
+			require.Error(t, err)
+		})
+	}
+
+	_, err := sfvjson.Marshal(42)
+	require.Error(t, err, "only SFV values can be marshaled")
+}
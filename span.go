@@ -0,0 +1,59 @@
+package sfv
+
+import "unicode"
+
+// Span describes the location of a parsed value in the input it was
+// parsed from, as a half-open range of byte offsets [Start, End).
+type Span struct {
+	Start int
+	End   int
+}
+
+// Len returns the number of bytes covered by the span
+func (s Span) Len() int {
+	return s.End - s.Start
+}
+
+// sourceRecorder is implemented by values that can remember where in
+// the input they were parsed from
+type sourceRecorder interface {
+	setRaw([]byte)
+	setSpan(Span)
+}
+
+// recordFieldRaw records the input consumed since offset start on v, a
+// top-level List or Dictionary, without trailing whitespace
+func (pctx *parseContext) recordFieldRaw(v any, start int) {
+	if !pctx.recordRaw {
+		return
+	}
+	end := pctx.idx
+	for end > start && unicode.IsSpace(rune(pctx.data[end-1])) {
+		end--
+	}
+	raw := pctx.data[start:end:end]
+	switch v := v.(type) {
+	case *List:
+		v.raw = raw
+	case *Dictionary:
+		v.raw = raw
+	}
+}
+
+// recordSource records the input consumed since offset start on v,
+// according to the WithRawText and WithSpans options
+func (pctx *parseContext) recordSource(v any, start int) {
+	if !pctx.recordRaw && !pctx.recordSpans {
+		return
+	}
+	sr, ok := v.(sourceRecorder)
+	if !ok {
+		return
+	}
+	if pctx.recordRaw {
+		sr.setRaw(pctx.data[start:pctx.idx:pctx.idx])
+	}
+	if pctx.recordSpans {
+		sr.setSpan(Span{Start: start, End: pctx.idx})
+	}
+}
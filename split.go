@@ -0,0 +1,114 @@
+package sfv
+
+import "fmt"
+
+// MarshalSplit is the same as Marshal, but splits the serialization of a
+// List or a Dictionary into multiple values of at most limit bytes each,
+// for use as separate field lines. Splitting only happens between
+// members, and recipients combine the field lines back into the original
+// value, as described in RFC 9651 Section 4.2.
+//
+// It is an error if a single member, or a value that is neither a List
+// nor a Dictionary, does not fit in limit bytes. An empty List or
+// Dictionary results in no values at all, or in ErrEmptyField if
+// WithEmptyFieldError is used.
+func MarshalSplit(v any, limit int, options ...EncodeOption) ([][]byte, error) {
+	cfg := newEncodeConfig(options)
+	chunks, err := cfg.marshalSplit(v, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 && cfg.emptyFieldError {
+		return nil, ErrEmptyField
+	}
+	return chunks, nil
+}
+
+func (cfg *encodeConfig) marshalSplit(v any, limit int) ([][]byte, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid field length limit %d", limit)
+	}
+	if v == nil {
+		if !cfg.allowNil {
+			return nil, errNilValue
+		}
+		return nil, nil
+	}
+
+	members, err := cfg.marshalMembers(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks [][]byte
+	var chunk []byte
+	for i, member := range members {
+		if len(member) > limit {
+			return nil, fmt.Errorf("member %d is %d bytes long, which exceeds the limit of %d bytes", i, len(member), limit)
+		}
+		if len(chunk) > 0 && len(chunk)+2+len(member) > limit {
+			chunks = append(chunks, chunk)
+			chunk = nil
+		}
+		if len(chunk) > 0 {
+			chunk = append(chunk, ',', ' ')
+		}
+		chunk = append(chunk, member...)
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+// marshalMembers returns the serialization of each member of v if it is
+// a List or a Dictionary. Other values are returned as a single member.
+func (cfg *encodeConfig) marshalMembers(v any) ([][]byte, error) {
+	value, ok := v.(Value)
+	if !ok {
+		var err error
+		value, err = cfg.valueToSFV(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert value to SFV: %w", err)
+		}
+	}
+
+	var members [][]byte
+	switch value := value.(type) {
+	case List:
+		return cfg.marshalMembers(&value)
+	case *List:
+		for i := range value.Len() {
+			member, _ := value.Get(i)
+			mv, err := cfg.valueToSFV(member)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert list member %d to SFV: %w", i, err)
+			}
+			buf, err := appendValue(nil, mv, cfg)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal list member %d: %w", i, err)
+			}
+			members = append(members, buf)
+		}
+	case *Dictionary:
+		if value == nil {
+			return nil, nil
+		}
+		for _, key := range value.sortedKeys(cfg) {
+			buf, err := appendDictionaryMember(nil, key, value.members.values[key], cfg)
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, buf)
+		}
+	default:
+		buf, err := cfg.marshal(nil, value)
+		if err != nil {
+			return nil, err
+		}
+		if len(buf) > 0 {
+			members = append(members, buf)
+		}
+	}
+	return members, nil
+}
@@ -0,0 +1,79 @@
+package sfv_test
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalSplit(t *testing.T) {
+	list, err := sfv.ParseString(`aaaa, bbbb;p=1, (cc dd), eeee`)
+	require.NoError(t, err)
+
+	testcases := []struct {
+		name     string
+		value    any
+		limit    int
+		expected []string
+		error    bool
+	}{
+		{name: "fits", value: list, limit: 100, expected: []string{`aaaa, bbbb;p=1, (cc dd), eeee`}},
+		{name: "exact fit", value: list, limit: 29, expected: []string{`aaaa, bbbb;p=1, (cc dd), eeee`}},
+		{name: "split list", value: list, limit: 16, expected: []string{`aaaa, bbbb;p=1`, `(cc dd), eeee`}},
+		{name: "one per line", value: list, limit: 9, expected: []string{`aaaa`, `bbbb;p=1`, `(cc dd)`, `eeee`}},
+		{name: "member too long", value: list, limit: 5, error: true},
+		{name: "go values", value: []int{100, 200, 300}, limit: 8, expected: []string{`100, 200`, `300`}},
+		{name: "dictionary", value: map[string]any{"a": 1, "b": true, "c": "xyz"}, limit: 10, expected: []string{`a=1, b`, `c="xyz"`}},
+		{name: "item", value: sfv.Token("abc"), limit: 3, expected: []string{`abc`}},
+		{name: "item too long", value: sfv.Token("abcd"), limit: 3, error: true},
+		{name: "empty list", value: &sfv.List{}, limit: 3, expected: nil},
+		{name: "invalid limit", value: list, limit: 0, error: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			chunks, err := sfv.MarshalSplit(tc.value, tc.limit)
+			if tc.error {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var got []string
+			for _, chunk := range chunks {
+				got = append(got, string(chunk))
+			}
+			require.Equal(t, tc.expected, got)
+		})
+	}
+
+	dict, err := sfv.ParseDictionaryString(`z=1, y=2, x=3`)
+	require.NoError(t, err)
+	chunks, err := sfv.MarshalSplit(dict, 8, sfv.WithSortedDictionaryKeys(true))
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte(`x=3, y=2`), []byte(`z=1`)}, chunks)
+}
+
+func TestMaxFieldLength(t *testing.T) {
+	var buf bytes.Buffer
+	enc := sfv.NewEncoder(&buf, sfv.WithMaxFieldLength(10))
+	require.NoError(t, enc.EncodeFields(
+		sfv.Field{Name: "Example-List", Value: []string{"a", "b", "c", "d"}},
+		sfv.Field{Name: "Example-Item", Value: 1},
+	))
+	require.Equal(t, "Example-List: \"a\", \"b\"\r\nExample-List: \"c\", \"d\"\r\nExample-Item: 1\r\n", buf.String())
+
+	h := http.Header{}
+	h.Set("Example", "old")
+	require.NoError(t, sfv.SetHeader(h, "Example", []int{1, 2, 3}, sfv.WithMaxFieldLength(4)))
+	require.Equal(t, []string{"1, 2", "3"}, h.Values("Example"))
+
+	require.NoError(t, sfv.AddHeader(h, "Example", []int{4, 5}, sfv.WithMaxFieldLength(1)))
+	require.Equal(t, []string{"1, 2", "3", "4", "5"}, h.Values("Example"))
+
+	require.Error(t, sfv.SetHeader(h, "Example", []int{100}, sfv.WithMaxFieldLength(2)))
+	require.Equal(t, []string{"1, 2", "3", "4", "5"}, h.Values("Example"), "failures should not modify the header")
+}
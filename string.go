@@ -1,7 +1,12 @@
 package sfv
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
+	"unicode/utf8"
+
+	"github.com/lestrrat-go/sfv/internal/tokens"
 )
 
 // StringItem represents a quoted string value,
@@ -51,6 +56,16 @@ func BareString(s string) *StringBareItem {
 	return &v
 }
 
+// StringStrict creates a new StringBareItem with the given string, like
+// BareString, but returns an error right away if s contains characters
+// that cannot appear in a String, instead of when the item is marshaled.
+func StringStrict(s string) (*StringBareItem, error) {
+	if err := validateString(s); err != nil {
+		return nil, err
+	}
+	return BareString(s), nil
+}
+
 // ToItem converts the StringBareItem to a full Item.
 func (s *StringBareItem) ToItem() Item {
 	return s.toItem()
@@ -58,13 +73,107 @@ func (s *StringBareItem) ToItem() Item {
 
 // MarshalSFV implements the Marshaler interface for StringBareItem.
 func (s StringBareItem) MarshalSFV() ([]byte, error) {
-	quoted := strconv.Quote(s.value)
-	return []byte(quoted), nil
+	return s.AppendSFV(nil)
+}
+
+// AppendSFV appends the serialization of the StringBareItem to dst.
+// It returns an error if the value contains characters that cannot
+// appear in a String, that is, anything other than printable ASCII.
+// Use a Display String for Unicode text.
+func (s StringBareItem) AppendSFV(dst []byte) ([]byte, error) {
+	return appendString(dst, s.value)
+}
+
+// appendString implements the String serialization algorithm from
+// RFC 9651 Section 4.1.6. Only printable ASCII characters (%x20-7E) can
+// be represented, and the only escape sequences are \" and \\.
+func appendString(dst []byte, s string) ([]byte, error) {
+	dst = append(dst, tokens.DoubleQuote)
+	for i := range len(s) {
+		c := s[i]
+		if c < 0x20 || c > 0x7e {
+			return nil, invalidStringCharError(c, i)
+		}
+		if c == tokens.DoubleQuote || c == tokens.Backslash {
+			dst = append(dst, tokens.Backslash)
+		}
+		dst = append(dst, c)
+	}
+	return append(dst, tokens.DoubleQuote), nil
+}
+
+// validateString checks that s can be serialized as a String
+func validateString(s string) error {
+	for i := range len(s) {
+		if c := s[i]; c < 0x20 || c > 0x7e {
+			return invalidStringCharError(c, i)
+		}
+	}
+	return nil
+}
+
+// invalidStringCharError reports the byte c at offset i. Non-ASCII bytes
+// are usually part of a multi-byte character, so they are printed in
+// hexadecimal rather than as a character
+func invalidStringCharError(c byte, i int) error {
+	if c >= utf8.RuneSelf {
+		return fmt.Errorf("sfv: invalid byte %#02x at position %d in string (use a display string for non-ASCII text)", c, i)
+	}
+	return fmt.Errorf("sfv: invalid byte %#02x at position %d in string", c, i)
+}
+
+// isASCII reports whether s only contains ASCII characters
+func isASCII(s string) bool {
+	for i := range len(s) {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
 }
 
 // Type returns the type of the StringBareItem, useful when
 // you have a list of BareItems and need to know the type
 // of each item.
-func (s StringBareItem) Type() int {
+func (s StringBareItem) Type() Type {
 	return StringType
 }
+
+// AsString returns the string held by the StringBareItem. The second return
+// value is always true.
+func (s StringBareItem) AsString() (string, bool) {
+	return s.value, true
+}
+
+// GoString returns Go-like notation for the StringBareItem, which is
+// used by the %#v verb.
+func (s StringBareItem) GoString() string {
+	return "sfv.BareString(" + strconv.Quote(s.value) + ")"
+}
+
+// ParseInner parses the content of the string as another structured
+// field of type ft. Some protocols embed serialized structured fields
+// inside String values, and this saves the caller from extracting the
+// string and calling one of the Parse functions by hand.
+//
+// If parsing fails, the returned error is a *ParseError, whose Offset
+// is relative to the start of the string content.
+func (s *StringBareItem) ParseInner(ft FieldType, options ...ParseOption) (any, error) {
+	mode, err := ft.parseMode()
+	if err != nil {
+		return nil, err
+	}
+
+	v, offset, err := parseWithOffset(stringBytes(s.value), mode, options)
+	if err != nil {
+		var perr *ParseError
+		if errors.As(err, &perr) {
+			err = perr.Err
+		}
+		return nil, &ParseError{
+			Offset: offset,
+			Err:    fmt.Errorf("sfv: failed to parse string content: %w", err),
+		}
+	}
+	return v, nil
+}
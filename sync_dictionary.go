@@ -0,0 +1,162 @@
+package sfv
+
+import (
+	"iter"
+	"sync"
+)
+
+// SyncDictionary is a Dictionary that can be used by multiple goroutines
+// at once. It is meant for read-mostly values, such as a field parsed
+// from the configuration once and consulted on every request, that are
+// occasionally updated: reads share a lock, while writes are exclusive.
+//
+// The members themselves are not protected by the lock. Members passed
+// to or retrieved from a SyncDictionary must therefore not be modified
+// in place; replace them with Set instead, or Freeze them beforehand.
+//
+// The zero value is an empty SyncDictionary ready to use. A
+// SyncDictionary must not be copied after first use.
+type SyncDictionary struct {
+	mu   sync.RWMutex
+	dict *Dictionary
+}
+
+// NewSyncDictionary creates a SyncDictionary holding the members of d,
+// which is used as is and must not be accessed directly afterwards. If d
+// is nil, the SyncDictionary is empty.
+func NewSyncDictionary(d *Dictionary) *SyncDictionary {
+	return &SyncDictionary{dict: d}
+}
+
+// dictionary returns the underlying dictionary, creating it if needed.
+// The caller must hold the write lock.
+func (sd *SyncDictionary) dictionary() *Dictionary {
+	if sd.dict == nil {
+		sd.dict = NewDictionary()
+	}
+	return sd.dict
+}
+
+// Len returns the number of members.
+func (sd *SyncDictionary) Len() int {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	if sd.dict == nil {
+		return 0
+	}
+	return sd.dict.members.Len()
+}
+
+// Keys returns a copy of the keys, in order.
+func (sd *SyncDictionary) Keys() []string {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dict.Keys()
+}
+
+// Has reports whether there is a member called key.
+func (sd *SyncDictionary) Has(key string) bool {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dict.Has(key)
+}
+
+// Get returns the member called key. See Dictionary.Get.
+func (sd *SyncDictionary) Get(key string) (any, bool) {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dict.Get(key)
+}
+
+// GetItem returns the member called key, which must be an Item. See
+// Dictionary.GetItem.
+func (sd *SyncDictionary) GetItem(key string) (Item, error) {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dict.GetItem(key)
+}
+
+// GetValue assigns the member called key to dst. See Dictionary.GetValue.
+func (sd *SyncDictionary) GetValue(key string, dst any) error {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dict.GetValue(key, dst)
+}
+
+// All returns an iterator over the keys and members, in order. The
+// iterator works on a snapshot taken when iteration starts, so the lock
+// is not held while the loop body runs, and the loop body may modify the
+// SyncDictionary.
+func (sd *SyncDictionary) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		sd.mu.RLock()
+		keys := sd.dict.Keys()
+		values := make([]any, len(keys))
+		for i, key := range keys {
+			values[i], _ = sd.dict.Get(key)
+		}
+		sd.mu.RUnlock()
+
+		for i, key := range keys {
+			if !yield(key, values[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Set adds or replaces the member called key. See Dictionary.Set.
+func (sd *SyncDictionary) Set(key string, value any) error {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	return sd.dictionary().Set(key, value)
+}
+
+// SetWithParams adds or replaces the member called key, with the given
+// parameters. See Dictionary.SetWithParams.
+func (sd *SyncDictionary) SetWithParams(key string, value any, params map[string]any) error {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	return sd.dictionary().SetWithParams(key, value, params)
+}
+
+// Update calls fn with the underlying Dictionary while holding the write
+// lock, so that several changes can be made atomically. fn must not keep
+// a reference to the Dictionary, nor call the methods of sd.
+func (sd *SyncDictionary) Update(fn func(*Dictionary) error) error {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	return fn(sd.dictionary())
+}
+
+// Snapshot returns a deep copy of the current contents, which can be
+// used without locking.
+func (sd *SyncDictionary) Snapshot() *Dictionary {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	if sd.dict == nil {
+		return NewDictionary()
+	}
+	return sd.dict.Clone()
+}
+
+// MarshalSFV implements the Marshaler interface for SyncDictionary.
+func (sd *SyncDictionary) MarshalSFV() ([]byte, error) {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	return sd.dict.MarshalSFV()
+}
+
+// AppendSFV appends the serialization of the dictionary to dst.
+func (sd *SyncDictionary) AppendSFV(dst []byte) ([]byte, error) {
+	return sd.appendSFV(dst, &defaultEncodeConfig)
+}
+
+func (sd *SyncDictionary) appendSFV(dst []byte, cfg *encodeConfig) ([]byte, error) {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+	if sd.dict == nil {
+		return dst, nil
+	}
+	return sd.dict.appendSFV(dst, cfg)
+}
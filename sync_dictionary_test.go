@@ -0,0 +1,85 @@
+package sfv_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncDictionary(t *testing.T) {
+	dict, err := sfv.ParseDictionaryString(`a=1, b=2`)
+	require.NoError(t, err)
+	sd := sfv.NewSyncDictionary(dict)
+
+	require.Equal(t, 2, sd.Len())
+	require.Equal(t, []string{"a", "b"}, sd.Keys())
+	require.True(t, sd.Has("a"))
+
+	item, err := sd.GetItem("a")
+	require.NoError(t, err)
+	n, ok := item.AsInt64()
+	require.True(t, ok)
+	require.Equal(t, int64(1), n)
+
+	require.NoError(t, sd.Set("c", sfv.TrueItem()))
+	require.NoError(t, sd.SetWithParams("d", "x", map[string]any{"p": 1}))
+	require.Equal(t, `a=1, b=2, c, d="x";p=1`, marshalString(t, sd))
+
+	b, err := sfv.Marshal(sd, sfv.WithSortedDictionaryKeys(true))
+	require.NoError(t, err)
+	require.Equal(t, `a=1, b=2, c, d="x";p=1`, string(b))
+
+	require.NoError(t, sd.Update(func(d *sfv.Dictionary) error {
+		if err := d.Set("a", sfv.Integer(10)); err != nil {
+			return err
+		}
+		return d.Set("e", sfv.Integer(5))
+	}))
+
+	var keys []string
+	for key := range sd.All() {
+		keys = append(keys, key)
+		// the loop body may modify the dictionary
+		require.NoError(t, sd.Set("z", sfv.Integer(0)))
+	}
+	require.Equal(t, []string{"a", "b", "c", "d", "e"}, keys)
+
+	snapshot := sd.Snapshot()
+	require.NoError(t, snapshot.Set("y", sfv.Integer(0)))
+	require.False(t, sd.Has("y"))
+
+	var zero sfv.SyncDictionary
+	require.Equal(t, 0, zero.Len())
+	require.Error(t, zero.GetValue("a", new(any)))
+	require.Equal(t, "", marshalString(t, &zero))
+	require.NoError(t, zero.Set("a", sfv.Integer(1)))
+	require.Equal(t, `a=1`, marshalString(t, &zero))
+}
+
+func TestSyncDictionaryConcurrency(t *testing.T) {
+	sd := sfv.NewSyncDictionary(nil)
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := range 50 {
+				_ = sd.Set(fmt.Sprintf("k%d-%d", i, j), sfv.Integer(int64(j)))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				_, _ = sd.Get("k0-0")
+				_, _ = sd.MarshalSFV()
+				for range sd.All() {
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 200, sd.Len())
+}
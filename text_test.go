@@ -0,0 +1,84 @@
+package sfv_test
+
+import (
+	"encoding"
+	"encoding/json"
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ encoding.TextMarshaler   = (*sfv.IntegerItem)(nil)
+	_ encoding.TextUnmarshaler = (*sfv.IntegerItem)(nil)
+	_ encoding.TextMarshaler   = (*sfv.List)(nil)
+	_ encoding.TextUnmarshaler = (*sfv.List)(nil)
+	_ encoding.TextMarshaler   = (*sfv.Dictionary)(nil)
+	_ encoding.TextUnmarshaler = (*sfv.Dictionary)(nil)
+	_ encoding.TextMarshaler   = (*sfv.InnerList)(nil)
+	_ encoding.TextUnmarshaler = (*sfv.InnerList)(nil)
+)
+
+func TestTextMarshaling(t *testing.T) {
+	t.Run("item", func(t *testing.T) {
+		var item sfv.IntegerItem
+		require.NoError(t, item.UnmarshalText([]byte(`5;a`)))
+		require.Equal(t, int64(5), item.Value())
+		text, err := item.MarshalText()
+		require.NoError(t, err)
+		require.Equal(t, `5;a`, string(text))
+
+		require.Error(t, item.UnmarshalText([]byte(`foo`)), "the item type must match")
+		require.Error(t, item.UnmarshalText([]byte(`5;`)))
+		require.Equal(t, int64(5), item.Value(), "failures should not modify the item")
+	})
+
+	t.Run("list", func(t *testing.T) {
+		var l sfv.List
+		require.NoError(t, l.UnmarshalText([]byte(`a, (b c);d`)))
+		require.Equal(t, 2, l.Len())
+		text, err := l.MarshalText()
+		require.NoError(t, err)
+		require.Equal(t, `a, (b c);d`, string(text))
+
+		require.NoError(t, l.UnmarshalText([]byte(``)))
+		require.Equal(t, 0, l.Len())
+		require.Error(t, l.UnmarshalText([]byte(`a,`)))
+	})
+
+	t.Run("dictionary", func(t *testing.T) {
+		var d sfv.Dictionary
+		require.NoError(t, d.UnmarshalText([]byte(`a=1, b`)))
+		require.Equal(t, []string{"a", "b"}, d.Keys())
+		text, err := d.MarshalText()
+		require.NoError(t, err)
+		require.Equal(t, `a=1, b`, string(text))
+		require.Error(t, d.UnmarshalText([]byte(`a=`)))
+	})
+
+	t.Run("inner list", func(t *testing.T) {
+		var il sfv.InnerList
+		require.NoError(t, il.UnmarshalText([]byte(`(1 2);p`)))
+		require.Equal(t, 2, il.Len())
+		text, err := il.MarshalText()
+		require.NoError(t, err)
+		require.Equal(t, `(1 2);p`, string(text))
+		require.Error(t, il.UnmarshalText([]byte(`1 2`)))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		type config struct {
+			Priority *sfv.Dictionary `json:"priority"`
+			Size     *sfv.IntegerItem
+		}
+		var cfg config
+		require.NoError(t, json.Unmarshal([]byte(`{"priority":"u=1, i","Size":"100;unit=kb"}`), &cfg))
+		require.Equal(t, []string{"u", "i"}, cfg.Priority.Keys())
+		require.Equal(t, int64(100), cfg.Size.Value())
+
+		out, err := json.Marshal(cfg)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"priority":"u=1, i","Size":"100;unit=kb"}`, string(out))
+	})
+}
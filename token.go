@@ -1,7 +1,10 @@
 package sfv
 
 import (
-	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/lestrrat-go/sfv/internal/tokens"
 )
 
 // TokenItem represents a token, an unquoted string value,
@@ -51,6 +54,16 @@ func BareToken(s string) *TokenBareItem {
 	return &v
 }
 
+// TokenStrict creates a new TokenBareItem with the given string, like
+// BareToken, but returns an error right away if s is not a valid token,
+// instead of when the item is marshaled.
+func TokenStrict(s string) (*TokenBareItem, error) {
+	if err := validateToken(s); err != nil {
+		return nil, err
+	}
+	return BareToken(s), nil
+}
+
 // ToItem converts the TokenBareItem to a full Item.
 func (t *TokenBareItem) ToItem() Item {
 	return t.toItem()
@@ -58,14 +71,51 @@ func (t *TokenBareItem) ToItem() Item {
 
 // MarshalSFV implements the Marshaler interface for TokenBareItem.
 func (t TokenBareItem) MarshalSFV() ([]byte, error) {
-	var buf bytes.Buffer
-	buf.WriteString(t.value)
-	return buf.Bytes(), nil
+	return t.AppendSFV(nil)
+}
+
+// AppendSFV appends the serialization of the TokenBareItem to dst.
+// It returns an error if the value is not a valid token.
+func (t TokenBareItem) AppendSFV(dst []byte) ([]byte, error) {
+	if err := validateToken(t.value); err != nil {
+		return nil, err
+	}
+	return append(dst, t.value...), nil
+}
+
+// validateToken checks that s can be serialized as a token, as specified
+// in RFC 9651 Section 4.1.7: it must start with an ALPHA or "*", followed
+// by any number of tchar, ":", or "/" characters
+func validateToken(s string) error {
+	if len(s) == 0 {
+		return fmt.Errorf("sfv: token must not be empty")
+	}
+	if c := s[0]; !isAlpha(c) && c != tokens.Asterisk {
+		return fmt.Errorf("sfv: token %q must start with a letter or '*'", s)
+	}
+	for i := 1; i < len(s); i++ {
+		if !isTokenChar(s[i]) {
+			return fmt.Errorf("sfv: invalid character %q at position %d in token %q", s[i], i, s)
+		}
+	}
+	return nil
 }
 
 // Type returns the type of the TokenBareItem, useful when
 // you have a list of BareItems and need to know the type
 // of each item.
-func (t TokenBareItem) Type() int {
+func (t TokenBareItem) Type() Type {
 	return TokenType
 }
+
+// AsString returns the token held by the TokenBareItem. The second return
+// value is always true.
+func (t TokenBareItem) AsString() (string, bool) {
+	return t.value, true
+}
+
+// GoString returns Go-like notation for the TokenBareItem, which is
+// used by the %#v verb.
+func (t TokenBareItem) GoString() string {
+	return "sfv.BareToken(" + strconv.Quote(t.value) + ")"
+}
@@ -0,0 +1,143 @@
+package sfv
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// SkipChildren can be returned by the function passed to Walk to skip the
+// members, items, and parameters of the current node. It is not returned
+// by Walk itself.
+var SkipChildren = errors.New("sfv: skip children") //nolint:errname,revive
+
+// PathElementKind describes what a PathElement refers to.
+type PathElementKind int
+
+const (
+	// MemberKeyElement refers to the member of a Dictionary with the key
+	// of the element
+	MemberKeyElement PathElementKind = iota + 1
+	// MemberIndexElement refers to the member of a List, or the item of
+	// an Inner List, at the index of the element
+	MemberIndexElement
+	// ParameterElement refers to the parameter with the key of the
+	// element
+	ParameterElement
+)
+
+// PathElement is a single step of a Path. Key is set for Dictionary
+// members and parameters, and Index for List and Inner List members.
+type PathElement struct {
+	Kind  PathElementKind
+	Key   string
+	Index int
+}
+
+// Path locates a value inside a List or a Dictionary, as a sequence of
+// steps starting from the top-level value. The empty Path refers to the
+// top-level value itself.
+type Path []PathElement
+
+// String returns the path in the same notation as Difference.Path:
+// Dictionary keys are written as is, List and Inner List members as an
+// index in brackets, and parameters as their key preceded by a
+// semicolon, as in "sig1[0];x".
+func (p Path) String() string {
+	var sb strings.Builder
+	for _, elem := range p {
+		switch elem.Kind {
+		case MemberKeyElement:
+			sb.WriteString(elem.Key)
+		case MemberIndexElement:
+			sb.WriteByte('[')
+			sb.WriteString(strconv.Itoa(elem.Index))
+			sb.WriteByte(']')
+		case ParameterElement:
+			sb.WriteByte(';')
+			sb.WriteString(elem.Key)
+		}
+	}
+	return sb.String()
+}
+
+// IsParameter reports whether the path refers to a parameter.
+func (p Path) IsParameter() bool {
+	return len(p) > 0 && p[len(p)-1].Kind == ParameterElement
+}
+
+// append returns a new path with elem added at the end, which never
+// shares its storage with p, so that callers may keep the paths they are
+// given
+func (p Path) append(elem PathElement) Path {
+	return append(p[:len(p):len(p)], elem)
+}
+
+// Walk calls fn for v and for every value it contains, in the order in
+// which they are serialized: Dictionary and List members, the items of
+// Inner Lists, and the parameters of Items and Inner Lists. v can be a
+// List, a Dictionary, an Inner List, an Item, a BareItem, or Parameters.
+//
+// fn is called with the path of each value, and the value itself, which
+// is one of the types listed above; parameters are passed as BareItems.
+// Values are visited before their contents, and if fn returns
+// SkipChildren, the contents of the current value are not visited. If fn
+// returns any other error, Walk stops and returns it.
+//
+// fn may keep the paths it is passed, but must not modify the structure
+// of the values being walked.
+func Walk(v any, fn func(path Path, node any) error) error {
+	return walk(nil, v, fn)
+}
+
+func walk(path Path, v any, fn func(Path, any) error) error {
+	if l, ok := v.(List); ok {
+		v = &l
+	}
+	if err := fn(path, v); err != nil {
+		if errors.Is(err, SkipChildren) {
+			return nil
+		}
+		return err
+	}
+
+	switch v := v.(type) {
+	case *Dictionary:
+		for key, member := range v.All() {
+			if err := walk(path.append(PathElement{Kind: MemberKeyElement, Key: key}), member, fn); err != nil {
+				return err
+			}
+		}
+	case *List:
+		for i, member := range v.All() {
+			if err := walk(path.append(PathElement{Kind: MemberIndexElement, Index: i}), member, fn); err != nil {
+				return err
+			}
+		}
+	case *InnerList:
+		if v == nil {
+			return nil
+		}
+		for i, item := range v.All() {
+			if err := walk(path.append(PathElement{Kind: MemberIndexElement, Index: i}), item, fn); err != nil {
+				return err
+			}
+		}
+		return walkParameters(path, v.params, fn)
+	case *Parameters:
+		return walkParameters(path, v, fn)
+	case CoreItem:
+		return walkParameters(path, itemParameters(v), fn)
+	}
+	return nil
+}
+
+func walkParameters(path Path, params *Parameters, fn func(Path, any) error) error {
+	for key, value := range params.All() {
+		err := fn(path.append(PathElement{Kind: ParameterElement, Key: key}), value)
+		if err != nil && !errors.Is(err, SkipChildren) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package sfv_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+// walkPaths returns the paths and the types of the nodes visited by Walk
+func walkPaths(t *testing.T, v any) []string {
+	t.Helper()
+	var visited []string
+	err := sfv.Walk(v, func(path sfv.Path, node any) error {
+		switch node := node.(type) {
+		case sfv.Item:
+			visited = append(visited, fmt.Sprintf("%s item %s", path, node.Type()))
+		case sfv.BareItem:
+			visited = append(visited, fmt.Sprintf("%s bare %s", path, node.Type()))
+		default:
+			visited = append(visited, fmt.Sprintf("%s %T", path, node))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	return visited
+}
+
+func TestWalk(t *testing.T) {
+	t.Run("dictionary", func(t *testing.T) {
+		dict, err := sfv.ParseDictionaryString(`sig1=("@method" "@path";req);created=1618884473, b=?0;x`)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			` *sfv.Dictionary`,
+			`sig1 *sfv.InnerList`,
+			`sig1[0] item string`,
+			`sig1[1] item string`,
+			`sig1[1];req bare boolean`,
+			`sig1;created bare integer`,
+			`b item boolean`,
+			`b;x bare boolean`,
+		}, walkPaths(t, dict))
+	})
+	t.Run("list", func(t *testing.T) {
+		v, err := sfv.ParseString(`a, (b);n=1`)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			` *sfv.List`,
+			`[0] item token`,
+			`[1] *sfv.InnerList`,
+			`[1][0] item token`,
+			`[1];n bare integer`,
+		}, walkPaths(t, v))
+	})
+	t.Run("item", func(t *testing.T) {
+		item, err := sfv.ParseItemString(`1;a;b=2`)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			` item integer`,
+			`;a bare boolean`,
+			`;b bare integer`,
+		}, walkPaths(t, item))
+	})
+	t.Run("structured path", func(t *testing.T) {
+		dict, err := sfv.ParseDictionaryString(`sig1=(a b;x=1)`)
+		require.NoError(t, err)
+		var paths []sfv.Path
+		err = sfv.Walk(dict, func(path sfv.Path, _ any) error {
+			paths = append(paths, path)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, sfv.Path{
+			{Kind: sfv.MemberKeyElement, Key: "sig1"},
+			{Kind: sfv.MemberIndexElement, Index: 1},
+			{Kind: sfv.ParameterElement, Key: "x"},
+		}, paths[len(paths)-1], "paths should not be overwritten by later calls")
+		require.Equal(t, sfv.Path{{Kind: sfv.MemberKeyElement, Key: "sig1"}}, paths[1])
+		require.True(t, paths[len(paths)-1].IsParameter())
+		require.False(t, paths[1].IsParameter())
+	})
+	t.Run("skip children", func(t *testing.T) {
+		v, err := sfv.ParseString(`(a b);n=1, c;d`)
+		require.NoError(t, err)
+		var visited []string
+		err = sfv.Walk(v, func(path sfv.Path, node any) error {
+			visited = append(visited, path.String())
+			if _, ok := node.(*sfv.InnerList); ok {
+				return sfv.SkipChildren
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{``, `[0]`, `[1]`, `[1];d`}, visited)
+	})
+	t.Run("error", func(t *testing.T) {
+		v, err := sfv.ParseString(`a, b, c`)
+		require.NoError(t, err)
+		myErr := errors.New("stop")
+		count := 0
+		err = sfv.Walk(v, func(path sfv.Path, _ any) error {
+			count++
+			if path.String() == `[1]` {
+				return myErr
+			}
+			return nil
+		})
+		require.ErrorIs(t, err, myErr)
+		require.Equal(t, 3, count)
+	})
+}
@@ -0,0 +1,81 @@
+package sfv_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/lestrrat-go/sfv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemWithParameter(t *testing.T) {
+	template, err := sfv.ParseItemString(`gzip;q=0.5`)
+	require.NoError(t, err)
+
+	derived, err := template.WithParameter("q", 1)
+	require.NoError(t, err)
+	require.Equal(t, `gzip;q=1`, marshalString(t, derived))
+
+	derived, err = derived.WithParameter("final", true)
+	require.NoError(t, err)
+	require.Equal(t, `gzip;q=1;final`, marshalString(t, derived))
+	require.Equal(t, `gzip;q=0.5`, marshalString(t, template), "the template should not be modified")
+
+	_, err = template.WithParameter("Bad", 1)
+	require.Error(t, err)
+	_, err = template.WithParameter("ok", []int{1})
+	require.Error(t, err)
+
+	// deriving from a shared item is safe from multiple goroutines
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			item, err := template.WithParameter("n", i)
+			if err == nil {
+				_, _ = sfv.Marshal(item)
+			}
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, `gzip;q=0.5`, marshalString(t, template))
+}
+
+func TestDictionaryWithMember(t *testing.T) {
+	dict, err := sfv.ParseDictionaryString(`a=1, b=2`)
+	require.NoError(t, err)
+
+	replaced, err := dict.WithMember("a", sfv.Integer(10))
+	require.NoError(t, err)
+	require.Equal(t, `a=10, b=2`, marshalString(t, replaced))
+
+	added, err := dict.WithMember("c", sfv.TrueItem())
+	require.NoError(t, err)
+	require.Equal(t, `a=1, b=2, c`, marshalString(t, added))
+	require.Equal(t, `a=1, b=2`, marshalString(t, dict), "the dictionary should not be modified")
+
+	_, err = dict.WithMember("d", 1)
+	require.Error(t, err, "values must be items or inner lists, as for Set")
+
+	var nilDict *sfv.Dictionary
+	d, err := nilDict.WithMember("a", sfv.Integer(1))
+	require.NoError(t, err)
+	require.Equal(t, `a=1`, marshalString(t, d))
+}
+
+func TestListWithMember(t *testing.T) {
+	list := parseList(t, `a, b, c`)
+
+	replaced, err := list.WithMember(1, sfv.Token("x"))
+	require.NoError(t, err)
+	require.Equal(t, `a, x, c`, marshalString(t, replaced))
+	require.Equal(t, `a, b, c`, marshalString(t, list), "the list should not be modified")
+
+	_, err = list.WithMember(3, sfv.Token("x"))
+	require.Error(t, err)
+
+	var nilList *sfv.List
+	_, err = nilList.WithMember(0, sfv.Token("x"))
+	require.Error(t, err)
+}